@@ -17,6 +17,10 @@ type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
 	precision time.Duration
+
+	// now returns the time of metrics added without timestamp, defaults to
+	// the current time
+	now func() time.Time
 }
 
 func NewAccumulator(
@@ -113,6 +117,8 @@ func (ac *accumulator) getTime(t []time.Time) time.Time {
 	var timestamp time.Time
 	if len(t) > 0 {
 		timestamp = t[0]
+	} else if ac.now != nil {
+		timestamp = ac.now()
 	} else {
 		timestamp = time.Now()
 	}
@@ -7,7 +7,9 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -17,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
@@ -24,6 +27,16 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Units of the running agent modified by incremental reloads
+	reloadLock sync.Mutex
+	inputs     *inputUnit
+	processors *processorChain
+	routes     map[string]*processorChain
+	outputs    *outputUnit
+
+	// Snapshot of the running plugins for status queries
+	plugins atomic.Pointer[pluginSnapshot]
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -35,6 +48,7 @@ func NewAgent(cfg *config.Config) *Agent {
 }
 
 // inputUnit is a group of input plugins and the shared channel they write to.
+// Inputs assigned to a route write to the channel of the route instead.
 //
 // ┌───────┐
 // │ Input │───┐
@@ -47,7 +61,43 @@ func NewAgent(cfg *config.Config) *Agent {
 // └───────┘
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
+	routes map[string]chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	sync.Mutex
+	ctx       context.Context
+	startTime time.Time
+	wg        sync.WaitGroup
+	running   map[*models.RunningInput]*pluginTask
+	stopped   bool
+}
+
+// destination returns the channel the given input writes to.
+func (unit *inputUnit) destination(input *models.RunningInput) chan<- telegraf.Metric {
+	if dst, found := unit.routes[input.Config.Route]; found {
+		return dst
+	}
+	return unit.dst
+}
+
+// closeDestinations closes the channels the inputs write to.
+func (unit *inputUnit) closeDestinations() {
+	close(unit.dst)
+	for _, dst := range unit.routes {
+		close(dst)
+	}
+}
+
+// pluginTask is the gather or flush loop of a single plugin.
+type pluginTask struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// stop terminates the loop and waits for it to return.
+func (t *pluginTask) stop() {
+	t.cancel()
+	<-t.done
 }
 
 //  ______     ┌───────────┐     ______
@@ -60,6 +110,26 @@ type processorUnit struct {
 	processor *models.RunningProcessor
 }
 
+// processorChain is a replaceable chain of processors between two fixed
+// channels. The metrics of the source channel are forwarded to the entry of
+// the current processors and the processed metrics are passed on to the
+// destination channel.
+//
+//  ______     ┌─────┐     ┌───────────┐     ┌───────────┐     ┌─────┐     ______
+// ()_____)──▶ │ Fwd │──▶  │ Processor │──▶  │ Processor │──▶  │ Fwd │──▶ ()_____)
+//             └─────┘     └───────────┘     └───────────┘     └─────┘
+
+type processorChain struct {
+	src <-chan telegraf.Metric
+	dst chan<- telegraf.Metric
+
+	sync.Mutex
+	entry      chan<- telegraf.Metric
+	processors models.RunningProcessors
+	done       chan struct{}
+	stopped    bool
+}
+
 // aggregatorUnit is a group of Aggregators and their source and sink channels.
 // Typically, the aggregators write to a processor channel and pass the original
 // metrics to the output channel.  The sink channels may be the same channel.
@@ -83,8 +153,10 @@ type aggregatorUnit struct {
 	aggregators []*models.RunningAggregator
 }
 
-// outputUnit is a group of Outputs and their source channel.  Metrics on the
-// channel are written to all outputs.
+// outputUnit is a group of Outputs and their source channels.  Metrics on the
+// source channel are written to all outputs not subscribed to any route, the
+// metrics on the channel of a route are written to the outputs subscribed to
+// the route.
 
 //                            ┌────────┐
 //                       ┌──▶ │ Output │
@@ -98,7 +170,14 @@ type aggregatorUnit struct {
 
 type outputUnit struct {
 	src     <-chan telegraf.Metric
+	routes  map[string]<-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	sync.RWMutex
+	ctx     context.Context
+	wg      sync.WaitGroup
+	running map[*models.RunningOutput]*pluginTask
+	stopped bool
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -135,6 +214,59 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	// Publish the configured plugins for the API and status queries until the
+	// running plugins replace them
+	a.reloadLock.Lock()
+	a.publishPlugins()
+	a.reloadLock.Unlock()
+
+	if a.Config.Agent.APIAddress != "" {
+		api, err := newAPIServer(a, a.Config.Agent.APIAddress)
+		if err != nil {
+			return fmt.Errorf("starting API server: %w", err)
+		}
+		api.start()
+		defer api.stop()
+	}
+
+	if a.Config.Agent.SelfstatAddress != "" {
+		server, err := newSelfstatServer(a.Config.Agent.SelfstatAddress)
+		if err != nil {
+			return fmt.Errorf("starting internal statistics server: %w", err)
+		}
+		server.start()
+		defer server.stop()
+	}
+
+	if a.Config.Agent.SelfstatOTLPEndpoint != "" {
+		interval := time.Duration(a.Config.Agent.SelfstatOTLPInterval)
+		if interval <= 0 {
+			interval = time.Duration(a.Config.Agent.Interval)
+		}
+		tlsCfg := &tls.ClientConfig{
+			TLSCA:              a.Config.Agent.SelfstatOTLPTLSCA,
+			TLSCert:            a.Config.Agent.SelfstatOTLPTLSCert,
+			TLSKey:             a.Config.Agent.SelfstatOTLPTLSKey,
+			ServerName:         a.Config.Agent.SelfstatOTLPTLSServerName,
+			InsecureSkipVerify: a.Config.Agent.SelfstatOTLPInsecureSkipVerify,
+			Enable:             a.Config.Agent.SelfstatOTLPTLSEnable,
+		}
+		pusher, err := newSelfstatPusher(a.Config.Agent.SelfstatOTLPEndpoint, interval, tlsCfg)
+		if err != nil {
+			return fmt.Errorf("starting internal statistics pusher: %w", err)
+		}
+		pushCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pusher.run(pushCtx)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -157,19 +289,31 @@ func (a *Agent) Run(ctx context.Context) error {
 		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
 	}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
-			return err
-		}
+	next, pc, err := a.startProcessorChain(next, routeProcessors(a.Config.Processors, ""))
+	if err != nil {
+		return err
 	}
 
-	iu, err := a.startInputs(next, a.Config.Inputs)
+	routes, rc, err := a.startRoutes(ou, a.Config.Routes())
 	if err != nil {
 		return err
 	}
 
+	iu, err := a.startInputs(next, routes, a.Config.Inputs)
+	if err != nil {
+		return err
+	}
+
+	a.reloadLock.Lock()
+	a.inputs, a.processors, a.routes, a.outputs = iu, pc, rc, ou
+	a.publishPlugins()
+	a.reloadLock.Unlock()
+	defer func() {
+		a.reloadLock.Lock()
+		a.inputs, a.processors, a.routes, a.outputs = nil, nil, nil, nil
+		a.reloadLock.Unlock()
+	}()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -191,11 +335,17 @@ func (a *Agent) Run(ctx context.Context) error {
 		}()
 	}
 
-	if pu != nil {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.runProcessorChain(pc)
+	}()
+
+	for _, chain := range rc {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.runProcessorChain(chain)
 		}()
 	}
 
@@ -205,6 +355,14 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.runInputs(ctx, startTime, iu)
 	}()
 
+	if a.Config.Persister != nil && a.Config.Agent.StatefileInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.checkpointStates(ctx, time.Duration(a.Config.Agent.StatefileInterval))
+		}()
+	}
+
 	wg.Wait()
 
 	if a.Config.Persister != nil {
@@ -225,6 +383,7 @@ func (a *Agent) InitPlugins() error {
 		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
 			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
 		}
+		a.linkStatusProvider(input.Input)
 		err := input.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
@@ -251,11 +410,53 @@ func (a *Agent) InitPlugins() error {
 		}
 	}
 	for _, output := range a.Config.Outputs {
+		a.linkStatusProvider(output.Output)
 		err := output.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
 		}
 	}
+	return linkDeadLetterOutputs(a.Config.Outputs)
+}
+
+// linkDeadLetterOutputs connects the outputs to the outputs receiving their
+// rejected metrics as configured by the 'dead_letter_output' setting. The
+// outputs are only linked if all references are valid.
+func linkDeadLetterOutputs(outputs []*models.RunningOutput) error {
+	byAlias := make(map[string]*models.RunningOutput, len(outputs))
+	for _, output := range outputs {
+		if output.Config.Alias != "" {
+			byAlias[output.Config.Alias] = output
+		}
+	}
+
+	targets := make(map[*models.RunningOutput]*models.RunningOutput, len(outputs))
+	for _, output := range outputs {
+		alias := output.Config.DeadLetterOutput
+		if alias == "" {
+			continue
+		}
+		target, found := byAlias[alias]
+		if !found {
+			return fmt.Errorf("dead-letter output %q of output %s not found", alias, output.LogName())
+		}
+		targets[output] = target
+	}
+
+	// Rejected metrics must not circulate between the outputs forever
+	for output := range targets {
+		seen := map[*models.RunningOutput]bool{output: true}
+		for current := targets[output]; current != nil; current = targets[current] {
+			if seen[current] {
+				return fmt.Errorf("dead-letter outputs of output %s form a cycle", output.LogName())
+			}
+			seen[current] = true
+		}
+	}
+
+	for _, output := range outputs {
+		output.SetDeadLetterOutput(targets[output])
+	}
 	return nil
 }
 
@@ -279,22 +480,14 @@ func (a *Agent) initPersister() error {
 	}
 
 	for _, processor := range a.Config.Processors {
-		var plugin telegraf.StatefulPlugin
-		if p, ok := processor.Processor.(processors.HasUnwrap); ok {
-			plugin, ok = p.Unwrap().(telegraf.StatefulPlugin)
-			if !ok {
-				continue
-			}
-		} else {
-			plugin, ok = processor.Processor.(telegraf.StatefulPlugin)
-			if !ok {
-				continue
-			}
+		plugin, ok := statefulProcessor(processor)
+		if !ok {
+			continue
 		}
 
 		name := processor.LogName()
 		id := processor.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register(id, &lockedState{processor, plugin}); err != nil {
 			return fmt.Errorf("could not register processor %s: %w", name, err)
 		}
 	}
@@ -307,7 +500,7 @@ func (a *Agent) initPersister() error {
 
 		name := aggregator.LogName()
 		id := aggregator.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register(id, &lockedState{aggregator, plugin}); err != nil {
 			return fmt.Errorf("could not register aggregator %s: %w", name, err)
 		}
 	}
@@ -320,7 +513,7 @@ func (a *Agent) initPersister() error {
 
 		name := processor.LogName()
 		id := processor.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register(id, &lockedState{processor, plugin}); err != nil {
 			return fmt.Errorf("could not register aggregating processor %s: %w", name, err)
 		}
 	}
@@ -341,53 +534,124 @@ func (a *Agent) initPersister() error {
 	return nil
 }
 
-func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) (*inputUnit, error) {
-	log.Printf("D! [agent] Starting service inputs")
+// checkpointStates periodically stores the plugin states until the context is
+// done. The final states are stored by the caller after all plugins stopped.
+func (a *Agent) checkpointStates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	unit := &inputUnit{
-		dst: dst,
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Printf("D! [agent] Checkpointing plugin states")
+			if err := a.Config.Persister.Store(); err != nil {
+				log.Printf("E! [agent] Checkpointing plugin states failed: %v", err)
+			}
+		}
 	}
+}
 
-	for _, input := range inputs {
-		// Service input plugins are not normally subject to timestamp
-		// rounding except for when precision is set on the input plugin.
-		//
-		// This only applies to the accumulator passed to Start(), the
-		// Gather() accumulator does apply rounding according to the
-		// precision and interval agent/plugin settings.
-		var interval time.Duration
-		var precision time.Duration
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
+// lockedState serializes accessing the state of a plugin with the calls of
+// the running plugin. This allows to checkpoint the state of processors and
+// aggregators while running without requiring the plugins to synchronize the
+// state access themselves.
+type lockedState struct {
+	sync.Locker
+	plugin telegraf.StatefulPlugin
+}
 
-		acc := NewAccumulator(input, dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+func (s *lockedState) GetState() interface{} {
+	s.Lock()
+	defer s.Unlock()
 
-		if err := input.Start(acc); err != nil {
-			// If the model tells us to remove the plugin we do so without error
-			var fatalErr *internal.FatalError
-			if errors.As(err, &fatalErr) {
-				log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
-				continue
-			}
+	return s.plugin.GetState()
+}
+
+func (s *lockedState) SetState(state interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.plugin.SetState(state)
+}
+
+// statefulProcessor returns the processor as stateful plugin if it supports
+// persisting its state.
+func statefulProcessor(processor *models.RunningProcessor) (telegraf.StatefulPlugin, bool) {
+	if p, ok := processor.Processor.(processors.HasUnwrap); ok {
+		plugin, ok := p.Unwrap().(telegraf.StatefulPlugin)
+		return plugin, ok
+	}
+	plugin, ok := processor.Processor.(telegraf.StatefulPlugin)
+	return plugin, ok
+}
+
+func (a *Agent) startInputs(
+	dst chan<- telegraf.Metric,
+	routes map[string]chan<- telegraf.Metric,
+	inputs []*models.RunningInput,
+) (*inputUnit, error) {
+	log.Printf("D! [agent] Starting service inputs")
 
+	unit := &inputUnit{
+		dst:    dst,
+		routes: routes,
+	}
+
+	for _, input := range inputs {
+		started, err := a.startInput(unit.destination(input), input)
+		if err != nil {
 			stopRunningInputs(unit.inputs)
 
-			return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+			return nil, err
 		}
-		if err := input.Probe(); err != nil {
-			// Probe failures are non-fatal to the agent but should only remove the plugin
-			log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
-			input.Stop()
-			continue
+		if started {
+			unit.inputs = append(unit.inputs, input)
 		}
-		unit.inputs = append(unit.inputs, input)
 	}
 
 	return unit, nil
 }
 
+// startInput starts a single input. Inputs failing with a fatal error or
+// failing the probe are not started but do not cause an error.
+func (*Agent) startInput(dst chan<- telegraf.Metric, input *models.RunningInput) (bool, error) {
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	if err := input.Start(acc); err != nil {
+		// If the model tells us to remove the plugin we do so without error
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
+			return false, nil
+		}
+
+		return false, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+	}
+	if err := input.Probe(); err != nil {
+		// Probe failures are non-fatal to the agent but should only remove the plugin
+		log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
+		input.Stop()
+		return false, nil
+	}
+
+	return true, nil
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -397,67 +661,90 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) {
-	var wg sync.WaitGroup
-	tickers := make([]Ticker, 0, len(unit.inputs))
+	unit.Lock()
+	unit.ctx = ctx
+	unit.startTime = startTime
+	unit.running = make(map[*models.RunningInput]*pluginTask, len(unit.inputs))
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := time.Duration(a.Config.Agent.Interval)
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
+		a.runInput(unit, input)
+	}
+	unit.Unlock()
 
-		// Overwrite agent precision if this plugin has its own.
-		precision := time.Duration(a.Config.Agent.Precision)
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
+	<-ctx.Done()
+	unit.Lock()
+	unit.stopped = true
+	unit.Unlock()
+	unit.wg.Wait()
 
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := time.Duration(a.Config.Agent.CollectionJitter)
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
+	log.Printf("D! [agent] Stopping service inputs")
+	stopRunningInputs(unit.inputs)
 
-		// Overwrite agent collection_offset if this plugin has its own.
-		offset := time.Duration(a.Config.Agent.CollectionOffset)
-		if input.Config.CollectionOffset != 0 {
-			offset = input.Config.CollectionOffset
-		}
+	unit.closeDestinations()
+	log.Printf("D! [agent] Input channel closed")
+}
 
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter, offset)
-		}
-		tickers = append(tickers, ticker)
+// runInput starts the gather loop of the given input. The unit must be locked
+// by the caller.
+func (a *Agent) runInput(unit *inputUnit, input *models.RunningInput) {
+	// Overwrite agent interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.Interval)
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+	// Overwrite agent precision if this plugin has its own.
+	precision := time.Duration(a.Config.Agent.Precision)
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.CollectionJitter)
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
 	}
-	defer stopTickers(tickers)
-	wg.Wait()
 
-	log.Printf("D! [agent] Stopping service inputs")
-	stopRunningInputs(unit.inputs)
+	// Overwrite agent collection_offset if this plugin has its own.
+	offset := time.Duration(a.Config.Agent.CollectionOffset)
+	if input.Config.CollectionOffset != 0 {
+		offset = input.Config.CollectionOffset
+	}
 
-	close(unit.dst)
-	log.Printf("D! [agent] Input channel closed")
+	var ticker Ticker
+	if a.Config.Agent.RoundInterval {
+		ticker = NewAlignedTicker(unit.startTime, interval, jitter, offset)
+	} else {
+		ticker = NewUnalignedTicker(interval, jitter, offset)
+	}
+
+	acc := NewAccumulator(input, unit.destination(input))
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	task := &pluginTask{cancel: cancel, done: make(chan struct{})}
+	unit.running[input] = task
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(task.done)
+		defer ticker.Stop()
+		a.gatherLoop(ctx, acc, input, ticker, interval)
+	}()
 }
 
 // testStartInputs is a variation of startInputs for use in --test and --once mode.
 // It differs by logging Start errors and returning only plugins successfully started.
-func (*Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) *inputUnit {
+func (*Agent) testStartInputs(
+	dst chan<- telegraf.Metric,
+	routes map[string]chan<- telegraf.Metric,
+	inputs []*models.RunningInput,
+) *inputUnit {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:    dst,
+		routes: routes,
 	}
 
 	for _, input := range inputs {
@@ -465,7 +752,7 @@ func (*Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.Runni
 		// This only applies to the accumulator passed to Start(), the
 		// Gather() accumulator does apply rounding according to the
 		// precision agent setting.
-		acc := NewAccumulator(input, dst)
+		acc := NewAccumulator(input, unit.destination(input))
 		acc.SetPrecision(time.Nanosecond)
 
 		if err := input.Start(acc); err != nil {
@@ -525,7 +812,7 @@ func (a *Agent) testRunInputs(
 				time.Sleep(500 * time.Millisecond)
 			}
 
-			acc := NewAccumulator(input, unit.dst)
+			acc := NewAccumulator(input, unit.destination(input))
 			acc.SetPrecision(getPrecision(precision, interval))
 
 			if err := input.Input.Gather(acc); err != nil {
@@ -542,7 +829,7 @@ func (a *Agent) testRunInputs(
 	log.Printf("D! [agent] Stopping service inputs")
 	stopRunningInputs(unit.inputs)
 
-	close(unit.dst)
+	unit.closeDestinations()
 	log.Printf("D! [agent] Input channel closed")
 }
 
@@ -572,6 +859,14 @@ func (a *Agent) gatherLoop(
 	for {
 		select {
 		case <-ticker.Elapsed():
+			if input.Paused() {
+				continue
+			}
+			err := a.gatherOnce(acc, input, ticker, interval)
+			if err != nil {
+				acc.AddError(err)
+			}
+		case <-input.GatherRequested():
 			err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
@@ -670,6 +965,143 @@ func (*Agent) runProcessors(units []*processorUnit) {
 	wg.Wait()
 }
 
+// startProcessorChain starts the given processors in a replaceable chain and
+// returns the source channel of the chain.
+func (a *Agent) startProcessorChain(dst chan<- telegraf.Metric, runningProcessors models.RunningProcessors) (chan<- telegraf.Metric, *processorChain, error) {
+	src := make(chan telegraf.Metric, 100)
+	chain := &processorChain{
+		src: src,
+		dst: dst,
+	}
+	if err := a.startChainProcessors(chain, runningProcessors); err != nil {
+		return nil, nil, err
+	}
+	return src, chain, nil
+}
+
+// startChainProcessors starts the given processors and sets them as the
+// current processors of the chain. Metrics leaving the processors are
+// forwarded to the destination of the chain until the processors are stopped
+// by closing the chain entry.
+func (a *Agent) startChainProcessors(chain *processorChain, runningProcessors models.RunningProcessors) error {
+	exit := make(chan telegraf.Metric, 100)
+
+	entry := chan<- telegraf.Metric(exit)
+	var units []*processorUnit
+	if len(runningProcessors) != 0 {
+		var err error
+		entry, units, err = a.startProcessors(exit, runningProcessors)
+		if err != nil {
+			return err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		go a.runProcessors(units)
+		for m := range exit {
+			chain.dst <- m
+		}
+	}()
+
+	chain.entry = entry
+	chain.processors = runningProcessors
+	chain.done = done
+
+	return nil
+}
+
+// runProcessorChain forwards the metrics to the current processors of the
+// chain until the source channel is closed and all metrics have been written.
+func (*Agent) runProcessorChain(chain *processorChain) {
+	for m := range chain.src {
+		chain.Lock()
+		chain.entry <- m
+		chain.Unlock()
+	}
+
+	chain.Lock()
+	chain.stopped = true
+	close(chain.entry)
+	<-chain.done
+	chain.Unlock()
+
+	close(chain.dst)
+	log.Printf("D! [agent] Processor chain closed")
+}
+
+// routeProcessors returns the processors of the given route in order. An empty
+// route denotes the processors not assigned to any route.
+func routeProcessors(runningProcessors models.RunningProcessors, route string) models.RunningProcessors {
+	var selected models.RunningProcessors
+	for _, processor := range runningProcessors {
+		if processor.Config.Route == route {
+			selected = append(selected, processor)
+		}
+	}
+	return selected
+}
+
+// addRoutes creates the channels of the given routes in the output unit and
+// returns them by route.
+func addRoutes(unit *outputUnit, routes []string) map[string]chan<- telegraf.Metric {
+	dst := make(map[string]chan<- telegraf.Metric, len(routes))
+	unit.routes = make(map[string]<-chan telegraf.Metric, len(routes))
+	for _, route := range routes {
+		c := make(chan telegraf.Metric, 100)
+		unit.routes[route] = c
+		dst[route] = c
+
+		if !slices.ContainsFunc(unit.outputs, func(o *models.RunningOutput) bool { return o.SubscribesTo(route) }) {
+			log.Printf("W! [agent] No output subscribed to route %q, dropping its metrics", route)
+		}
+	}
+	return dst
+}
+
+// startRoutes starts a separate processor chain for each route passing the
+// metrics to the outputs subscribed to the route. The source channels and the
+// chains are returned by route.
+func (a *Agent) startRoutes(unit *outputUnit, routes []string) (map[string]chan<- telegraf.Metric, map[string]*processorChain, error) {
+	dst := addRoutes(unit, routes)
+
+	sources := make(map[string]chan<- telegraf.Metric, len(routes))
+	chains := make(map[string]*processorChain, len(routes))
+	for _, route := range routes {
+		src, chain, err := a.startProcessorChain(dst[route], routeProcessors(a.Config.Processors, route))
+		if err != nil {
+			return nil, nil, err
+		}
+		sources[route] = src
+		chains[route] = chain
+	}
+	return sources, chains, nil
+}
+
+// startRouteProcessors is a variation of startRoutes for use in --test and
+// --once mode starting the processors of each route without a replaceable
+// chain.
+func (a *Agent) startRouteProcessors(dst map[string]chan<- telegraf.Metric) (map[string]chan<- telegraf.Metric, []*processorUnit, error) {
+	sources := make(map[string]chan<- telegraf.Metric, len(dst))
+	var units []*processorUnit
+	for route, c := range dst {
+		runningProcessors := routeProcessors(a.Config.Processors, route)
+		if len(runningProcessors) == 0 {
+			sources[route] = c
+			continue
+		}
+
+		src, pu, err := a.startProcessors(c, runningProcessors)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources[route] = src
+		units = append(units, pu...)
+	}
+	return sources, units, nil
+}
+
 // startAggregators sets up the aggregator unit and returns the source channel.
 func (*Agent) startAggregators(aggC, outputC chan<- telegraf.Metric, aggregators []*models.RunningAggregator) (chan<- telegraf.Metric, *aggregatorUnit) {
 	src := make(chan telegraf.Metric, 100)
@@ -829,54 +1261,94 @@ func (*Agent) connectOutput(ctx context.Context, output *models.RunningOutput) e
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
-	// Start flush loop
-	interval := time.Duration(a.Config.Agent.FlushInterval)
-	jitter := time.Duration(a.Config.Agent.FlushJitter)
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Start flush loop
+	unit.Lock()
+	unit.ctx = ctx
+	unit.running = make(map[*models.RunningOutput]*pluginTask, len(unit.outputs))
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
+		a.runOutput(unit, output)
+	}
+	unit.Unlock()
 
+	var wg sync.WaitGroup
+	for route, src := range unit.routes {
 		wg.Add(1)
-		go func(output *models.RunningOutput) {
+		go func() {
 			defer wg.Done()
+			unit.forward(route, src)
+		}()
+	}
+	unit.forward("", unit.src)
+	wg.Wait()
 
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
+	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
+	unit.Lock()
+	unit.stopped = true
+	unit.Unlock()
+	cancel()
+	unit.wg.Wait()
 
-			a.flushLoop(ctx, output, ticker)
-		}(output)
-	}
+	log.Println("I! [agent] Stopping running outputs")
+	stopRunningOutputs(unit.outputs)
+}
 
-	for metric := range unit.src {
+// forward writes the metrics of the source channel to the outputs subscribed to
+// the given route until the channel is closed.
+func (unit *outputUnit) forward(route string, src <-chan telegraf.Metric) {
+	for metric := range src {
+		unit.RLock()
+		last := -1
 		for i, output := range unit.outputs {
-			if i == len(unit.outputs)-1 {
+			if output.SubscribesTo(route) {
+				last = i
+			}
+		}
+		for i, output := range unit.outputs[:last+1] {
+			switch {
+			case i == last:
 				output.AddMetricNoCopy(metric)
-			} else {
+			case output.SubscribesTo(route):
 				output.AddMetric(metric)
 			}
 		}
+		if last < 0 {
+			metric.Drop()
+		}
+		unit.RUnlock()
 	}
+}
 
-	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
-	cancel()
-	wg.Wait()
+// runOutput starts the flush loop of the given output. The unit must be
+// locked by the caller.
+func (a *Agent) runOutput(unit *outputUnit, output *models.RunningOutput) {
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.FlushInterval)
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
 
-	log.Println("I! [agent] Stopping running outputs")
-	stopRunningOutputs(unit.outputs)
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.FlushJitter)
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	task := &pluginTask{cancel: cancel, done: make(chan struct{})}
+	unit.running[output] = task
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(task.done)
+
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, output, ticker)
+	}()
 }
 
 // flushLoop runs an output's flush function periodically until the context is
@@ -911,10 +1383,18 @@ func (a *Agent) flushLoop(
 			logError(a.flushOnce(output, ticker, output.Write))
 			return
 		case <-ticker.Elapsed():
+			if output.Paused() {
+				continue
+			}
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
 			logError(a.flushOnce(output, ticker, output.Write))
+		case <-output.FlushRequested():
+			logError(a.flushOnce(output, ticker, output.Write))
 		case <-output.BatchReady:
+			if output.Paused() {
+				continue
+			}
 			logError(a.flushBatch(output, output.WriteBatch))
 		}
 	}
@@ -1001,6 +1481,29 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 
 	next := outputC
 
+	// All routes write to the same output channel, so merge them and close
+	// the output channel after all routes are done.
+	var wg sync.WaitGroup
+	routes := a.Config.Routes()
+	routeDst := make(map[string]chan<- telegraf.Metric, len(routes))
+	if len(routes) > 0 {
+		srcs := make([]<-chan telegraf.Metric, 0, len(routes)+1)
+		c := make(chan telegraf.Metric, 100)
+		next = c
+		srcs = append(srcs, c)
+		for _, route := range routes {
+			c := make(chan telegraf.Metric, 100)
+			routeDst[route] = c
+			srcs = append(srcs, c)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mergeChannels(outputC, srcs)
+		}()
+	}
+
 	var apu []*processorUnit
 	var au *aggregatorUnit
 	if len(a.Config.Aggregators) != 0 {
@@ -1017,17 +1520,22 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 	}
 
 	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
+	if processors := routeProcessors(a.Config.Processors, ""); len(processors) != 0 {
 		var err error
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+		next, pu, err = a.startProcessors(next, processors)
 		if err != nil {
 			return err
 		}
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
+	routeSrc, rpu, err := a.startRouteProcessors(routeDst)
+	if err != nil {
+		return err
+	}
+	pu = append(pu, rpu...)
+
+	iu := a.testStartInputs(next, routeSrc, a.Config.Inputs)
 
-	var wg sync.WaitGroup
 	if au != nil {
 		wg.Add(1)
 		go func() {
@@ -1125,14 +1633,20 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 	}
 
 	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	if processors := routeProcessors(a.Config.Processors, ""); len(processors) != 0 {
+		next, pu, err = a.startProcessors(next, processors)
 		if err != nil {
 			return err
 		}
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
+	routes, rpu, err := a.startRouteProcessors(addRoutes(ou, a.Config.Routes()))
+	if err != nil {
+		return err
+	}
+	pu = append(pu, rpu...)
+
+	iu := a.testStartInputs(next, routes, a.Config.Inputs)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -1176,6 +1690,23 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 	return nil
 }
 
+// mergeChannels forwards the metrics of all source channels to the destination
+// channel and closes the destination after all sources are closed.
+func mergeChannels(dst chan<- telegraf.Metric, srcs []<-chan telegraf.Metric) {
+	var wg sync.WaitGroup
+	for _, src := range srcs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range src {
+				dst <- m
+			}
+		}()
+	}
+	wg.Wait()
+	close(dst)
+}
+
 // Returns the rounding precision for metrics.
 func getPrecision(precision, interval time.Duration) time.Duration {
 	if precision > 0 {
@@ -1207,9 +1738,3 @@ func panicRecover(input *models.RunningInput) {
 			"https://github.com/influxdata/telegraf/issues/new/choose")
 	}
 }
-
-func stopTickers(tickers []Ticker) {
-	for _, ticker := range tickers {
-		ticker.Stop()
-	}
-}
@@ -2,10 +2,9 @@ package agent
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,7 +13,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
@@ -118,6 +119,68 @@ func TestAgent_LoadOutput(t *testing.T) {
 	require.Len(t, a.Config.Outputs, 3)
 }
 
+func TestAgent_DeadLetterOutput(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[outputs.discard]]
+  dead_letter_output = "dlq"
+
+[[outputs.discard]]
+  alias = "dlq"
+`), config.EmptySourcePath))
+	require.NoError(t, linkDeadLetterOutputs(c.Outputs))
+	require.Same(t, c.Outputs[1], c.Outputs[0].DeadLetterOutput())
+	require.Nil(t, c.Outputs[1].DeadLetterOutput())
+
+	// Unknown dead-letter outputs must be reported
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[outputs.discard]]
+  dead_letter_output = "dlq"
+`), config.EmptySourcePath))
+	require.ErrorContains(t, linkDeadLetterOutputs(c.Outputs), `dead-letter output "dlq" of output outputs.discard not found`)
+
+	// Outputs must not reference each other in a cycle
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[outputs.discard]]
+  alias = "a"
+  dead_letter_output = "b"
+
+[[outputs.discard]]
+  alias = "b"
+  dead_letter_output = "a"
+`), config.EmptySourcePath))
+	require.ErrorContains(t, linkDeadLetterOutputs(c.Outputs), "form a cycle")
+}
+
+func TestOutputUnitForwardRoutes(t *testing.T) {
+	newOutput := func(routes ...string) *models.RunningOutput {
+		return models.NewRunningOutput(&reloadTestOutput{}, &models.OutputConfig{Name: "test", Routes: routes}, 10, 100)
+	}
+	unrouted := newOutput()
+	billing := newOutput("billing")
+	both := newOutput("billing", "audit")
+	unit := &outputUnit{outputs: []*models.RunningOutput{unrouted, billing, both}}
+
+	forward := func(route string, n int) {
+		src := make(chan telegraf.Metric, n)
+		for range n {
+			src <- testutil.TestMetric(42)
+		}
+		close(src)
+		unit.forward(route, src)
+	}
+	forward("", 1)
+	forward("billing", 2)
+	forward("audit", 3)
+	forward("unknown", 4)
+
+	require.Equal(t, 1, unrouted.BufferLength())
+	require.Equal(t, 2, billing.BufferLength())
+	require.Equal(t, 5, both.BufferLength())
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
@@ -215,7 +278,7 @@ func TestCases(t *testing.T) {
 			agent := NewAgent(cfg)
 			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
 			defer cancel()
-			actual, err := collect(ctx, agent, 0)
+			actual, err := agent.TestCollect(ctx, 0)
 			require.NoError(t, err)
 
 			// Process expected metrics and compare with resulting metrics
@@ -228,32 +291,91 @@ func TestCases(t *testing.T) {
 	}
 }
 
-// Implement a "test-mode" like call but collect the metrics
-func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metric, error) {
-	var received []telegraf.Metric
-	var mu sync.Mutex
-
-	src := make(chan telegraf.Metric, 100)
-
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for m := range src {
-			mu.Lock()
-			received = append(received, m)
-			mu.Unlock()
-			m.Reject()
-		}
-	}()
-
-	if err := a.runTest(ctx, wait, src); err != nil {
-		return nil, err
+func TestCheckpointStateWhileRunning(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+	}{
+		{
+			name: "processor",
+			cfg: `
+[[processors.dedup]]
+  dedup_interval = "1h"
+`,
+		},
+		{
+			name: "processor modifying state",
+			cfg: `
+[[processors.cumulative_sum]]
+`,
+		},
+		{
+			name: "aggregator",
+			cfg: `
+[[aggregators.basicstats]]
+  period = "1h"
+`,
+		},
+		{
+			name: "aggregator modifying state",
+			cfg: `
+[[aggregators.derivative]]
+  period = "1h"
+`,
+		},
 	}
-	wg.Wait()
 
-	if models.GlobalGatherErrors.Get() != 0 {
-		return received, fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			require.NoError(t, c.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath))
+			c.Persister = &persister.Persister{Filename: filepath.Join(t.TempDir(), "state.json")}
+
+			a := NewAgent(c)
+			require.NoError(t, a.initPersister())
+
+			// Add metrics the same way the agent does for the plugin
+			var add func(m telegraf.Metric)
+			switch {
+			case len(c.Processors) > 0:
+				processor := c.Processors[0]
+				require.NoError(t, processor.Init())
+				acc := &testutil.Accumulator{}
+				add = func(m telegraf.Metric) {
+					require.NoError(t, processor.Add(m, acc))
+				}
+			case len(c.Aggregators) > 0:
+				aggregator := c.Aggregators[0]
+				require.NoError(t, aggregator.Init())
+				now := time.Now()
+				aggregator.UpdateWindow(now.Add(-time.Hour), now.Add(time.Hour))
+				add = func(m telegraf.Metric) {
+					aggregator.Add(m)
+				}
+			}
+
+			// Store the state concurrently to the plugin adding metrics to its
+			// cache to trigger the race detector in case of unsynchronized
+			// access
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ts := time.Now()
+				for i := range 10000 {
+					m := metric.New("test", map[string]string{"id": strconv.Itoa(i % 10)}, map[string]interface{}{"value": i}, ts)
+					add(m)
+				}
+			}()
+
+			for {
+				select {
+				case <-done:
+					require.NoError(t, c.Persister.Store())
+					return
+				default:
+					require.NoError(t, c.Persister.Store())
+				}
+			}
+		})
 	}
-	return received, nil
 }
@@ -0,0 +1,318 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// apiServer exposes the running plugins of the agent via HTTP and allows to
+// trigger actions on single plugins.
+type apiServer struct {
+	agent    *Agent
+	server   *http.Server
+	listener net.Listener
+	done     chan struct{}
+}
+
+type apiPlugin struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+}
+
+type apiGather struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	Error    string    `json:"error,omitempty"`
+}
+
+type apiInput struct {
+	apiPlugin
+	Paused     bool       `json:"paused"`
+	LastGather *apiGather `json:"last_gather,omitempty"`
+}
+
+type apiProcessor struct {
+	apiPlugin
+	Order int64 `json:"order"`
+}
+
+type apiAggregator struct {
+	apiPlugin
+	Period string `json:"period"`
+}
+
+type apiBuffer struct {
+	Strategy string   `json:"strategy"`
+	Size     int      `json:"size"`
+	Limit    int      `json:"limit,omitempty"`
+	Fill     *float64 `json:"fill_percent,omitempty"`
+}
+
+type apiOutput struct {
+	apiPlugin
+	Paused bool      `json:"paused"`
+	Buffer apiBuffer `json:"buffer"`
+}
+
+type apiPlugins struct {
+	Inputs      []apiInput      `json:"inputs"`
+	Processors  []apiProcessor  `json:"processors"`
+	Aggregators []apiAggregator `json:"aggregators"`
+	Outputs     []apiOutput     `json:"outputs"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// listen creates a listener for the given address. Addresses starting with
+// "unix://" denote a unix socket, all others a TCP address.
+func listen(address string) (net.Listener, error) {
+	network := "tcp"
+	if path, found := strings.CutPrefix(address, "unix://"); found {
+		network = "unix"
+		address = path
+		// Remove stale sockets of previous runs
+		if err := os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing existing socket failed: %w", err)
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %q failed: %w", address, err)
+	}
+	return listener, nil
+}
+
+// newAPIServer creates a server listening on the given address. As the API
+// does not provide any authentication, only unix sockets and loopback
+// addresses are accepted.
+func newAPIServer(a *Agent, address string) (*apiServer, error) {
+	listener, err := listen(address)
+	if err != nil {
+		return nil, err
+	}
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok && !addr.IP.IsLoopback() {
+		listener.Close()
+		return nil, fmt.Errorf("refusing to serve the unauthenticated API on non-loopback address %q", address)
+	}
+
+	s := &apiServer{
+		agent:    a,
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", s.listPlugins)
+	mux.HandleFunc("POST /api/v1/inputs/{id}/gather", s.gather)
+	mux.HandleFunc("POST /api/v1/outputs/{id}/flush", s.flush)
+	mux.HandleFunc("POST /api/v1/{category}/{id}/pause", s.pause)
+	mux.HandleFunc("POST /api/v1/{category}/{id}/resume", s.resume)
+
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+func (s *apiServer) start() {
+	log.Printf("I! [agent] Starting API server on %s", s.listener.Addr())
+	go func() {
+		defer close(s.done)
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving API failed: %v", err)
+		}
+	}()
+}
+
+func (s *apiServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping API server failed: %v", err)
+	}
+	<-s.done
+}
+
+func (s *apiServer) listPlugins(w http.ResponseWriter, _ *http.Request) {
+	running := s.agent.runningPlugins()
+
+	plugins := apiPlugins{
+		Inputs:      make([]apiInput, 0, len(running.inputs)),
+		Processors:  make([]apiProcessor, 0, len(running.processors)),
+		Aggregators: make([]apiAggregator, 0, len(running.aggregators)),
+		Outputs:     make([]apiOutput, 0, len(running.outputs)),
+	}
+
+	for _, input := range running.inputs {
+		info := apiInput{
+			apiPlugin: apiPlugin{ID: input.ID(), Name: input.Config.Name, Alias: input.Config.Alias},
+			Paused:    input.Paused(),
+		}
+		if status := input.LastGather(); !status.Time.IsZero() {
+			info.LastGather = &apiGather{
+				Time:     status.Time,
+				Duration: status.Duration.String(),
+			}
+			if status.Err != nil {
+				info.LastGather.Error = status.Err.Error()
+			}
+		}
+		plugins.Inputs = append(plugins.Inputs, info)
+	}
+
+	for _, processor := range running.processors {
+		plugins.Processors = append(plugins.Processors, apiProcessor{
+			apiPlugin: apiPlugin{ID: processor.ID(), Name: processor.Config.Name, Alias: processor.Config.Alias},
+			Order:     processor.Config.Order,
+		})
+	}
+
+	for _, aggregator := range running.aggregators {
+		plugins.Aggregators = append(plugins.Aggregators, apiAggregator{
+			apiPlugin: apiPlugin{ID: aggregator.ID(), Name: aggregator.Config.Name, Alias: aggregator.Config.Alias},
+			Period:    aggregator.Config.Period.String(),
+		})
+	}
+
+	for _, output := range running.outputs {
+		strategy := output.Config.BufferStrategy
+		if strategy == "" {
+			strategy = "memory"
+		}
+		buffer := apiBuffer{
+			Strategy: strategy,
+			Size:     output.BufferLength(),
+		}
+		// The metric limit only applies to memory buffers, the fill of disk
+		// buffers refers to their size on disk
+		if strategy == "memory" {
+			buffer.Limit = output.MetricBufferLimit
+		}
+		if fill, ok := output.BufferFill(); ok {
+			buffer.Fill = &fill
+		}
+		plugins.Outputs = append(plugins.Outputs, apiOutput{
+			apiPlugin: apiPlugin{ID: output.ID(), Name: output.Config.Name, Alias: output.Config.Alias},
+			Paused:    output.Paused(),
+			Buffer:    buffer,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, plugins)
+}
+
+func (s *apiServer) gather(w http.ResponseWriter, r *http.Request) {
+	inputs := s.findInputs(r.PathValue("id"))
+	if len(inputs) == 0 {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "input not found"})
+		return
+	}
+	for _, input := range inputs {
+		input.RequestGather()
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *apiServer) flush(w http.ResponseWriter, r *http.Request) {
+	outputs := s.findOutputs(r.PathValue("id"))
+	if len(outputs) == 0 {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "output not found"})
+		return
+	}
+	for _, output := range outputs {
+		output.RequestFlush()
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *apiServer) pause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+func (s *apiServer) resume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+func (s *apiServer) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id := r.PathValue("id")
+
+	var found bool
+	switch category := r.PathValue("category"); category {
+	case "inputs":
+		for _, input := range s.findInputs(id) {
+			if paused {
+				input.Pause()
+			} else {
+				input.Resume()
+			}
+			found = true
+		}
+	case "outputs":
+		for _, output := range s.findOutputs(id) {
+			if paused {
+				output.Pause()
+			} else {
+				output.Resume()
+			}
+			found = true
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("pausing %s is not supported", category)})
+		return
+	}
+
+	if !found {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "plugin not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findInputs returns all inputs with the given ID. Multiple plugins can share
+// an ID if their configuration is identical.
+func (s *apiServer) findInputs(id string) []*models.RunningInput {
+	var inputs []*models.RunningInput
+	for _, input := range s.agent.runningPlugins().inputs {
+		if input.ID() == id {
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs
+}
+
+// findOutputs returns all outputs with the given ID. Multiple plugins can
+// share an ID if their configuration is identical.
+func (s *apiServer) findOutputs(id string) []*models.RunningOutput {
+	var outputs []*models.RunningOutput
+	for _, output := range s.agent.runningPlugins().outputs {
+		if output.ID() == id {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("E! [agent] Encoding API response failed: %v", err)
+	}
+}
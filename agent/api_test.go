@@ -0,0 +1,282 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type apiTestInput struct {
+	err error
+}
+
+func (*apiTestInput) SampleConfig() string {
+	return ""
+}
+
+func (i *apiTestInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddError(i.err)
+	return nil
+}
+
+type apiTestOutput struct{}
+
+func (*apiTestOutput) SampleConfig() string {
+	return ""
+}
+
+func (*apiTestOutput) Connect() error {
+	return nil
+}
+
+func (*apiTestOutput) Close() error {
+	return nil
+}
+
+func (*apiTestOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func newAPITestServer(t *testing.T) (*apiServer, *config.Config) {
+	t.Helper()
+
+	cfg := config.NewConfig()
+	input := models.NewRunningInput(&apiTestInput{err: errors.New("boom")}, &models.InputConfig{Name: "test", ID: "in1"})
+	cfg.Inputs = append(cfg.Inputs, input)
+	output := models.NewRunningOutput(&apiTestOutput{}, &models.OutputConfig{Name: "test", ID: "out1"}, 10, 100)
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := NewAgent(cfg)
+	a.publishPlugins()
+	s, err := newAPIServer(a, "127.0.0.1:0")
+	require.NoError(t, err)
+	s.start()
+	t.Cleanup(s.stop)
+
+	return s, cfg
+}
+
+func TestAPIListPlugins(t *testing.T) {
+	s, cfg := newAPITestServer(t)
+
+	require.NoError(t, cfg.Inputs[0].Gather(&testutil.Accumulator{}))
+	cfg.Outputs[0].AddMetric(testutil.TestMetric(42))
+
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/api/v1/plugins")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var plugins apiPlugins
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plugins))
+
+	require.Len(t, plugins.Inputs, 1)
+	require.Equal(t, "in1", plugins.Inputs[0].ID)
+	require.NotNil(t, plugins.Inputs[0].LastGather)
+	require.Equal(t, "boom", plugins.Inputs[0].LastGather.Error)
+
+	require.Len(t, plugins.Outputs, 1)
+	require.Equal(t, "out1", plugins.Outputs[0].ID)
+	fill := 1.0
+	require.Equal(t, apiBuffer{Strategy: "memory", Size: 1, Limit: 100, Fill: &fill}, plugins.Outputs[0].Buffer)
+}
+
+func TestAPIListPluginsDiskBuffer(t *testing.T) {
+	cfg := config.NewConfig()
+	output := models.NewRunningOutput(&apiTestOutput{}, &models.OutputConfig{
+		Name:            "test",
+		ID:              "out1",
+		BufferStrategy:  "disk_segmented",
+		BufferDirectory: t.TempDir(),
+		BufferMaxSize:   1024 * 1024,
+	}, 10, 1)
+	t.Cleanup(output.Close)
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := NewAgent(cfg)
+	a.publishPlugins()
+	s, err := newAPIServer(a, "127.0.0.1:0")
+	require.NoError(t, err)
+	s.start()
+	t.Cleanup(s.stop)
+
+	// The metric buffer limit must not affect the fill of disk buffers
+	for range 5 {
+		output.AddMetric(testutil.TestMetric(42))
+	}
+
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/api/v1/plugins")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var plugins apiPlugins
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plugins))
+	require.Len(t, plugins.Outputs, 1)
+
+	buffer := plugins.Outputs[0].Buffer
+	require.Equal(t, "disk_segmented", buffer.Strategy)
+	require.Equal(t, 5, buffer.Size)
+	require.Zero(t, buffer.Limit)
+	require.NotNil(t, buffer.Fill)
+	require.Greater(t, *buffer.Fill, 0.0)
+	require.Less(t, *buffer.Fill, 1.0)
+}
+
+func TestAPIRefuseNonLoopback(t *testing.T) {
+	_, err := newAPIServer(NewAgent(config.NewConfig()), "0.0.0.0:0")
+	require.ErrorContains(t, err, "refusing to serve the unauthenticated API on non-loopback address")
+}
+
+func TestAPIActions(t *testing.T) {
+	s, cfg := newAPITestServer(t)
+	addr := "http://" + s.listener.Addr().String()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{name: "gather", path: "/api/v1/inputs/in1/gather", expected: http.StatusAccepted},
+		{name: "gather unknown", path: "/api/v1/inputs/foo/gather", expected: http.StatusNotFound},
+		{name: "flush", path: "/api/v1/outputs/out1/flush", expected: http.StatusAccepted},
+		{name: "pause input", path: "/api/v1/inputs/in1/pause", expected: http.StatusNoContent},
+		{name: "pause output", path: "/api/v1/outputs/out1/pause", expected: http.StatusNoContent},
+		{name: "pause processor", path: "/api/v1/processors/p1/pause", expected: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(addr+tt.path, "", nil)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+
+	require.True(t, cfg.Inputs[0].Paused())
+	require.True(t, cfg.Outputs[0].Paused())
+	require.Len(t, cfg.Inputs[0].GatherRequested(), 1)
+	require.Len(t, cfg.Outputs[0].FlushRequested(), 1)
+
+	resp, err := http.Post(addr+"/api/v1/inputs/in1/resume", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.False(t, cfg.Inputs[0].Paused())
+}
+
+func TestAgentStatusProvider(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(10 * time.Second)
+	input := models.NewRunningInput(&apiTestInput{}, &models.InputConfig{Name: "test", ID: "in1"})
+	cfg.Inputs = append(cfg.Inputs, input)
+	output := models.NewRunningOutput(&apiTestOutput{}, &models.OutputConfig{Name: "test", ID: "out1"}, 10, 100)
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := NewAgent(cfg)
+	a.publishPlugins()
+	var status models.StatusProvider = &agentStatus{agent: a}
+
+	require.NoError(t, input.Gather(&testutil.Accumulator{}))
+	inputs := status.InputStatus()
+	require.Len(t, inputs, 1)
+	require.Equal(t, "in1", inputs[0].ID)
+	require.Equal(t, 10*time.Second, inputs[0].Interval)
+	require.False(t, inputs[0].LastGather.LastSuccess.IsZero())
+
+	output.AddMetric(testutil.TestMetric(42))
+	outputs := status.OutputStatus()
+	require.Len(t, outputs, 1)
+	require.Equal(t, "out1", outputs[0].ID)
+	require.Equal(t, 1, outputs[0].BufferSize)
+	require.Equal(t, 100, outputs[0].BufferLimit)
+	require.True(t, outputs[0].BufferLimited)
+	require.InDelta(t, 1.0, outputs[0].BufferFill, 0)
+
+	// Querying the status must not wait for a running reload
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+	require.Len(t, status.InputStatus(), 1)
+	require.Len(t, status.OutputStatus(), 1)
+}
+
+func TestAPIDuringReload(t *testing.T) {
+	cfg := newReloadTestConfig()
+	cfg.Agent.APIAddress = "127.0.0.1:0"
+	cfg.Inputs = append(cfg.Inputs, newReloadTestInput("in1"))
+	processor, _ := newReloadTestProcessor("proc1")
+	cfg.Processors = append(cfg.Processors, processor)
+	output, _ := newReloadTestOutput("out1")
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := runReloadTestAgent(t, cfg)
+	s, err := newAPIServer(a, "127.0.0.1:0")
+	require.NoError(t, err)
+	s.start()
+	defer s.stop()
+	addr := "http://" + s.listener.Addr().String()
+
+	// Query the API concurrently to reloads replacing the plugins
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			for _, path := range []string{"/api/v1/plugins", "/api/v1/inputs/in2/pause", "/api/v1/outputs/out2/flush"} {
+				method := http.MethodPost
+				if path == "/api/v1/plugins" {
+					method = http.MethodGet
+				}
+				req, err := http.NewRequestWithContext(ctx, method, addr+path, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					continue
+				}
+				resp.Body.Close()
+			}
+		}
+	}()
+
+	for i := range 10 {
+		updated := newReloadTestConfig()
+		updated.Agent.APIAddress = "127.0.0.1:0"
+		updated.Inputs = append(updated.Inputs, newReloadTestInput("in"+strconv.Itoa(i%2+1)))
+		processor, _ := newReloadTestProcessor("proc" + strconv.Itoa(i%2+1))
+		updated.Processors = append(updated.Processors, processor)
+		output, _ := newReloadTestOutput("out" + strconv.Itoa(i%2+1))
+		updated.Outputs = append(updated.Outputs, output)
+		require.NoError(t, a.Reload(t.Context(), updated))
+	}
+	cancel()
+	wg.Wait()
+
+	resp, err := http.Get(addr + "/api/v1/plugins")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var plugins apiPlugins
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plugins))
+	require.Len(t, plugins.Inputs, 1)
+	require.Equal(t, "in2", plugins.Inputs[0].ID)
+	require.Len(t, plugins.Processors, 1)
+	require.Equal(t, "proc2", plugins.Processors[0].ID)
+	require.Len(t, plugins.Outputs, 1)
+	require.Equal(t, "out2", plugins.Outputs[0].ID)
+}
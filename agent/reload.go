@@ -0,0 +1,415 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/models"
+)
+
+// ErrFullReloadRequired is returned by Reload if the configuration changes
+// cannot be applied to the running agent and the agent must be restarted.
+var ErrFullReloadRequired = errors.New("configuration changes require a full reload")
+
+var errNotRunning = errors.New("agent is not running")
+
+// Reload applies the given configuration to the running agent. Plugins are
+// matched by their ID and only inputs, processors and outputs with a changed
+// configuration are stopped or started. Unchanged outputs keep running
+// including their buffered metrics. As processors form a chain, all processors
+// of a route are restarted if any of them changed, carrying over the state of
+// unchanged stateful processors.
+//
+// ErrFullReloadRequired is returned for changes that cannot be applied to the
+// running agent such as changed agent settings, global tags, aggregators or
+// the set of routes.
+// On any other error the agent might be partially reloaded and should be
+// restarted.
+func (a *Agent) Reload(ctx context.Context, cfg *config.Config) error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	if a.inputs == nil || a.processors == nil || a.outputs == nil {
+		return errNotRunning
+	}
+
+	if err := a.checkReload(cfg); err != nil {
+		return err
+	}
+
+	inputs, addedInputs, removedInputs := diffPlugins(a.Config.Inputs, cfg.Inputs)
+	outputs, addedOutputs, removedOutputs := diffPlugins(a.Config.Outputs, cfg.Outputs)
+	processors, changedRoutes := a.diffProcessors(cfg.Processors)
+	processorsChanged := len(changedRoutes) > 0
+
+	// Initialize the new plugins before touching the running agent
+	for _, input := range addedInputs {
+		// Share the snmp translator setting with plugins that need it.
+		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
+		}
+		a.linkStatusProvider(input.Input)
+		if err := input.Init(); err != nil {
+			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
+		}
+	}
+	var previousProcessors, newProcessors models.RunningProcessors
+	for _, route := range changedRoutes {
+		previousProcessors = append(previousProcessors, routeProcessors(a.Config.Processors, route)...)
+		newProcessors = append(newProcessors, routeProcessors(cfg.Processors, route)...)
+	}
+	for _, processor := range newProcessors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+		}
+	}
+	for _, output := range addedOutputs {
+		a.linkStatusProvider(output.Output)
+		if err := output.Init(); err != nil {
+			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
+		}
+	}
+	if err := linkDeadLetterOutputs(outputs); err != nil {
+		return err
+	}
+
+	if a.Config.Persister != nil {
+		if err := a.reloadPersister(removedInputs, previousProcessors, removedOutputs, addedInputs, newProcessors, addedOutputs); err != nil {
+			return err
+		}
+	}
+
+	// Connect the new outputs first to not lose any metrics of new plugins
+	// and remove the outputs last to flush the metrics of removed plugins.
+	for _, output := range addedOutputs {
+		if err := a.addOutput(ctx, a.outputs, output); err != nil {
+			return err
+		}
+	}
+	for _, route := range changedRoutes {
+		chain := a.processors
+		if route != "" {
+			chain = a.routes[route]
+		}
+		if err := a.replaceProcessors(chain, routeProcessors(cfg.Processors, route)); err != nil {
+			return err
+		}
+	}
+	for _, input := range addedInputs {
+		if err := a.addInput(a.inputs, input); err != nil {
+			return err
+		}
+	}
+	for _, input := range removedInputs {
+		a.removeInput(a.inputs, input)
+	}
+	for _, output := range removedOutputs {
+		a.removeOutput(a.outputs, output)
+	}
+
+	a.Config.Inputs = inputs
+	a.Config.Processors = processors
+	a.Config.Outputs = outputs
+	a.publishPlugins()
+
+	log.Printf("I! [agent] Reloaded configuration: %d inputs and %d outputs added, %d inputs and %d outputs removed, processors changed: %t",
+		len(addedInputs), len(addedOutputs), len(removedInputs), len(removedOutputs), processorsChanged)
+
+	return nil
+}
+
+// checkReload checks if the changes of the given configuration can be applied
+// to the running agent.
+func (a *Agent) checkReload(cfg *config.Config) error {
+	current := *a.Config.Agent
+	updated := *cfg.Agent
+	// The agent sets the default when running, so only compare explicit settings
+	if updated.SkipProcessorsAfterAggregators == nil {
+		updated.SkipProcessorsAfterAggregators = current.SkipProcessorsAfterAggregators
+	}
+	if !reflect.DeepEqual(current, updated) {
+		return fmt.Errorf("%w: agent settings changed", ErrFullReloadRequired)
+	}
+
+	if !maps.Equal(a.Config.Tags, cfg.Tags) {
+		return fmt.Errorf("%w: global tags changed", ErrFullReloadRequired)
+	}
+
+	if !slices.Equal(pluginIDs(a.Config.Aggregators), pluginIDs(cfg.Aggregators)) {
+		return fmt.Errorf("%w: aggregators changed", ErrFullReloadRequired)
+	}
+
+	// Each route is wired into the inputs and outputs at startup
+	if !slices.Equal(a.Config.Routes(), cfg.Routes()) {
+		return fmt.Errorf("%w: routes changed", ErrFullReloadRequired)
+	}
+
+	// Processors running after the aggregators are separate instances wired
+	// into the aggregator unit and cannot be replaced.
+	before, after := routeProcessors(a.Config.Processors, ""), routeProcessors(cfg.Processors, "")
+	processorsChanged := !slices.Equal(pluginIDs(before), pluginIDs(after))
+	if processorsChanged && len(a.Config.Aggregators) > 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+		return fmt.Errorf("%w: processors running after aggregators changed", ErrFullReloadRequired)
+	}
+
+	return nil
+}
+
+// reloadPersister unregisters the states of the removed plugins and registers
+// the added plugins. Aggregators are not covered as changing them requires a
+// full reload.
+func (a *Agent) reloadPersister(
+	removedInputs []*models.RunningInput,
+	removedProcessors models.RunningProcessors,
+	removedOutputs []*models.RunningOutput,
+	addedInputs []*models.RunningInput,
+	addedProcessors models.RunningProcessors,
+	addedOutputs []*models.RunningOutput,
+) error {
+	p := a.Config.Persister
+
+	for _, input := range removedInputs {
+		if _, ok := input.Input.(telegraf.StatefulPlugin); ok {
+			p.Unregister(input.ID())
+		}
+	}
+	for _, processor := range removedProcessors {
+		if _, ok := statefulProcessor(processor); ok {
+			p.Unregister(processor.ID())
+		}
+	}
+	for _, output := range removedOutputs {
+		if _, ok := output.Output.(telegraf.StatefulPlugin); ok {
+			p.Unregister(output.ID())
+		}
+	}
+
+	for _, input := range addedInputs {
+		if plugin, ok := input.Input.(telegraf.StatefulPlugin); ok {
+			if err := p.Register(input.ID(), plugin); err != nil {
+				return fmt.Errorf("could not register input %s: %w", input.LogName(), err)
+			}
+		}
+	}
+	for _, processor := range addedProcessors {
+		if plugin, ok := statefulProcessor(processor); ok {
+			if err := p.Register(processor.ID(), &lockedState{processor, plugin}); err != nil {
+				return fmt.Errorf("could not register processor %s: %w", processor.LogName(), err)
+			}
+		}
+	}
+	for _, output := range addedOutputs {
+		if plugin, ok := output.Output.(telegraf.StatefulPlugin); ok {
+			if err := p.Register(output.ID(), plugin); err != nil {
+				return fmt.Errorf("could not register output %s: %w", output.LogName(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// addInput starts the given input and its gather loop in the running unit.
+func (a *Agent) addInput(unit *inputUnit, input *models.RunningInput) error {
+	unit.Lock()
+	defer unit.Unlock()
+
+	if unit.stopped || unit.running == nil {
+		return errNotRunning
+	}
+
+	started, err := a.startInput(unit.destination(input), input)
+	if err != nil || !started {
+		return err
+	}
+	unit.inputs = append(unit.inputs, input)
+	a.runInput(unit, input)
+
+	return nil
+}
+
+// removeInput stops the gather loop of the given input and stops the plugin.
+func (*Agent) removeInput(unit *inputUnit, input *models.RunningInput) {
+	unit.Lock()
+	task, found := unit.running[input]
+	if unit.stopped || !found {
+		unit.Unlock()
+		return
+	}
+	delete(unit.running, input)
+	unit.inputs = slices.DeleteFunc(unit.inputs, func(i *models.RunningInput) bool { return i == input })
+	unit.Unlock()
+
+	task.stop()
+	input.Stop()
+}
+
+// addOutput connects the given output and starts its flush loop in the running
+// unit.
+func (a *Agent) addOutput(ctx context.Context, unit *outputUnit, output *models.RunningOutput) error {
+	if err := a.connectOutput(ctx, output); err != nil {
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			// If the model tells us to remove the plugin we do so without error
+			log.Printf("I! [agent] Failed to connect to [%s], error was %q;  shutting down plugin...", output.LogName(), err)
+			output.Close()
+			return nil
+		}
+		return fmt.Errorf("connecting output %s: %w", output.LogName(), err)
+	}
+
+	unit.Lock()
+	defer unit.Unlock()
+
+	if unit.stopped || unit.running == nil {
+		output.Close()
+		return errNotRunning
+	}
+	unit.outputs = append(unit.outputs, output)
+	a.runOutput(unit, output)
+
+	return nil
+}
+
+// removeOutput detaches the given output from the running unit, flushes the
+// buffered metrics one last time and closes the output.
+func (*Agent) removeOutput(unit *outputUnit, output *models.RunningOutput) {
+	unit.Lock()
+	task, found := unit.running[output]
+	if unit.stopped || !found {
+		unit.Unlock()
+		return
+	}
+	delete(unit.running, output)
+	unit.outputs = slices.DeleteFunc(unit.outputs, func(o *models.RunningOutput) bool { return o == output })
+	unit.Unlock()
+
+	task.stop()
+	output.Close()
+}
+
+// replaceProcessors stops the current processors of the chain after all queued
+// metrics passed them and starts the given processors in their place.
+func (a *Agent) replaceProcessors(chain *processorChain, runningProcessors models.RunningProcessors) error {
+	chain.Lock()
+	defer chain.Unlock()
+
+	if chain.stopped {
+		return errNotRunning
+	}
+
+	close(chain.entry)
+	<-chain.done
+
+	// Carry over the state of unchanged processors
+	states := make(map[string]telegraf.StatefulPlugin, len(chain.processors))
+	for _, processor := range chain.processors {
+		if plugin, ok := statefulProcessor(processor); ok {
+			states[processor.ID()] = plugin
+		}
+	}
+	for _, processor := range runningProcessors {
+		plugin, ok := statefulProcessor(processor)
+		if !ok {
+			continue
+		}
+		if previous, found := states[processor.ID()]; found {
+			if err := plugin.SetState(previous.GetState()); err != nil {
+				log.Printf("W! [agent] Carrying over state of %s failed: %v", processor.LogName(), err)
+			}
+		}
+	}
+
+	if err := a.startChainProcessors(chain, runningProcessors); err != nil {
+		// The previous processors are stopped already, so keep the metrics
+		// flowing unprocessed until the agent is restarted.
+		//nolint:errcheck // starting the chain cannot fail without processors
+		a.startChainProcessors(chain, nil)
+		return err
+	}
+
+	return nil
+}
+
+// diffProcessors returns the routes with changed processors, including the
+// empty route for the processors not assigned to any route. The merged list
+// follows the updated processors but contains the current instances for the
+// routes without changes.
+func (a *Agent) diffProcessors(updated models.RunningProcessors) (merged models.RunningProcessors, changed []string) {
+	changedRoutes := make(map[string]bool)
+	for _, route := range append([]string{""}, a.Config.Routes()...) {
+		current := routeProcessors(a.Config.Processors, route)
+		if !slices.Equal(pluginIDs(current), pluginIDs(routeProcessors(updated, route))) {
+			changed = append(changed, route)
+			changedRoutes[route] = true
+		}
+	}
+
+	// Unchanged routes contain the same processors in the same order
+	current := make(map[string]models.RunningProcessors)
+	for _, processor := range a.Config.Processors {
+		current[processor.Config.Route] = append(current[processor.Config.Route], processor)
+	}
+	merged = make(models.RunningProcessors, 0, len(updated))
+	for _, processor := range updated {
+		route := processor.Config.Route
+		if !changedRoutes[route] {
+			processor, current[route] = current[route][0], current[route][1:]
+		}
+		merged = append(merged, processor)
+	}
+
+	return merged, changed
+}
+
+// diffPlugins matches the updated plugins against the current ones by ID. The
+// merged list follows the order of the updated plugins but contains the
+// current instance for each unchanged plugin.
+func diffPlugins[T interface {
+	comparable
+	ID() string
+}](current, updated []T) (merged, added, removed []T) {
+	candidates := make(map[string][]T, len(current))
+	for _, plugin := range current {
+		candidates[plugin.ID()] = append(candidates[plugin.ID()], plugin)
+	}
+
+	kept := make(map[T]bool, len(current))
+	for _, plugin := range updated {
+		id := plugin.ID()
+		if c := candidates[id]; len(c) > 0 {
+			merged = append(merged, c[0])
+			kept[c[0]] = true
+			candidates[id] = c[1:]
+			continue
+		}
+		merged = append(merged, plugin)
+		added = append(added, plugin)
+	}
+
+	for _, plugin := range current {
+		if !kept[plugin] {
+			removed = append(removed, plugin)
+		}
+	}
+
+	return merged, added, removed
+}
+
+// pluginIDs returns the IDs of the given plugins in order.
+func pluginIDs[T interface{ ID() string }](plugins []T) []string {
+	ids := make([]string, 0, len(plugins))
+	for _, plugin := range plugins {
+		ids = append(ids, plugin.ID())
+	}
+	return ids
+}
@@ -0,0 +1,341 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type reloadTestInput struct{}
+
+func (*reloadTestInput) SampleConfig() string {
+	return ""
+}
+
+func (*reloadTestInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("test", map[string]interface{}{"value": 42}, nil)
+	return nil
+}
+
+type reloadTestProcessor struct {
+	sync.Mutex
+	count int
+}
+
+func (*reloadTestProcessor) SampleConfig() string {
+	return ""
+}
+
+func (p *reloadTestProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	p.Lock()
+	defer p.Unlock()
+	p.count += len(in)
+	return in
+}
+
+func (p *reloadTestProcessor) GetState() interface{} {
+	p.Lock()
+	defer p.Unlock()
+	return p.count
+}
+
+func (p *reloadTestProcessor) SetState(state interface{}) error {
+	count, ok := state.(int)
+	if !ok {
+		return errors.New("invalid state type")
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.count = count
+	return nil
+}
+
+type reloadTestOutput struct {
+	connected atomic.Bool
+	closed    atomic.Bool
+}
+
+func (*reloadTestOutput) SampleConfig() string {
+	return ""
+}
+
+func (o *reloadTestOutput) Connect() error {
+	o.connected.Store(true)
+	return nil
+}
+
+func (o *reloadTestOutput) Close() error {
+	o.closed.Store(true)
+	return nil
+}
+
+func (*reloadTestOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func newReloadTestConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(time.Hour)
+	cfg.Agent.FlushInterval = config.Duration(time.Hour)
+	return cfg
+}
+
+func newReloadTestInput(id string) *models.RunningInput {
+	return models.NewRunningInput(&reloadTestInput{}, &models.InputConfig{Name: "test", ID: id})
+}
+
+func newReloadTestOutput(id string) (*models.RunningOutput, *reloadTestOutput) {
+	plugin := &reloadTestOutput{}
+	return models.NewRunningOutput(plugin, &models.OutputConfig{Name: "test", ID: id}, 10, 100), plugin
+}
+
+func newReloadTestProcessor(id string) (*models.RunningProcessor, *reloadTestProcessor) {
+	plugin := &reloadTestProcessor{}
+	processor := processors.NewStreamingProcessorFromProcessor(plugin)
+	return models.NewRunningProcessor(processor, &models.ProcessorConfig{Name: "test", ID: id}), plugin
+}
+
+// runReloadTestAgent runs an agent for the given config and waits until all
+// units are running.
+func runReloadTestAgent(t *testing.T, cfg *config.Config) *Agent {
+	t.Helper()
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		a.reloadLock.Lock()
+		defer a.reloadLock.Unlock()
+		if a.inputs == nil || a.outputs == nil {
+			return false
+		}
+
+		a.inputs.Lock()
+		defer a.inputs.Unlock()
+		a.outputs.Lock()
+		defer a.outputs.Unlock()
+		return a.inputs.running != nil && a.outputs.running != nil
+	}, time.Second, 10*time.Millisecond)
+
+	return a
+}
+
+func TestReloadDiffPlugins(t *testing.T) {
+	in1 := newReloadTestInput("in1")
+	in2 := newReloadTestInput("in2")
+	in3 := newReloadTestInput("in3")
+	in1Updated := newReloadTestInput("in1")
+	in4 := newReloadTestInput("in4")
+
+	merged, added, removed := diffPlugins(
+		[]*models.RunningInput{in1, in2, in3},
+		[]*models.RunningInput{in4, in1Updated, in3},
+	)
+	require.Equal(t, []*models.RunningInput{in4, in1, in3}, merged)
+	require.Equal(t, []*models.RunningInput{in4}, added)
+	require.Equal(t, []*models.RunningInput{in2}, removed)
+}
+
+func TestReloadKeepsUnchangedOutputs(t *testing.T) {
+	cfg := newReloadTestConfig()
+	cfg.Inputs = append(cfg.Inputs, newReloadTestInput("in1"), newReloadTestInput("in2"))
+	out1, plugin1 := newReloadTestOutput("out1")
+	out2, plugin2 := newReloadTestOutput("out2")
+	cfg.Outputs = append(cfg.Outputs, out1, out2)
+
+	a := runReloadTestAgent(t, cfg)
+	out1.AddMetric(testutil.TestMetric(1))
+
+	// Keep the first input and output, replace the others
+	updated := newReloadTestConfig()
+	in3 := newReloadTestInput("in3")
+	updated.Inputs = append(updated.Inputs, newReloadTestInput("in1"), in3)
+	out3, plugin3 := newReloadTestOutput("out3")
+	out1Updated, _ := newReloadTestOutput("out1")
+	updated.Outputs = append(updated.Outputs, out1Updated, out3)
+
+	require.NoError(t, a.Reload(context.Background(), updated))
+
+	require.Equal(t, []*models.RunningInput{cfg.Inputs[0], in3}, a.Config.Inputs)
+	require.Equal(t, []*models.RunningOutput{out1, out3}, a.Config.Outputs)
+	require.Equal(t, a.Config.Inputs, a.inputs.inputs)
+	require.Equal(t, a.Config.Outputs, a.outputs.outputs)
+
+	// The unchanged output must neither be reconnected nor lose its buffer
+	require.False(t, plugin1.closed.Load())
+	require.Equal(t, 1, out1.BufferLength())
+	require.True(t, plugin2.closed.Load())
+	require.True(t, plugin3.connected.Load())
+}
+
+func TestReloadProcessorsCarryState(t *testing.T) {
+	cfg := newReloadTestConfig()
+	input := newReloadTestInput("in1")
+	cfg.Inputs = append(cfg.Inputs, input)
+	processor, plugin := newReloadTestProcessor("proc1")
+	cfg.Processors = append(cfg.Processors, processor)
+	output, _ := newReloadTestOutput("out1")
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := runReloadTestAgent(t, cfg)
+
+	input.RequestGather()
+	require.Eventually(t, func() bool {
+		return output.BufferLength() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, plugin.GetState())
+
+	// Add a processor and keep the existing one
+	updated := newReloadTestConfig()
+	updated.Inputs = append(updated.Inputs, newReloadTestInput("in1"))
+	processorUpdated, pluginUpdated := newReloadTestProcessor("proc1")
+	processorAdded, pluginAdded := newReloadTestProcessor("proc2")
+	updated.Processors = append(updated.Processors, processorUpdated, processorAdded)
+	outputUpdated, _ := newReloadTestOutput("out1")
+	updated.Outputs = append(updated.Outputs, outputUpdated)
+
+	require.NoError(t, a.Reload(context.Background(), updated))
+	require.Equal(t, 1, pluginUpdated.GetState())
+
+	input.RequestGather()
+	require.Eventually(t, func() bool {
+		return output.BufferLength() == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, pluginUpdated.GetState())
+	require.Equal(t, 1, pluginAdded.GetState())
+	require.Equal(t, 1, plugin.GetState())
+}
+
+func TestReloadStatefulProcessorCheckpoint(t *testing.T) {
+	cfg := newReloadTestConfig()
+	cfg.Persister = &persister.Persister{Filename: filepath.Join(t.TempDir(), "state.json")}
+	cfg.Inputs = append(cfg.Inputs, newReloadTestInput("in1"))
+	output, _ := newReloadTestOutput("out1")
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := runReloadTestAgent(t, cfg)
+
+	// Add a stateful processor
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[processors.dedup]]
+  id = "dedup1"
+  dedup_interval = "1h"
+`), config.EmptySourcePath))
+	updated := newReloadTestConfig()
+	updated.Processors = c.Processors
+	updated.Inputs = append(updated.Inputs, newReloadTestInput("in1"))
+	outputUpdated, _ := newReloadTestOutput("out1")
+	updated.Outputs = append(updated.Outputs, outputUpdated)
+	require.NoError(t, a.Reload(context.Background(), updated))
+
+	// Store the state concurrently to the added processor filling its cache
+	// to trigger the race detector in case of unsynchronized access
+	processor := updated.Processors[0]
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		acc := &testutil.Accumulator{}
+		for i := range 1000 {
+			m := metric.New("test", map[string]string{"id": strconv.Itoa(i)}, map[string]interface{}{"value": i}, time.Now())
+			require.NoError(t, processor.Add(m, acc))
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			require.NoError(t, cfg.Persister.Store())
+			return
+		default:
+			require.NoError(t, cfg.Persister.Store())
+		}
+	}
+}
+
+func TestReloadFullReloadRequired(t *testing.T) {
+	cfg := newReloadTestConfig()
+	cfg.Inputs = append(cfg.Inputs, newReloadTestInput("in1"))
+	output, _ := newReloadTestOutput("out1")
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	a := runReloadTestAgent(t, cfg)
+
+	updated := newReloadTestConfig()
+	updated.Agent.Interval = config.Duration(time.Minute)
+	updated.Inputs = append(updated.Inputs, newReloadTestInput("in2"))
+	outputUpdated, _ := newReloadTestOutput("out1")
+	updated.Outputs = append(updated.Outputs, outputUpdated)
+
+	require.ErrorIs(t, a.Reload(context.Background(), updated), ErrFullReloadRequired)
+	require.Equal(t, cfg.Inputs, a.Config.Inputs)
+}
+
+func TestReloadRouteProcessors(t *testing.T) {
+	newConfig := func(billingProcessor string) (*config.Config, *reloadTestProcessor, *reloadTestProcessor) {
+		cfg := newReloadTestConfig()
+		input := newReloadTestInput("in1")
+		input.Config.Route = "billing"
+		cfg.Inputs = append(cfg.Inputs, input)
+		processor, plugin := newReloadTestProcessor("proc1")
+		routed, routedPlugin := newReloadTestProcessor(billingProcessor)
+		routed.Config.Route = "billing"
+		cfg.Processors = append(cfg.Processors, processor, routed)
+		output, _ := newReloadTestOutput("out1")
+		output.Config.Routes = []string{"billing"}
+		cfg.Outputs = append(cfg.Outputs, output)
+		return cfg, plugin, routedPlugin
+	}
+
+	cfg, plugin, routedPlugin := newConfig("proc2")
+	input, processor, output := cfg.Inputs[0], cfg.Processors[0], cfg.Outputs[0]
+	a := runReloadTestAgent(t, cfg)
+
+	input.RequestGather()
+	require.Eventually(t, func() bool {
+		return output.BufferLength() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, plugin.GetState())
+	require.Equal(t, 1, routedPlugin.GetState())
+
+	// Replace the processor of the route and keep the unrouted processor
+	updated, _, routedUpdated := newConfig("proc3")
+	require.NoError(t, a.Reload(context.Background(), updated))
+	require.Same(t, processor, a.Config.Processors[0])
+
+	input.RequestGather()
+	require.Eventually(t, func() bool {
+		return output.BufferLength() == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, plugin.GetState())
+	require.Equal(t, 1, routedPlugin.GetState())
+	require.Equal(t, 1, routedUpdated.GetState())
+
+	// Adding a route requires a restart
+	updated, _, _ = newConfig("proc3")
+	updated.Inputs[0].Config.Route = "audit"
+	require.ErrorIs(t, a.Reload(context.Background(), updated), ErrFullReloadRequired)
+}
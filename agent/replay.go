@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Replay feeds the given metrics through the processors and aggregators and
+// writes the result to the outputs. If w is not nil, the result is written
+// to w in line-protocol format instead and the outputs are not used.
+//
+// The metrics are replayed in the order of their timestamp and the time is
+// simulated, i.e. the aggregation periods are determined by the metric
+// timestamps instead of the wall-clock. Inputs are not used and the metrics
+// do not belong to any route, so outputs with routes are rejected.
+func (a *Agent) Replay(ctx context.Context, metrics []telegraf.Metric, w io.Writer) error {
+	a.Config.Inputs = nil
+
+	if w == nil {
+		for _, output := range a.Config.Outputs {
+			if len(output.Config.Routes) > 0 {
+				return fmt.Errorf("output %s uses routes which are not supported when replaying metrics", output.LogName())
+			}
+		}
+
+		if err := a.runReplay(ctx, metrics, nil); err != nil {
+			return err
+		}
+
+		unsent := 0
+		for _, output := range a.Config.Outputs {
+			unsent += output.BufferLength()
+		}
+		if unsent != 0 {
+			return fmt.Errorf("output plugins unable to send %d metrics", unsent)
+		}
+		return nil
+	}
+
+	a.Config.Outputs = nil
+	src := make(chan telegraf.Metric, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var writeErr error
+	go func() {
+		defer wg.Done()
+		s := &influx.Serializer{SortFields: true, UintSupport: true}
+		for metric := range src {
+			octets, err := s.Serialize(metric)
+			if err == nil && writeErr == nil {
+				_, writeErr = w.Write(octets)
+			}
+			metric.Accept()
+		}
+	}()
+
+	if err := a.runReplay(ctx, metrics, src); err != nil {
+		// The channel is only closed after replaying all metrics, so stop the
+		// writer here to not leak it
+		close(src)
+		wg.Wait()
+		return err
+	}
+	wg.Wait()
+
+	return writeErr
+}
+
+// runReplay runs the processors and aggregators for the given metrics. The
+// result is sent to the outputC or to the outputs if outputC is nil.
+func (a *Agent) runReplay(ctx context.Context, metrics []telegraf.Metric, outputC chan<- telegraf.Metric) error {
+	// Set the default for processor skipping
+	if a.Config.Agent.SkipProcessorsAfterAggregators == nil {
+		msg := `The default value of 'skip_processors_after_aggregators' will change to 'true' with Telegraf v1.40.0! `
+		msg += `If you need the current default behavior, please explicitly set the option to 'false'!`
+		log.Print("W! [agent] ", color.YellowString(msg))
+		skipProcessorsAfterAggregators := false
+		a.Config.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+	}
+
+	log.Printf("D! [agent] Initializing plugins")
+	if err := a.InitPlugins(); err != nil {
+		return err
+	}
+
+	next := outputC
+	var ou *outputUnit
+	if outputC == nil {
+		log.Printf("D! [agent] Connecting outputs")
+		var err error
+		next, ou, err = a.startOutputs(ctx, a.Config.Outputs)
+		if err != nil {
+			return err
+		}
+	}
+
+	var apu []*processorUnit
+	var au *aggregatorUnit
+	if len(a.Config.Aggregators) != 0 {
+		procC := next
+		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+			var err error
+			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
+			if err != nil {
+				return err
+			}
+		}
+
+		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
+	}
+
+	var pu []*processorUnit
+	if processors := routeProcessors(a.Config.Processors, ""); len(processors) != 0 {
+		var err error
+		next, pu, err = a.startProcessors(next, processors)
+		if err != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	if ou != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runOutputs(ou)
+		}()
+	}
+
+	if au != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(apu)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.replayAggregators(au)
+		}()
+	}
+
+	if pu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(pu)
+		}()
+	}
+
+	// Feed the metrics in the order of their timestamps
+	sorted := make([]telegraf.Metric, len(metrics))
+	copy(sorted, metrics)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time().Before(sorted[j].Time()) })
+	log.Printf("D! [agent] Replaying %d metrics", len(sorted))
+	for _, m := range sorted {
+		next <- m
+	}
+	close(next)
+
+	wg.Wait()
+
+	log.Printf("D! [agent] Stopped Successfully")
+
+	return nil
+}
+
+// replayAggregators aggregates the metrics until the source channel is closed
+// using the metric timestamps as time. An aggregation period is pushed as soon
+// as a metric after the period arrives and the pushed metrics are timestamped
+// with the end of the period.
+func (a *Agent) replayAggregators(unit *aggregatorUnit) {
+	interval := time.Duration(a.Config.Agent.Interval)
+	precision := getPrecision(time.Duration(a.Config.Agent.Precision), interval)
+
+	accs := make([]*accumulator, 0, len(a.Config.Aggregators))
+	for _, agg := range a.Config.Aggregators {
+		accs = append(accs, &accumulator{
+			maker:     agg,
+			metrics:   unit.aggC,
+			precision: precision,
+		})
+	}
+
+	started := false
+	for metric := range unit.src {
+		for i, agg := range a.Config.Aggregators {
+			if !started {
+				since, until := updateWindow(metric.Time(), a.Config.Agent.RoundInterval, agg.Period())
+				agg.UpdateWindow(since, until)
+			} else if !metric.Time().Before(agg.EndPeriod()) {
+				replayPush(agg, accs[i], metric.Time(), a.Config.Agent.RoundInterval)
+			}
+		}
+		started = true
+
+		var dropOriginal bool
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(metric); ok {
+				dropOriginal = true
+			}
+		}
+
+		if !dropOriginal {
+			unit.outputC <- metric // keep original.
+		} else {
+			metric.Drop()
+		}
+	}
+
+	// Push the last period
+	if started {
+		for i, agg := range a.Config.Aggregators {
+			replayPush(agg, accs[i], agg.EndPeriod(), a.Config.Agent.RoundInterval)
+		}
+	}
+
+	close(unit.aggC)
+	log.Printf("D! [agent] Aggregator channel closed")
+}
+
+// replayPush pushes the current aggregation period and moves the window to the
+// period containing the given time.
+func replayPush(agg *models.RunningAggregator, acc *accumulator, t time.Time, roundInterval bool) {
+	end := agg.EndPeriod()
+	acc.now = func() time.Time { return end }
+	agg.Push(acc)
+
+	// Push moves the window based on the wall-clock, so override it
+	since, until := end, end.Add(agg.Period())
+	if !t.Before(until) {
+		since, until = updateWindow(t, roundInterval, agg.Period())
+	}
+	agg.UpdateWindow(since, until)
+}
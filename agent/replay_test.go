@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestReplay(t *testing.T) {
+	cfg := `
+[agent]
+  round_interval = true
+  skip_processors_after_aggregators = true
+
+[[processors.override]]
+  [processors.override.tags]
+    replayed = "true"
+
+[[aggregators.minmax]]
+  period = "10s"
+  drop_original = true
+  namepass = ["cpu"]
+
+[[outputs.discard]]
+`
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+
+	// Metrics are intentionally not in chronological order
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(1700000012, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(1700000005, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(1700000007, 0)),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewAgent(c).Replay(t.Context(), metrics, &buf))
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	actual, err := parser.Parse(buf.Bytes())
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(1700000007, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value_min": float64(1), "value_max": float64(2)},
+			time.Unix(1700000010, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value_min": float64(3), "value_max": float64(3)},
+			time.Unix(1700000020, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
+
+func TestReplayOutputs(t *testing.T) {
+	cfg := `
+[agent]
+  skip_processors_after_aggregators = true
+
+[[processors.override]]
+  name_override = "replayed"
+`
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	output := &replayTestOutput{}
+	c.Outputs = append(c.Outputs, models.NewRunningOutput(output, &models.OutputConfig{Name: "test"}, 10, 100))
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(1700000005, 0)),
+	}
+	require.NoError(t, NewAgent(c).Replay(t.Context(), metrics, nil))
+
+	expected := []telegraf.Metric{
+		metric.New("replayed", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+		metric.New("replayed", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(1700000005, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, output.metrics)
+}
+
+func TestReplayRoutedOutput(t *testing.T) {
+	c := config.NewConfig()
+	output := &replayTestOutput{}
+	c.Outputs = append(c.Outputs, models.NewRunningOutput(output, &models.OutputConfig{Name: "test", Routes: []string{"billing"}}, 10, 100))
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+	}
+	require.ErrorContains(t, NewAgent(c).Replay(t.Context(), metrics, nil), "uses routes")
+	require.Empty(t, output.metrics)
+}
+
+func TestReplayError(t *testing.T) {
+	c := config.NewConfig()
+	processor := processors.NewStreamingProcessorFromProcessor(&replayFailingProcessor{})
+	c.Processors = append(c.Processors, models.NewRunningProcessor(processor, &models.ProcessorConfig{Name: "failing"}))
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1700000000, 0)),
+	}
+
+	var buf bytes.Buffer
+	require.ErrorContains(t, NewAgent(c).Replay(t.Context(), metrics, &buf), "init failed")
+	require.Empty(t, buf.Bytes())
+
+	// The writer must not be left running after an error
+	stacks := make([]byte, 1<<20)
+	stacks = stacks[:runtime.Stack(stacks, true)]
+	require.NotContains(t, string(stacks), "(*Agent).Replay.func")
+}
+
+type replayFailingProcessor struct{}
+
+func (*replayFailingProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*replayFailingProcessor) Init() error {
+	return errors.New("init failed")
+}
+
+func (*replayFailingProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	return in
+}
+
+type replayTestOutput struct {
+	metrics []telegraf.Metric
+	sync.Mutex
+}
+
+func (*replayTestOutput) SampleConfig() string {
+	return ""
+}
+
+func (*replayTestOutput) Connect() error {
+	return nil
+}
+
+func (*replayTestOutput) Close() error {
+	return nil
+}
+
+func (o *replayTestOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
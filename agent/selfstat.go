@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	cryptotls "crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// selfstatServer serves the internal statistics of the agent in Prometheus
+// exposition format independent of the output pipeline.
+type selfstatServer struct {
+	server     *http.Server
+	listener   net.Listener
+	serializer *prometheus.Serializer
+	done       chan struct{}
+}
+
+// newSelfstatServer creates a server listening on the given address.
+func newSelfstatServer(address string) (*selfstatServer, error) {
+	serializer := &prometheus.Serializer{
+		FormatConfig: prometheus.FormatConfig{SortMetrics: true},
+	}
+	if err := serializer.Init(); err != nil {
+		return nil, err
+	}
+
+	listener, err := listen(address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &selfstatServer{
+		listener:   listener,
+		serializer: serializer,
+		done:       make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", s.metrics)
+
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+func (s *selfstatServer) start() {
+	log.Printf("I! [agent] Serving internal statistics on %s", s.listener.Addr())
+	go func() {
+		defer close(s.done)
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving internal statistics failed: %v", err)
+		}
+	}()
+}
+
+func (s *selfstatServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping internal statistics server failed: %v", err)
+	}
+	<-s.done
+}
+
+func (s *selfstatServer) metrics(w http.ResponseWriter, _ *http.Request) {
+	buf, err := s.serializer.SerializeBatch(selfstat.Snapshot())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write(buf); err != nil {
+		log.Printf("E! [agent] Writing internal statistics failed: %v", err)
+	}
+}
+
+// selfstatPusher periodically pushes the internal statistics of the agent to
+// an OpenTelemetry collector via gRPC independent of the output pipeline.
+type selfstatPusher struct {
+	endpoint  string
+	interval  time.Duration
+	conn      *grpc.ClientConn
+	client    pmetricotlp.GRPCClient
+	converter *influx2otel.LineProtocolToOtelMetrics
+}
+
+// newSelfstatPusher creates a pusher for the given collector endpoint. The
+// connection uses TLS unless it is explicitly disabled in the TLS settings.
+func newSelfstatPusher(endpoint string, interval time.Duration, tlsCfg *tls.ClientConfig) (*selfstatPusher, error) {
+	converter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlsCfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if tlsCfg.Enable == nil || *tlsCfg.Enable {
+		if tlsConfig == nil {
+			tlsConfig = &cryptotls.Config{}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &selfstatPusher{
+		endpoint:  endpoint,
+		interval:  interval,
+		conn:      conn,
+		client:    pmetricotlp.NewGRPCClient(conn),
+		converter: converter,
+	}, nil
+}
+
+// run pushes the statistics every interval until the context is done.
+func (p *selfstatPusher) run(ctx context.Context) {
+	defer p.conn.Close()
+
+	log.Printf("I! [agent] Pushing internal statistics to %s every %s", p.endpoint, p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.push(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("E! [agent] Pushing internal statistics failed: %v", err)
+			}
+		}
+	}
+}
+
+func (p *selfstatPusher) push(ctx context.Context) error {
+	batch := p.converter.NewBatch()
+	for _, m := range selfstat.Snapshot() {
+		if err := batch.AddPoint(m.Name(), m.Tags(), m.Fields(), m.Time(), common.InfluxMetricValueTypeGauge); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+	_, err := p.client.Export(ctx, pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics()))
+	return err
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type selfstatTestCollector struct {
+	pmetricotlp.UnimplementedGRPCServer
+	requests chan pmetricotlp.ExportRequest
+}
+
+func (c *selfstatTestCollector) Export(_ context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	c.requests <- req
+	return pmetricotlp.NewExportResponse(), nil
+}
+
+func TestSelfstatServer(t *testing.T) {
+	stat := selfstat.Register("selfstat_server_test", "requests", map[string]string{"plugin": "test"})
+	stat.Set(42)
+
+	s, err := newSelfstatServer("127.0.0.1:0")
+	require.NoError(t, err)
+	s.start()
+	defer s.stop()
+
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `internal_selfstat_server_test_requests{plugin="test"} 42`)
+}
+
+func TestSelfstatPusher(t *testing.T) {
+	stat := selfstat.Register("selfstat_pusher_test", "requests", map[string]string{"plugin": "test"})
+	stat.Set(42)
+
+	pki := testutil.NewPKI("../testutil/pki")
+	serverTLS, err := pki.TLSServerConfig().TLSConfig()
+	require.NoError(t, err)
+	disabled := false
+
+	tests := []struct {
+		name          string
+		serverOptions []grpc.ServerOption
+		client        *tls.ClientConfig
+	}{
+		{
+			name:          "tls",
+			serverOptions: []grpc.ServerOption{grpc.Creds(credentials.NewTLS(serverTLS))},
+			client:        pki.TLSClientConfig(),
+		},
+		{
+			name:   "insecure",
+			client: &tls.ClientConfig{Enable: &disabled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			collector := &selfstatTestCollector{requests: make(chan pmetricotlp.ExportRequest, 10)}
+			server := grpc.NewServer(tt.serverOptions...)
+			pmetricotlp.RegisterGRPCServer(server, collector)
+			go server.Serve(listener) //nolint:errcheck // test server stops on cleanup
+			defer server.Stop()
+
+			pusher, err := newSelfstatPusher(listener.Addr().String(), 10*time.Millisecond, tt.client)
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				pusher.run(ctx)
+			}()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			var req pmetricotlp.ExportRequest
+			select {
+			case req = <-collector.requests:
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "no statistics received")
+			}
+
+			found := false
+			resourceMetrics := req.Metrics().ResourceMetrics()
+			for i := range resourceMetrics.Len() {
+				scopeMetrics := resourceMetrics.At(i).ScopeMetrics()
+				for j := range scopeMetrics.Len() {
+					metrics := scopeMetrics.At(j).Metrics()
+					for k := range metrics.Len() {
+						m := metrics.At(k)
+						if m.Name() == "internal_selfstat_pusher_test_requests" {
+							found = true
+							require.Equal(t, int64(42), m.Gauge().DataPoints().At(0).IntValue())
+						}
+					}
+				}
+			}
+			require.True(t, found, "statistic not pushed")
+		})
+	}
+}
+
+func TestSelfstatServerKeepsTimings(t *testing.T) {
+	stat := selfstat.RegisterTiming("selfstat_timing_test", "duration_ns", map[string]string{"plugin": "test"})
+	stat.Incr(100)
+
+	s, err := newSelfstatServer("127.0.0.1:0")
+	require.NoError(t, err)
+	s.start()
+	defer s.stop()
+
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Serving the statistics must not reset the averages collected by the
+	// internal input
+	stat.Incr(200)
+	require.Equal(t, int64(150), stat.Get())
+}
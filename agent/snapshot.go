@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// TestCollect runs the inputs, processors and aggregators for a single gather
+// like Test but returns the metrics instead of writing them to stdout.
+func (a *Agent) TestCollect(ctx context.Context, wait time.Duration) ([]telegraf.Metric, error) {
+	var received []telegraf.Metric
+	var mu sync.Mutex
+
+	src := make(chan telegraf.Metric, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for m := range src {
+			mu.Lock()
+			received = append(received, m)
+			mu.Unlock()
+			m.Reject()
+		}
+	}()
+
+	if err := a.runTest(ctx, wait, src); err != nil {
+		return nil, err
+	}
+	wg.Wait()
+
+	if models.GlobalGatherErrors.Get() != 0 {
+		return received, fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	}
+	return received, nil
+}
+
+// WriteSnapshot writes the metrics in line-protocol format with sorted fields
+// to the given file.
+func WriteSnapshot(filename string, metrics []telegraf.Metric) error {
+	s := &serializers_influx.Serializer{SortFields: true, UintSupport: true}
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		octets, err := s.Serialize(m)
+		if err != nil {
+			return fmt.Errorf("serializing metric %q failed: %w", m.Name(), err)
+		}
+		buf.Write(octets)
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0640)
+}
+
+// ReadSnapshot reads the metrics in line-protocol format from the given file.
+func ReadSnapshot(filename string) ([]telegraf.Metric, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &influx.Parser{}
+	if err := parser.Init(); err != nil {
+		return nil, err
+	}
+	return parser.Parse(data)
+}
+
+// DiffSnapshot compares the metrics ignoring timestamps and the order of the
+// metrics, tags and fields. The returned diff contains one line per metric
+// prefixed with '-' for expected metrics missing in the actual metrics and
+// '+' for unexpected metrics, or is empty if the metrics are equal.
+func DiffSnapshot(expected, actual []telegraf.Metric) (string, error) {
+	expectedLines, err := snapshotLines(expected)
+	if err != nil {
+		return "", err
+	}
+	actualLines, err := snapshotLines(actual)
+	if err != nil {
+		return "", err
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(expectedLines) || j < len(actualLines) {
+		switch {
+		case j >= len(actualLines) || (i < len(expectedLines) && expectedLines[i] < actualLines[j]):
+			diff.WriteString("- " + expectedLines[i] + "\n")
+			i++
+		case i >= len(expectedLines) || expectedLines[i] > actualLines[j]:
+			diff.WriteString("+ " + actualLines[j] + "\n")
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return diff.String(), nil
+}
+
+// snapshotLines returns the sorted line-protocol representation of the metrics
+// without timestamps.
+func snapshotLines(metrics []telegraf.Metric) ([]string, error) {
+	s := &serializers_influx.Serializer{SortFields: true, UintSupport: true}
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		m = m.Copy()
+		m.SetTime(time.Unix(0, 0))
+		octets, err := s.Serialize(m)
+		if err != nil {
+			return nil, fmt.Errorf("serializing metric %q failed: %w", m.Name(), err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(octets))
+		for scanner.Scan() {
+			lines = append(lines, strings.TrimSuffix(scanner.Text(), " 0"))
+		}
+	}
+	sort.Strings(lines)
+	return lines, nil
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSnapshotRoundtrip(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_user": 42.5, "usage_system": 1.5},
+			time.Unix(1700000000, 0),
+		),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": uint64(1024)}, time.Unix(1700000000, 0)),
+	}
+
+	filename := filepath.Join(t.TempDir(), "snapshot.lp")
+	require.NoError(t, WriteSnapshot(filename, metrics))
+
+	actual, err := ReadSnapshot(filename)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, metrics, actual)
+}
+
+func TestDiffSnapshot(t *testing.T) {
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"a": 1, "b": 2}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": 10}, time.Unix(1700000000, 0)),
+	}
+
+	// Different timestamps and metric order must not be reported
+	actual := []telegraf.Metric{
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": 10}, time.Unix(1800000000, 0)),
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"b": 2, "a": 1}, time.Unix(1800000000, 0)),
+	}
+	diff, err := DiffSnapshot(expected, actual)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	// Changed and additional metrics must be reported
+	actual = []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"a": 1, "b": 3}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": 10}, time.Unix(1700000000, 0)),
+		metric.New("swap", map[string]string{}, map[string]interface{}{"free": 5}, time.Unix(1700000000, 0)),
+	}
+	diff, err = DiffSnapshot(expected, actual)
+	require.NoError(t, err)
+	require.Equal(t, "- cpu,cpu=cpu0 a=1i,b=2i\n+ cpu,cpu=cpu0 a=1i,b=3i\n+ swap free=5i\n", diff)
+}
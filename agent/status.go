@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// pluginSnapshot holds the plugins of the running agent. The snapshot is
+// replaced on reload to allow querying the plugins, e.g. via the API, without
+// accessing the configuration modified by a running reload.
+type pluginSnapshot struct {
+	interval    time.Duration
+	inputs      []*models.RunningInput
+	processors  []*models.RunningProcessor
+	aggregators []*models.RunningAggregator
+	outputs     []*models.RunningOutput
+}
+
+// publishPlugins updates the snapshot of the running plugins. The caller must
+// hold the reload lock.
+func (a *Agent) publishPlugins() {
+	a.plugins.Store(&pluginSnapshot{
+		interval:    time.Duration(a.Config.Agent.Interval),
+		inputs:      slices.Clone(a.Config.Inputs),
+		processors:  slices.Clone(a.Config.Processors),
+		aggregators: slices.Clone(a.Config.Aggregators),
+		outputs:     slices.Clone(a.Config.Outputs),
+	})
+}
+
+// runningPlugins returns the current plugins of the agent. The snapshot is
+// empty before the plugins were published when starting the agent.
+func (a *Agent) runningPlugins() *pluginSnapshot {
+	if snapshot := a.plugins.Load(); snapshot != nil {
+		return snapshot
+	}
+	return &pluginSnapshot{}
+}
+
+// agentStatus provides the status of the running plugins of the agent to
+// plugins implementing models.StatusConsumer.
+type agentStatus struct {
+	agent *Agent
+}
+
+func (s *agentStatus) InputStatus() []models.InputStatus {
+	plugins := s.agent.runningPlugins()
+
+	status := make([]models.InputStatus, 0, len(plugins.inputs))
+	for _, input := range plugins.inputs {
+		interval := plugins.interval
+		if input.Config.Interval != 0 {
+			interval = input.Config.Interval
+		}
+		status = append(status, models.InputStatus{
+			ID:         input.ID(),
+			Name:       input.Config.Name,
+			Alias:      input.Config.Alias,
+			Interval:   interval,
+			Paused:     input.Paused(),
+			LastGather: input.LastGather(),
+		})
+	}
+	return status
+}
+
+func (s *agentStatus) OutputStatus() []models.OutputStatus {
+	plugins := s.agent.runningPlugins()
+
+	status := make([]models.OutputStatus, 0, len(plugins.outputs))
+	for _, output := range plugins.outputs {
+		info := models.OutputStatus{
+			ID:                  output.ID(),
+			Name:                output.Config.Name,
+			Alias:               output.Config.Alias,
+			Paused:              output.Paused(),
+			BufferSize:          output.BufferLength(),
+			ConsecutiveFailures: output.ConsecutiveFailures(),
+		}
+		switch output.Config.BufferStrategy {
+		case "", "memory":
+			info.BufferLimit = output.MetricBufferLimit
+		}
+		info.BufferFill, info.BufferLimited = output.BufferFill()
+		status = append(status, info)
+	}
+	return status
+}
+
+// linkStatusProvider passes the status of the running plugins to the given
+// plugin if it implements models.StatusConsumer.
+func (a *Agent) linkStatusProvider(plugin interface{}) {
+	if consumer, ok := plugin.(models.StatusConsumer); ok {
+		consumer.SetStatusProvider(&agentStatus{agent: a})
+	}
+}
@@ -48,8 +48,23 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 		To check the file 'mysettings.conf' use
 
 		> telegraf config check --config mysettings.conf
+
+		Use '--format json' or '--format sarif' to collect all problems of the
+		configuration instead of stopping at the first error and to output a
+		machine-readable report. Besides errors, the report contains unknown and
+		deprecated options, filters that can never match, outputs no metric can
+		reach and processors sharing the same order. The command fails if the
+		report contains errors.
+
+		> telegraf config check --config-directory /etc/telegraf/telegraf.d --format sarif
 		`,
-					Flags: configHandlingFlags,
+					Flags: append(configHandlingFlags,
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "output format of the check result [text, json, sarif]",
+							Value: "text",
+						},
+					),
 					Action: func(cCtx *cli.Context) error {
 						// Setup logging
 						logConfig := &logger.Config{Debug: cCtx.Bool("debug")}
@@ -57,46 +72,39 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 							return err
 						}
 
-						// Collect the given configuration files
-						configFiles := cCtx.StringSlice("config")
-						configDir := cCtx.StringSlice("config-directory")
-						for _, fConfigDirectory := range configDir {
-							files, err := config.WalkDirectory(fConfigDirectory)
-							if err != nil {
-								return err
-							}
-							configFiles = append(configFiles, files...)
+						configFiles, err := collectConfigFiles(cCtx)
+						if err != nil {
+							return err
 						}
 
-						// If no "config" or "config-directory" flag(s) was
-						// provided we should load default configuration files
-						if len(configFiles) == 0 {
-							paths, err := config.GetDefaultConfigPath()
-							if err != nil {
-								return err
+						format := cCtx.String("format")
+						if format == "text" {
+							return checkConfig(configFiles, cCtx.Bool("quiet"))
+						}
+						if format != "json" && format != "sarif" {
+							return fmt.Errorf("invalid format %q", format)
+						}
+
+						// Collect all findings and only try to initialize
+						// the plugins if the configuration is loadable
+						findings := config.Lint(configFiles...)
+						if !hasLintErrors(findings) {
+							if err := checkConfig(configFiles, cCtx.Bool("quiet")); err != nil {
+								findings = append(findings, config.LintFinding{
+									Rule:     config.LintRuleInitFailed,
+									Severity: config.LintError,
+									Message:  err.Error(),
+								})
 							}
-							configFiles = paths
 						}
 
-						// Load the config and try to initialize the plugins
-						c := config.NewConfig()
-						c.Agent.Quiet = cCtx.Bool("quiet")
-						if err := c.LoadAll(configFiles...); err != nil {
+						if err := writeLintReport(outputBuffer, format, findings); err != nil {
 							return err
 						}
-
-						ag := agent.NewAgent(c)
-
-						// Set the default for processor skipping
-						if c.Agent.SkipProcessorsAfterAggregators == nil {
-							msg := `The default value of 'skip_processors_after_aggregators' will change to 'true' with Telegraf v1.40.0! `
-							msg += `If you need the current default behavior, please explicitly set the option to 'false'!`
-							log.Print("W! [agent] ", color.YellowString(msg))
-							skipProcessorsAfterAggregators := false
-							c.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+						if hasLintErrors(findings) {
+							return errors.New("configuration check found errors")
 						}
-
-						return ag.InitPlugins()
+						return nil
 					},
 				},
 				{
@@ -124,6 +132,62 @@ InfluxDB v2 output plugin use
 						return nil
 					},
 				},
+				{
+					Name:  "graph",
+					Usage: "show the metric pipeline of the configuration as graph",
+					Description: `
+The 'graph' command reads the configuration files specified via '--config' or
+'--config-directory' and prints the metric pipeline as graph. The graph shows
+the path of the metrics from the inputs via processors and aggregators to the
+outputs including routes. Edges are annotated with the metric filters applied
+on the path, processors with their order. If no configuration file is
+explicitly specified the command reads the default locations and uses those
+configuration files.
+
+To render the pipeline of the configuration directory '/etc/telegraf/telegraf.d'
+as image use
+
+> telegraf config graph --config-directory /etc/telegraf/telegraf.d | dot -Tsvg > pipeline.svg
+
+To output a Mermaid flowchart use
+
+> telegraf config graph --config mysettings.conf --format mermaid
+`,
+					Flags: append(configHandlingFlags,
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "output format of the graph [dot, mermaid]",
+							Value: "dot",
+						},
+					),
+					Action: func(cCtx *cli.Context) error {
+						// Setup logging
+						logConfig := &logger.Config{Debug: cCtx.Bool("debug")}
+						if err := logger.SetupLogging(logConfig); err != nil {
+							return err
+						}
+
+						configFiles, err := collectConfigFiles(cCtx)
+						if err != nil {
+							return err
+						}
+
+						c := config.NewConfig()
+						c.Agent.Quiet = cCtx.Bool("quiet")
+						if err := c.LoadAll(configFiles...); err != nil {
+							return err
+						}
+
+						switch format := cCtx.String("format"); format {
+						case "dot":
+							return c.Graph().WriteDOT(outputBuffer)
+						case "mermaid":
+							return c.Graph().WriteMermaid(outputBuffer)
+						default:
+							return fmt.Errorf("invalid format %q", format)
+						}
+					},
+				},
 				{
 					Name:  "migrate",
 					Usage: "migrate deprecated plugins and options of the configuration(s)",
@@ -170,25 +234,9 @@ To migrate the file 'mysettings.conf' use
 							migrationsGeneral, migrationsPlugins, migrationsOptions,
 						)
 
-						// Collect the given configuration files
-						configFiles := cCtx.StringSlice("config")
-						configDir := cCtx.StringSlice("config-directory")
-						for _, fConfigDirectory := range configDir {
-							files, err := config.WalkDirectory(fConfigDirectory)
-							if err != nil {
-								return err
-							}
-							configFiles = append(configFiles, files...)
-						}
-
-						// If no "config" or "config-directory" flag(s) was
-						// provided we should load default configuration files
-						if len(configFiles) == 0 {
-							paths, err := config.GetDefaultConfigPath()
-							if err != nil {
-								return err
-							}
-							configFiles = paths
+						configFiles, err := collectConfigFiles(cCtx)
+						if err != nil {
+							return err
 						}
 
 						for _, fn := range configFiles {
@@ -244,3 +292,48 @@ To migrate the file 'mysettings.conf' use
 		},
 	}
 }
+
+// collectConfigFiles returns the configuration files given via '--config' and
+// '--config-directory' or the default configuration files if none is given.
+func collectConfigFiles(cCtx *cli.Context) ([]string, error) {
+	configFiles := cCtx.StringSlice("config")
+	configDir := cCtx.StringSlice("config-directory")
+	allFormats := cCtx.Bool("config-directory-all-formats")
+	for _, fConfigDirectory := range configDir {
+		files, err := config.WalkDirectory(fConfigDirectory, allFormats)
+		if err != nil {
+			return nil, err
+		}
+		configFiles = append(configFiles, files...)
+	}
+
+	// If no "config" or "config-directory" flag(s) was
+	// provided we should load default configuration files
+	if len(configFiles) == 0 {
+		return config.GetDefaultConfigPath(allFormats)
+	}
+	return configFiles, nil
+}
+
+// checkConfig loads the given configuration files and tries to initialize,
+// but not start, the plugins.
+func checkConfig(configFiles []string, quiet bool) error {
+	c := config.NewConfig()
+	c.Agent.Quiet = quiet
+	if err := c.LoadAll(configFiles...); err != nil {
+		return err
+	}
+
+	ag := agent.NewAgent(c)
+
+	// Set the default for processor skipping
+	if c.Agent.SkipProcessorsAfterAggregators == nil {
+		msg := `The default value of 'skip_processors_after_aggregators' will change to 'true' with Telegraf v1.40.0! `
+		msg += `If you need the current default behavior, please explicitly set the option to 'false'!`
+		log.Print("W! [agent] ", color.YellowString(msg))
+		skipProcessorsAfterAggregators := false
+		c.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+	}
+
+	return ag.InitPlugins()
+}
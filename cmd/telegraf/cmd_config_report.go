@@ -0,0 +1,173 @@
+// Report handling for the "config check" command
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+type lintReport struct {
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+	Findings []config.LintFinding `json:"findings"`
+}
+
+func newLintReport(findings []config.LintFinding) *lintReport {
+	report := &lintReport{Findings: findings}
+	if report.Findings == nil {
+		report.Findings = make([]config.LintFinding, 0)
+	}
+	for _, f := range findings {
+		switch f.Severity {
+		case config.LintError:
+			report.Errors++
+		case config.LintWarning:
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+func hasLintErrors(findings []config.LintFinding) bool {
+	for _, f := range findings {
+		if f.Severity == config.LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// SARIF v2.1.0 structures, see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func newSarifLog(findings []config.LintFinding) *sarifLog {
+	ids := make([]string, 0, len(config.LintRules))
+	for id := range config.LintRules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rules := make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: config.LintRules[id]}})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:  f.Rule,
+			Level:   f.Severity,
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.File != "" {
+			loc := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.File},
+				},
+			}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+			}
+			result.Locations = []sarifLocation{loc}
+		}
+		if f.Plugin != "" || f.PluginID != "" {
+			result.Properties = map[string]string{"plugin": f.Plugin, "plugin_id": f.PluginID}
+		}
+		results = append(results, result)
+	}
+
+	return &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "telegraf",
+						Version:        internal.Version,
+						InformationURI: "https://github.com/influxdata/telegraf",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+// writeLintReport writes the findings in the given format to the writer.
+func writeLintReport(w io.Writer, format string, findings []config.LintFinding) error {
+	var report interface{}
+	switch format {
+	case "json":
+		report = newLintReport(findings)
+	case "sarif":
+		report = newSarifLog(findings)
+	default:
+		return fmt.Errorf("invalid report format %q", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
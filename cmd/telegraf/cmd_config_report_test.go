@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+var lintFindings = []config.LintFinding{
+	{
+		Rule:     config.LintRuleUnknownOption,
+		Severity: config.LintError,
+		Message:  "unknown field",
+		File:     "telegraf.conf",
+		Line:     3,
+		Plugin:   "inputs.file",
+		PluginID: "abc",
+	},
+	{
+		Rule:     config.LintRuleInitFailed,
+		Severity: config.LintWarning,
+		Message:  "init failed",
+	},
+}
+
+func TestWriteLintReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeLintReport(&buf, "json", lintFindings))
+
+	var report lintReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	require.Equal(t, 1, report.Errors)
+	require.Equal(t, 1, report.Warnings)
+	require.Equal(t, lintFindings, report.Findings)
+}
+
+func TestWriteLintReportSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeLintReport(&buf, "sarif", lintFindings))
+
+	var report sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	require.Equal(t, "2.1.0", report.Version)
+	require.Len(t, report.Runs, 1)
+	require.Len(t, report.Runs[0].Tool.Driver.Rules, len(config.LintRules))
+
+	expected := []sarifResult{
+		{
+			RuleID:  config.LintRuleUnknownOption,
+			Level:   "error",
+			Message: sarifMessage{Text: "unknown field"},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "telegraf.conf"},
+						Region:           &sarifRegion{StartLine: 3},
+					},
+				},
+			},
+			Properties: map[string]string{"plugin": "inputs.file", "plugin_id": "abc"},
+		},
+		{
+			RuleID:  config.LintRuleInitFailed,
+			Level:   "warning",
+			Message: sarifMessage{Text: "init failed"},
+		},
+	}
+	require.Equal(t, expected, report.Runs[0].Results)
+}
+
+func TestWriteLintReportInvalidFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.ErrorContains(t, writeLintReport(&buf, "xml", lintFindings), "invalid report format")
+}
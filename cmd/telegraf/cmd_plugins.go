@@ -2,6 +2,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -60,6 +63,43 @@ func getPluginCommands(outputBuffer io.Writer) []*cli.Command {
 				return nil
 			},
 			Subcommands: []*cli.Command{
+				{
+					Name:      "schema",
+					Usage:     "Print the JSON Schema of a plugin configuration",
+					ArgsUsage: "[<category> <name>]",
+					Description: `
+The 'schema' command prints the JSON Schema describing the configuration options
+of the given plugin including their types and defaults. Without arguments, a
+bundle with the schemas of the agent settings and all plugins is printed.
+
+To print the schema of the 'cpu' input plugin use
+
+> telegraf plugins schema inputs cpu
+
+Valid categories are 'inputs', 'outputs', 'processors', 'aggregators',
+'secretstores', 'parsers' and 'serializers'.
+`,
+					Action: func(cCtx *cli.Context) error {
+						var schema *config.Schema
+						var err error
+						switch cCtx.NArg() {
+						case 0:
+							schema, err = config.SchemaBundle()
+						case 2:
+							schema, err = config.PluginSchema(cCtx.Args().Get(0), cCtx.Args().Get(1))
+						default:
+							return errors.New("expected plugin category and name or no arguments")
+						}
+						if err != nil {
+							return err
+						}
+
+						encoder := json.NewEncoder(outputBuffer)
+						encoder.SetIndent("", "  ")
+						encoder.SetEscapeHTML(false)
+						return encoder.Encode(schema)
+					},
+				},
 				{
 					Name:  "inputs",
 					Usage: "Print available input plugins",
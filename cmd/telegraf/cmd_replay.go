@@ -0,0 +1,124 @@
+// Command handling for the "replay" command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+func getReplayCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "replay",
+			Usage: "feed recorded metrics through the processors, aggregators and outputs",
+			Description: `
+The 'replay' command reads the configuration files specified via '--config' or
+'--config-directory' and feeds the metrics parsed from the given input file
+through the configured processors and aggregators to the configured outputs.
+The inputs of the configuration are not used. The metrics are replayed in
+the order of their timestamps and aggregation periods are determined by the
+metric timestamps instead of the current time.
+
+The input file can be in any data format supported by Telegraf's parsers using
+the parser's default settings. The file name is used as metric name for
+formats without a name.
+
+To replay the line-protocol file 'metrics.lp' to the configured outputs use
+
+> telegraf replay --config telegraf.conf --input-file metrics.lp --format influx
+
+To print the result instead of writing to the outputs use
+
+> telegraf replay --config telegraf.conf --input-file metrics.lp --stdout
+`,
+			Flags: append(configHandlingFlags,
+				&cli.StringFlag{
+					Name:     "input-file",
+					Usage:    "file containing the metrics to replay",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "data format of the input file",
+					Value: "influx",
+				},
+				&cli.BoolFlag{
+					Name:  "stdout",
+					Usage: "print the resulting metrics in line-protocol format instead of writing to the outputs",
+				},
+			),
+			Action: func(cCtx *cli.Context) error {
+				// Setup logging
+				logConfig := &logger.Config{Debug: cCtx.Bool("debug"), Quiet: cCtx.Bool("quiet")}
+				if err := logger.SetupLogging(logConfig); err != nil {
+					return err
+				}
+
+				metrics, err := parseReplayFile(cCtx.String("input-file"), cCtx.String("format"))
+				if err != nil {
+					return err
+				}
+
+				configFiles, err := collectConfigFiles(cCtx)
+				if err != nil {
+					return err
+				}
+
+				c := config.NewConfig()
+				c.Agent.Quiet = cCtx.Bool("quiet")
+				if err := c.LoadAll(configFiles...); err != nil {
+					return err
+				}
+
+				ag := agent.NewAgent(c)
+				if cCtx.Bool("stdout") {
+					return ag.Replay(cCtx.Context, metrics, outputBuffer)
+				}
+				if len(c.Outputs) == 0 {
+					return errors.New("no outputs found, use '--stdout' to print the metrics")
+				}
+				return ag.Replay(cCtx.Context, metrics, nil)
+			},
+		},
+	}
+}
+
+// parseReplayFile parses the metrics of the given file with the default
+// settings of the parser for the given data format.
+func parseReplayFile(filename, format string) ([]telegraf.Metric, error) {
+	creator, found := parsers.Parsers[format]
+	if !found {
+		return nil, fmt.Errorf("unknown data format %q", format)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	parser := creator(name)
+	if p, ok := parser.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return nil, fmt.Errorf("initializing %q parser failed: %w", format, err)
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := parser.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q failed: %w", filename, err)
+	}
+	return metrics, nil
+}
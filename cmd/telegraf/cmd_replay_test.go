@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	_ "github.com/influxdata/telegraf/plugins/parsers/influx"
+	_ "github.com/influxdata/telegraf/plugins/parsers/value"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseReplayFile(t *testing.T) {
+	dir := t.TempDir()
+
+	lp := filepath.Join(dir, "metrics.lp")
+	require.NoError(t, os.WriteFile(lp, []byte("cpu value=42i 1700000000000000000\n"), 0600))
+	actual, err := parseReplayFile(lp, "influx")
+	require.NoError(t, err)
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(42)}, time.Unix(1700000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Formats without a metric name use the file name
+	values := filepath.Join(dir, "temperature.txt")
+	require.NoError(t, os.WriteFile(values, []byte("23\n"), 0600))
+	actual, err = parseReplayFile(values, "value")
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "temperature", actual[0].Name())
+
+	_, err = parseReplayFile(lp, "unknown")
+	require.ErrorContains(t, err, `unknown data format "unknown"`)
+}
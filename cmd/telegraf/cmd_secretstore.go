@@ -2,6 +2,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/awnumar/memguard"
+	jose "github.com/dvsekhvalnov/jose2go"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/term"
 )
@@ -37,7 +39,7 @@ func getSecretStoreCommands(m App) []*cli.Command {
 	return []*cli.Command{
 		{
 			Name:  "secrets",
-			Usage: "commands for listing, adding and removing secrets on all known secret-stores",
+			Usage: "commands for listing, adding, removing, exporting and importing secrets on all known secret-stores",
 			Subcommands: []*cli.Command{
 				{
 					Name:  "list",
@@ -75,11 +77,12 @@ To also reveal the actual secret, i.e. the value, you can pass the
 						// Only load the secret-stores
 						filters := processFilterOnlySecretStoreFlags(cCtx)
 						g := GlobalFlags{
-							config:     cCtx.StringSlice("config"),
-							configDir:  cCtx.StringSlice("config-directory"),
-							plugindDir: cCtx.String("plugin-directory"),
-							password:   cCtx.String("password"),
-							debug:      cCtx.Bool("debug"),
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
 						}
 						w := WindowFlags{}
 						m.Init(nil, filters, g, w)
@@ -153,11 +156,12 @@ with the ID 'mystore'.
 						// Only load the secret-stores
 						filters := processFilterOnlySecretStoreFlags(cCtx)
 						g := GlobalFlags{
-							config:     cCtx.StringSlice("config"),
-							configDir:  cCtx.StringSlice("config-directory"),
-							plugindDir: cCtx.String("plugin-directory"),
-							password:   cCtx.String("password"),
-							debug:      cCtx.Bool("debug"),
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
 						}
 						w := WindowFlags{}
 						m.Init(nil, filters, g, w)
@@ -217,11 +221,12 @@ you will be prompted to enter the value of the secret.
 						// Only load the secret-stores
 						filters := processFilterOnlySecretStoreFlags(cCtx)
 						g := GlobalFlags{
-							config:     cCtx.StringSlice("config"),
-							configDir:  cCtx.StringSlice("config-directory"),
-							plugindDir: cCtx.String("plugin-directory"),
-							password:   cCtx.String("password"),
-							debug:      cCtx.Bool("debug"),
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
 						}
 						w := WindowFlags{}
 						m.Init(nil, filters, g, w)
@@ -252,6 +257,220 @@ you will be prompted to enter the value of the secret.
 							return fmt.Errorf("unable to set secret: %w", err)
 						}
 
+						return nil
+					},
+				},
+				{
+					Name:  "delete",
+					Usage: "remove a secret from the given store",
+					Description: `
+The 'delete' command requires passing in your configuration file
+containing the secret-store definitions you want to access. To get a
+list of available secret-store plugins, please have a look at
+https://github.com/influxdata/telegraf/tree/master/plugins/secretstores.
+and use the 'secrets list' command to get the IDs of available stores and keys.
+
+Assuming you use the default configuration file location, you can run
+the following command to remove a secret from an available secret-store
+
+> telegraf secrets delete mystore mysecretkey
+
+This will remove the secret with the key 'mysecretkey' from the secret-store
+with the ID 'mystore'.
+`,
+					ArgsUsage: "<secret-store ID> <secret key>",
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						args := cCtx.Args()
+						if !args.Present() || args.Len() != 2 {
+							return errors.New("invalid number of arguments")
+						}
+
+						storeID := args.First()
+						key := args.Get(1)
+
+						store, err := m.GetSecretStore(storeID)
+						if err != nil {
+							return fmt.Errorf("unable to get secret-store: %w", err)
+						}
+						if err := store.Delete(key); err != nil {
+							return fmt.Errorf("unable to delete secret: %w", err)
+						}
+
+						return nil
+					},
+				},
+				{
+					Name:  "export",
+					Usage: "export all secrets of the given store to an encrypted bundle",
+					Description: `
+The 'export' command requires passing in your configuration file
+containing the secret-store definitions you want to access. All secrets
+of the given store are written to the given file as a bundle encrypted
+with a password. The bundle can be imported into another store using the
+'secrets import' command, e.g. to migrate from one secret-store to another.
+
+Assuming you use the default configuration file location, you can run
+
+> telegraf secrets export mystore secrets.jwe
+
+to export all secrets of the store with the ID 'mystore'. You will be
+prompted for the bundle password unless you pass the '--bundle-password'
+flag.
+`,
+					ArgsUsage: "<secret-store ID> <bundle file>",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "bundle-password",
+							Usage: "password for encrypting the bundle",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						args := cCtx.Args()
+						if !args.Present() || args.Len() != 2 {
+							return errors.New("invalid number of arguments")
+						}
+
+						storeID := args.First()
+						filename := args.Get(1)
+
+						store, err := m.GetSecretStore(storeID)
+						if err != nil {
+							return fmt.Errorf("unable to get secret-store: %w", err)
+						}
+						keys, err := store.List()
+						if err != nil {
+							return fmt.Errorf("unable to get secrets from store %q: %w", storeID, err)
+						}
+
+						bundle := make(map[string][]byte, len(keys))
+						defer func() {
+							for _, v := range bundle {
+								memguard.WipeBytes(v)
+							}
+						}()
+						for _, k := range keys {
+							v, err := store.Get(k)
+							if err != nil {
+								return fmt.Errorf("unable to get value of secret %q from store %q: %w", k, storeID, err)
+							}
+							bundle[k] = v
+						}
+
+						password, err := bundlePassword(cCtx)
+						if err != nil {
+							return err
+						}
+						if err := writeSecretBundle(filename, password, bundle); err != nil {
+							return fmt.Errorf("unable to write bundle: %w", err)
+						}
+						fmt.Printf("Exported %d secrets from store %q\n", len(bundle), storeID)
+
+						return nil
+					},
+				},
+				{
+					Name:  "import",
+					Usage: "import all secrets of an encrypted bundle into the given store",
+					Description: `
+The 'import' command requires passing in your configuration file
+containing the secret-store definitions you want to access. All secrets
+contained in the bundle created by the 'secrets export' command are set
+in the given store. Existing secrets with the same key are overwritten.
+
+Assuming you use the default configuration file location, you can run
+
+> telegraf secrets import mystore secrets.jwe
+
+to import all secrets of the bundle into the store with the ID 'mystore'.
+You will be prompted for the bundle password unless you pass the
+'--bundle-password' flag.
+`,
+					ArgsUsage: "<secret-store ID> <bundle file>",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "bundle-password",
+							Usage: "password for decrypting the bundle",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:              cCtx.StringSlice("config"),
+							configDir:           cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
+							plugindDir:          cCtx.String("plugin-directory"),
+							password:            cCtx.String("password"),
+							debug:               cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						args := cCtx.Args()
+						if !args.Present() || args.Len() != 2 {
+							return errors.New("invalid number of arguments")
+						}
+
+						storeID := args.First()
+						filename := args.Get(1)
+
+						store, err := m.GetSecretStore(storeID)
+						if err != nil {
+							return fmt.Errorf("unable to get secret-store: %w", err)
+						}
+
+						password, err := bundlePassword(cCtx)
+						if err != nil {
+							return err
+						}
+						bundle, err := readSecretBundle(filename, password)
+						if err != nil {
+							return fmt.Errorf("unable to read bundle: %w", err)
+						}
+						defer func() {
+							for _, v := range bundle {
+								memguard.WipeBytes(v)
+							}
+						}()
+
+						keys := make([]string, 0, len(bundle))
+						for k := range bundle {
+							keys = append(keys, k)
+						}
+						sort.Strings(keys)
+						for _, k := range keys {
+							if err := store.Set(k, string(bundle[k])); err != nil {
+								return fmt.Errorf("unable to set secret %q in store %q: %w", k, storeID, err)
+							}
+						}
+						fmt.Printf("Imported %d secrets into store %q\n", len(keys), storeID)
+
 						return nil
 					},
 				},
@@ -259,3 +478,65 @@ you will be prompted to enter the value of the secret.
 		},
 	}
 }
+
+// secretBundle is the payload of the encrypted bundle used for exporting and
+// importing secrets
+type secretBundle struct {
+	Secrets map[string][]byte `json:"secrets"`
+}
+
+// bundlePassword returns the password for the secret bundle either from the
+// flag or by prompting the user
+func bundlePassword(cCtx *cli.Context) (string, error) {
+	if password := cCtx.String("bundle-password"); password != "" {
+		return password, nil
+	}
+
+	fmt.Printf("Enter bundle password: ")
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	fmt.Println()
+	if len(b) == 0 {
+		return "", errors.New("empty bundle password")
+	}
+	return string(b), nil
+}
+
+// writeSecretBundle writes the secrets as JWE encrypted with the given
+// password, the same format used by the 'jose' secret-store
+func writeSecretBundle(filename, password string, secrets map[string][]byte) error {
+	payload, err := json.Marshal(secretBundle{Secrets: secrets})
+	if err != nil {
+		return err
+	}
+	defer memguard.WipeBytes(payload)
+
+	token, err := jose.EncryptBytes(payload, jose.PBES2_HS256_A128KW, jose.A256GCM, password)
+	if err != nil {
+		return fmt.Errorf("encrypting failed: %w", err)
+	}
+	return os.WriteFile(filename, []byte(token), 0600)
+}
+
+// readSecretBundle reads the secrets from the JWE encrypted with the given
+// password
+func readSecretBundle(filename, password string) (map[string][]byte, error) {
+	token, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, _, err := jose.DecodeBytes(strings.TrimSpace(string(token)), password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting failed: %w", err)
+	}
+	defer memguard.WipeBytes(payload)
+
+	var bundle secretBundle
+	if err := json.Unmarshal(payload, &bundle); err != nil {
+		return nil, fmt.Errorf("parsing failed: %w", err)
+	}
+	return bundle.Secrets, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandSecretsDelete(t *testing.T) {
+	previous := maps.Clone(secrets["yoda"])
+	t.Cleanup(func() { secrets["yoda"] = previous })
+
+	buf := new(bytes.Buffer)
+	args := []string{os.Args[0], "secrets", "delete", "yoda", "episode1"}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	require.NotContains(t, secrets["yoda"], "episode1")
+	require.Contains(t, secrets["yoda"], "episode2")
+
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+	require.ErrorContains(t, err, "unable to delete secret")
+
+	args = []string{os.Args[0], "secrets", "delete", "yoda"}
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "invalid number of arguments")
+}
+
+func TestCommandSecretsExportImport(t *testing.T) {
+	secrets["ahsoka"] = map[string][]byte{"episode2": []byte("padawan")}
+	t.Cleanup(func() { delete(secrets, "ahsoka") })
+
+	bundle := filepath.Join(t.TempDir(), "secrets.jwe")
+	buf := new(bytes.Buffer)
+
+	args := []string{os.Args[0], "secrets", "export", "--bundle-password", "may the force", "yoda", bundle}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	// The bundle must not contain the secrets in clear text
+	content, err := os.ReadFile(bundle)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "member")
+
+	args = []string{os.Args[0], "secrets", "import", "--bundle-password", "wrong", "ahsoka", bundle}
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "decrypting failed")
+
+	args = []string{os.Args[0], "secrets", "import", "--bundle-password", "may the force", "ahsoka", bundle}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	require.Equal(t, map[string][]byte{
+		"episode1": []byte("member"),
+		"episode2": []byte("member"),
+		"episode3": []byte("member"),
+	}, secrets["ahsoka"])
+}
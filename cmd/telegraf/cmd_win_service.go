@@ -92,8 +92,9 @@ services with different configurations use
 							restartDelay: cCtx.String("restart-delay"),
 							autoRestart:  cCtx.Bool("auto-restart"),
 
-							configs:    cCtx.StringSlice("config"),
-							configDirs: cCtx.StringSlice("config-directory"),
+							configs:             cCtx.StringSlice("config"),
+							configDirs:          cCtx.StringSlice("config-directory"),
+							configDirAllFormats: cCtx.Bool("config-directory-all-formats"),
 						}
 						name := cCtx.String("service-name")
 						if err := installService(name, cfg); err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -108,6 +109,10 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			Name:  "config-directory",
 			Usage: "directory containing additional *.conf files",
 		},
+		&cli.BoolFlag{
+			Name:  "config-directory-all-formats",
+			Usage: "also load *.yaml, *.yml and *.json files from the config directories",
+		},
 		&cli.StringFlag{
 			Name: "section-filter",
 			Usage: "filter the sections to print, separator is ':'. " +
@@ -226,10 +231,14 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		g := GlobalFlags{
 			config:                  cCtx.StringSlice("config"),
 			configDir:               cCtx.StringSlice("config-directory"),
+			configDirAllFormats:     cCtx.Bool("config-directory-all-formats"),
 			testWait:                cCtx.Int("test-wait"),
 			configURLRetryAttempts:  cCtx.Int("config-url-retry-attempts"),
 			configURLWatchInterval:  cCtx.Duration("config-url-watch-interval"),
+			configURLVerifyKey:      cCtx.String("config-url-verify-key"),
+			configURLManifest:       cCtx.String("config-url-manifest"),
 			watchConfig:             cCtx.String("watch-config"),
+			reloadMode:              cCtx.String("reload-mode"),
 			watchInterval:           cCtx.Duration("watch-interval"),
 			watchDebounceInterval:   cCtx.Duration("watch-debounce-interval"),
 			pidFile:                 cCtx.String("pidfile"),
@@ -238,12 +247,26 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			oldEnvBehavior:          cCtx.Bool("old-env-behavior"),
 			printPluginConfigSource: cCtx.Bool("print-plugin-config-source"),
 			test:                    cCtx.Bool("test"),
+			record:                  cCtx.String("record"),
+			verify:                  cCtx.String("verify"),
 			debug:                   cCtx.Bool("debug"),
 			once:                    cCtx.Bool("once"),
 			quiet:                   cCtx.Bool("quiet"),
 			unprotected:             cCtx.Bool("unprotected"),
 		}
 
+		if g.configURLManifest != "" && g.configURLVerifyKey == "" {
+			return errors.New("'--config-url-manifest' requires '--config-url-verify-key'")
+		}
+		if g.record != "" || g.verify != "" {
+			if !g.test && g.testWait == 0 {
+				return errors.New("'--record' and '--verify' require test mode")
+			}
+			if g.record != "" && g.verify != "" {
+				return errors.New("'--record' and '--verify' cannot be used together")
+			}
+		}
+
 		w := WindowFlags{
 			service:             cCtx.String("service"),
 			serviceName:         cCtx.String("service-name"),
@@ -262,6 +285,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		getSecretStoreCommands(m)...,
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
+	commands = append(commands, getReplayCommands(configHandlingFlags, outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
 
 	app := &cli.App{
@@ -296,6 +320,12 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Usage: "monitoring config changes [notify, poll] of --config and --config-directory options. " +
 						"Notify supports linux, *bsd, and macOS. Poll is required for Windows and checks every 250ms.",
 				},
+				&cli.StringFlag{
+					Name: "reload-mode",
+					Usage: "reload behavior [full, incremental] on config changes. Incremental only restarts " +
+						"inputs, processors and outputs with a changed configuration.",
+					Value: "full",
+				},
 				&cli.DurationFlag{
 					Name:        "watch-debounce-interval",
 					Usage:       "Time duration to wait after a config change before reloading",
@@ -310,6 +340,25 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Name:  "password",
 					Usage: "password to unlock secret-stores",
 				},
+				&cli.StringFlag{
+					Name: "config-url-verify-key",
+					Usage: "PEM encoded public key file for verifying the signature of URL based configuration files. " +
+						"The signature is fetched from the configuration URL with an additional '.sig' suffix.",
+				},
+				&cli.StringFlag{
+					Name: "config-url-manifest",
+					Usage: "location of a signed SHA-256 manifest relative to the configuration URL used for " +
+						"verification instead of a signature per configuration file",
+				},
+				&cli.StringFlag{
+					Name:  "record",
+					Usage: "in test mode, record the resulting metrics as snapshot to the given file",
+				},
+				&cli.StringFlag{
+					Name: "verify",
+					Usage: "in test mode, compare the resulting metrics against the snapshot in the given file " +
+						"ignoring timestamps and exit with an error if they differ",
+				},
 				//
 				// Bool flags
 				&cli.BoolFlag{
@@ -101,6 +101,15 @@ func (s *MockSecretStore) Set(key, value string) error {
 	s.Secrets[key] = []byte(value)
 	return nil
 }
+
+func (s *MockSecretStore) Delete(key string) error {
+	if _, found := s.Secrets[key]; !found {
+		return errors.New("not found")
+	}
+	delete(s.Secrets, key)
+	return nil
+}
+
 func (s *MockSecretStore) List() ([]string, error) {
 	keys := make([]string, 0, len(s.Secrets))
 	for k := range s.Secrets {
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -36,10 +37,14 @@ var stop chan struct{}
 type GlobalFlags struct {
 	config                  []string
 	configDir               []string
+	configDirAllFormats     bool
 	testWait                int
 	configURLRetryAttempts  int
 	configURLWatchInterval  time.Duration
+	configURLVerifyKey      string
+	configURLManifest       string
 	watchConfig             string
+	reloadMode              string
 	watchInterval           time.Duration
 	watchDebounceInterval   time.Duration
 	pidFile                 string
@@ -48,6 +53,8 @@ type GlobalFlags struct {
 	oldEnvBehavior          bool
 	printPluginConfigSource bool
 	test                    bool
+	record                  string
+	verify                  string
 	debug                   bool
 	once                    bool
 	quiet                   bool
@@ -80,7 +87,8 @@ type Telegraf struct {
 	configFiles        []string
 	secretstoreFilters []string
 
-	cfg *config.Config
+	cfg   *config.Config
+	agent atomic.Pointer[agent.Agent]
 
 	GlobalFlags
 	WindowFlags
@@ -140,7 +148,12 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 }
 
 func (t *Telegraf) reloadLoop() error {
-	reloadConfig := false
+	switch t.reloadMode {
+	case "", "full", "incremental":
+	default:
+		return fmt.Errorf("invalid reload mode %q", t.reloadMode)
+	}
+
 	reload := make(chan bool, 1)
 	reload <- true
 	for <-reload {
@@ -170,7 +183,10 @@ func (t *Telegraf) reloadLoop() error {
 				}
 			}
 		}
-		if t.configURLWatchInterval > 0 {
+		watchRemoteConfigs := func() {
+			if t.configURLWatchInterval <= 0 {
+				return
+			}
 			remoteConfigs := make([]string, 0)
 			for _, fConfig := range t.configFiles {
 				if isURL(fConfig) {
@@ -181,34 +197,60 @@ func (t *Telegraf) reloadLoop() error {
 				go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
 			}
 		}
+		watchRemoteConfigs()
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					// May need to update the list of known config files
-					// if a delete or create occured. That way on the reload
-					// we ensure we watch the correct files.
-					if err := t.getConfigFiles(); err != nil {
-						log.Println("E! Error loading config files: ", err)
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						// May need to update the list of known config files
+						// if a delete or create occured. That way on the reload
+						// we ensure we watch the correct files.
+						if err := t.getConfigFiles(); err != nil {
+							log.Println("E! Error loading config files: ", err)
+						}
+						// Load the complete configuration including remote
+						// and included files before stopping the running
+						// agent to keep it running on errors
+						c, err := t.loadConfiguration()
+						if err == nil {
+							err = t.checkConfiguration(c)
+						}
+						if err != nil {
+							log.Printf("E! Keeping the current config: %v", err)
+							// The remote config watcher stops after a change
+							watchRemoteConfigs()
+							continue
+						}
+						if t.reloadMode == "incremental" {
+							err := t.reloadAgent(ctx, c)
+							if err == nil {
+								// The remote config watcher stops after a change
+								watchRemoteConfigs()
+								continue
+							}
+							log.Printf("W! Incremental reload not possible, restarting agent: %v", err)
+						}
+						<-reload
+						t.cfg = c
+						reload <- true
 					}
-					<-reload
-					reload <- true
+					cancel()
+				case err := <-t.pprofErr:
+					log.Printf("E! pprof server failed: %v", err)
+					cancel()
+				case <-stop:
+					cancel()
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
-		err := t.runAgent(ctx, reloadConfig)
+		err := t.runAgent(ctx)
 		if err != nil && !errors.Is(err, context.Canceled) {
 			return fmt.Errorf("[telegraf] Error running agent: %w", err)
 		}
-		reloadConfig = true
 	}
 
 	return nil
@@ -393,6 +435,12 @@ func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	c.InputFilters = t.inputFilters
 	c.SecretStoreFilters = t.secretstoreFilters
 
+	verifier, err := t.remoteConfigVerifier()
+	if err != nil {
+		return c, err
+	}
+	c.RemoteConfigVerifier = verifier
+
 	if err := t.getConfigFiles(); err != nil {
 		return c, err
 	}
@@ -402,12 +450,21 @@ func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	return c, nil
 }
 
+// remoteConfigVerifier returns the verifier for remote configurations or nil
+// if verification is disabled
+func (t *Telegraf) remoteConfigVerifier() (*config.RemoteConfigVerifier, error) {
+	if t.configURLVerifyKey == "" {
+		return nil, nil
+	}
+	return config.NewRemoteConfigVerifier(t.configURLVerifyKey, t.configURLManifest)
+}
+
 func (t *Telegraf) getConfigFiles() error {
 	var configFiles []string
 
 	configFiles = append(configFiles, t.config...)
 	for _, fConfigDirectory := range t.configDir {
-		files, err := config.WalkDirectory(fConfigDirectory)
+		files, err := config.WalkDirectory(fConfigDirectory, t.configDirAllFormats)
 		if err != nil {
 			return err
 		}
@@ -416,7 +473,7 @@ func (t *Telegraf) getConfigFiles() error {
 
 	// load default config paths if none are found
 	if len(configFiles) == 0 {
-		defaultFiles, err := config.GetDefaultConfigPath()
+		defaultFiles, err := config.GetDefaultConfigPath(t.configDirAllFormats)
 		if err != nil {
 			return fmt.Errorf("unable to load default config paths: %w", err)
 		}
@@ -427,15 +484,17 @@ func (t *Telegraf) getConfigFiles() error {
 	return nil
 }
 
-func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
-	c := t.cfg
-	var err error
-	if reloadConfig {
-		if c, err = t.loadConfiguration(); err != nil {
-			return err
-		}
+// reloadAgent applies the given configuration to the running agent by only
+// restarting the plugins with changed configuration.
+func (t *Telegraf) reloadAgent(ctx context.Context, c *config.Config) error {
+	ag := t.agent.Load()
+	if ag == nil {
+		return errors.New("agent not running")
 	}
+	return ag.Reload(ctx, c)
+}
 
+func (t *Telegraf) checkConfiguration(c *config.Config) error {
 	if !t.test && t.testWait == 0 && len(c.Outputs) == 0 {
 		return errors.New("no outputs found, probably invalid config file provided")
 	}
@@ -451,6 +510,15 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		return fmt.Errorf("agent flush_interval must be positive; found %v", c.Agent.Interval)
 	}
 
+	return nil
+}
+
+func (t *Telegraf) runAgent(ctx context.Context) error {
+	c := t.cfg
+	if err := t.checkConfiguration(c); err != nil {
+		return err
+	}
+
 	// Setup logging as configured.
 	logConfig := &logger.Config{
 		Debug:                   c.Agent.Debug || t.debug,
@@ -534,6 +602,9 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 
 	if t.test || t.testWait != 0 {
 		wait := time.Duration(t.testWait) * time.Second
+		if t.record != "" || t.verify != "" {
+			return t.testSnapshot(ctx, ag, wait)
+		}
 		return ag.Test(ctx, wait)
 	}
 
@@ -558,9 +629,43 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 
+	t.agent.Store(ag)
+	defer t.agent.Store(nil)
+
 	return ag.Run(ctx)
 }
 
+// testSnapshot runs the agent in test mode and records the resulting metrics
+// as snapshot or verifies them against a previously recorded snapshot.
+func (t *Telegraf) testSnapshot(ctx context.Context, ag *agent.Agent, wait time.Duration) error {
+	metrics, err := ag.TestCollect(ctx, wait)
+	if err != nil {
+		return err
+	}
+
+	if t.record != "" {
+		if err := agent.WriteSnapshot(t.record, metrics); err != nil {
+			return fmt.Errorf("recording snapshot failed: %w", err)
+		}
+		log.Printf("I! Recorded %d metrics to %q", len(metrics), t.record)
+		return nil
+	}
+
+	expected, err := agent.ReadSnapshot(t.verify)
+	if err != nil {
+		return fmt.Errorf("reading snapshot failed: %w", err)
+	}
+	diff, err := agent.DiffSnapshot(expected, metrics)
+	if err != nil {
+		return err
+	}
+	if diff != "" {
+		return fmt.Errorf("metrics differ from snapshot %q:\n%s", t.verify, diff)
+	}
+	log.Printf("I! Metrics match snapshot %q", t.verify)
+	return nil
+}
+
 // isURL checks if string is valid url
 func isURL(str string) bool {
 	u, err := url.Parse(str)
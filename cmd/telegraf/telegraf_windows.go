@@ -41,6 +41,7 @@ func (t *Telegraf) Run() error {
 				configs:      t.config,
 				configDirs:   t.configDir,
 				watchConfig:  t.watchConfig,
+				reloadMode:   t.reloadMode,
 			}
 			if err := installService(t.serviceName, cfg); err != nil {
 				return err
@@ -169,9 +170,11 @@ type serviceConfig struct {
 	autoRestart  bool
 
 	// Telegraf parameters
-	configs     []string
-	configDirs  []string
-	watchConfig string
+	configs             []string
+	configDirs          []string
+	configDirAllFormats bool
+	watchConfig         string
+	reloadMode          string
 }
 
 func installService(name string, cfg *serviceConfig) error {
@@ -195,12 +198,18 @@ func installService(name string, cfg *serviceConfig) error {
 	for _, dn := range cfg.configDirs {
 		args = append(args, "--config-directory", dn)
 	}
+	if cfg.configDirAllFormats {
+		args = append(args, "--config-directory-all-formats")
+	}
 	if len(args) == 0 {
 		args = append(args, "--config", filepath.Join(programFiles, "Telegraf", "telegraf.conf"))
 	}
 	if cfg.watchConfig != "" {
 		args = append(args, "--watch-config", cfg.watchConfig)
 	}
+	if cfg.reloadMode != "" && cfg.reloadMode != "full" {
+		args = append(args, "--reload-mode", cfg.reloadMode)
+	}
 	// Pass the service name to the command line, to have a custom name when relaunching as a service
 	args = append(args, "--service-name", name)
 
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/influxdata/toml/ast"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// hostFacts contains the facts about the host available in the 'enable_if'
+// expressions of plugins
+type hostFacts struct {
+	env  *cel.Env
+	vars map[string]interface{}
+
+	processes     map[string]bool
+	processesOnce sync.Once
+}
+
+func newHostFacts() (*hostFacts, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("getting hostname failed: %w", err)
+	}
+
+	facts := &hostFacts{
+		vars: map[string]interface{}{
+			"os":       runtime.GOOS,
+			"arch":     runtime.GOARCH,
+			"hostname": hostname,
+		},
+	}
+
+	facts.env, err = cel.NewEnv(
+		cel.Variable("os", cel.StringType),
+		cel.Variable("arch", cel.StringType),
+		cel.Variable("hostname", cel.StringType),
+		cel.Function(
+			"env",
+			cel.Overload("env_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					// Unset variables are empty to not fail the evaluation
+					return types.String(os.Getenv(arg.Value().(string)))
+				}),
+			),
+		),
+		cel.Function(
+			"file_exists",
+			cel.Overload("file_exists_string", []*cel.Type{cel.StringType}, cel.BoolType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					_, err := os.Stat(arg.Value().(string))
+					return types.Bool(err == nil)
+				}),
+			),
+		),
+		cel.Function(
+			"service_running",
+			cel.Overload("service_running_string", []*cel.Type{cel.StringType}, cel.BoolType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.Bool(facts.processRunning(arg.Value().(string)))
+				}),
+			),
+		),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	return facts, nil
+}
+
+// processRunning checks if a process with the given executable name is
+// running. The process list is only collected once per configuration.
+func (f *hostFacts) processRunning(name string) bool {
+	f.processesOnce.Do(func() {
+		f.processes = make(map[string]bool)
+		procs, err := process.Processes()
+		if err != nil {
+			log.Printf("W! Listing processes for 'enable_if' failed: %v", err)
+			return
+		}
+		for _, p := range procs {
+			if n, err := p.Name(); err == nil {
+				f.processes[strings.TrimSuffix(n, ".exe")] = true
+			}
+		}
+	})
+	return f.processes[strings.TrimSuffix(name, ".exe")]
+}
+
+// evaluate compiles the given expression and evaluates it against the facts
+func (f *hostFacts) evaluate(expression string) (bool, error) {
+	tree, issues := f.env.Compile(expression)
+	if issues.Err() != nil {
+		return false, issues.Err()
+	}
+	if tree.OutputType() != cel.BoolType {
+		return false, errors.New("expression needs to return a boolean")
+	}
+	program, err := f.env.Program(tree)
+	if err != nil {
+		return false, err
+	}
+
+	result, _, err := program.Eval(f.vars)
+	if err != nil {
+		return false, fmt.Errorf("evaluating expression failed: %w", err)
+	}
+	enabled, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %T instead of a boolean", result.Value())
+	}
+	return enabled, nil
+}
+
+// applyConditions evaluates the 'enable_if' option of all plugin tables and
+// removes the plugins whose condition is not met. When linting, the
+// conditions are only checked for errors and all plugins are kept.
+func (c *Config) applyConditions(tbl *ast.Table) error {
+	for _, category := range pluginCategories {
+		node, found := tbl.Fields[category]
+		if !found {
+			continue
+		}
+		categoryTbl, ok := node.(*ast.Table)
+		if !ok {
+			continue
+		}
+
+		for name, pluginVal := range categoryTbl.Fields {
+			switch pluginTbl := pluginVal.(type) {
+			case *ast.Table:
+				enabled, err := c.pluginEnabled(pluginTbl)
+				if err != nil {
+					return fmt.Errorf("plugin %s.%s: line %d: %w", category, name, pluginTbl.Line, err)
+				}
+				if !enabled {
+					log.Printf("I! Skipping plugin %s.%s defined in line %d as its 'enable_if' condition is not met",
+						category, name, pluginTbl.Line)
+					delete(categoryTbl.Fields, name)
+				}
+			case []*ast.Table:
+				plugins := make([]*ast.Table, 0, len(pluginTbl))
+				for _, plugin := range pluginTbl {
+					enabled, err := c.pluginEnabled(plugin)
+					if err != nil {
+						return fmt.Errorf("plugin %s.%s: line %d: %w", category, name, plugin.Line, err)
+					}
+					if !enabled {
+						log.Printf("I! Skipping plugin %s.%s defined in line %d as its 'enable_if' condition is not met",
+							category, name, plugin.Line)
+						continue
+					}
+					plugins = append(plugins, plugin)
+				}
+				if len(plugins) == 0 {
+					delete(categoryTbl.Fields, name)
+				} else {
+					categoryTbl.Fields[name] = plugins
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// pluginEnabled evaluates the 'enable_if' option of the plugin table and
+// removes the option
+func (c *Config) pluginEnabled(tbl *ast.Table) (bool, error) {
+	node, found := tbl.Fields["enable_if"]
+	if !found {
+		return true, nil
+	}
+	delete(tbl.Fields, "enable_if")
+
+	kv, ok := node.(*ast.KeyValue)
+	if !ok {
+		return false, errors.New("invalid 'enable_if' option: expected a string")
+	}
+	expression, ok := kv.Value.(*ast.String)
+	if !ok {
+		return false, fmt.Errorf("line %d: invalid 'enable_if' option: expected a string", kv.Line)
+	}
+
+	if c.facts == nil {
+		facts, err := newHostFacts()
+		if err != nil {
+			return false, err
+		}
+		c.facts = facts
+	}
+
+	enabled, err := c.facts.evaluate(expression.Value)
+	if err != nil {
+		return false, fmt.Errorf("invalid 'enable_if' option: %w", err)
+	}
+	return enabled || c.linter != nil, nil
+}
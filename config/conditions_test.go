@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestEnableIf(t *testing.T) {
+	t.Setenv("TELEGRAF_TEST_ROLE", "db")
+	existing := filepath.Join(t.TempDir(), "marker")
+	require.NoError(t, os.WriteFile(existing, nil, 0600))
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+[[inputs.memcached]]
+  servers = ["os"]
+  enable_if = "os == '%s' && arch == '%s'"
+
+[[inputs.memcached]]
+  servers = ["other os"]
+  enable_if = "os == 'plan9'"
+
+[[inputs.memcached]]
+  servers = ["env"]
+  enable_if = "env('TELEGRAF_TEST_ROLE') == 'db'"
+
+[[inputs.memcached]]
+  servers = ["unset env"]
+  enable_if = "env('TELEGRAF_TEST_UNSET_VARIABLE') == 'db'"
+
+[[inputs.memcached]]
+  servers = ["empty env"]
+  enable_if = "env('TELEGRAF_TEST_UNSET_VARIABLE') == ''"
+
+[[inputs.memcached]]
+  servers = ["file"]
+  enable_if = "file_exists('%s')"
+
+[[inputs.memcached]]
+  servers = ["missing file"]
+  enable_if = "file_exists('%s')"
+
+[[inputs.memcached]]
+  servers = ["hostname"]
+  enable_if = "hostname == '%s'"
+
+[[inputs.memcached]]
+  servers = ["service"]
+  enable_if = "service_running('telegraf-test-does-not-exist')"
+
+[[inputs.memcached]]
+  servers = ["unconditional"]
+`, runtime.GOOS, runtime.GOARCH, filepath.ToSlash(existing), filepath.ToSlash(existing)+".missing", hostname)
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Empty(t, c.UnusedFields)
+
+	servers := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		plugin, ok := input.Input.(*MockupInputPlugin)
+		require.True(t, ok)
+		servers = append(servers, plugin.Servers...)
+	}
+	require.Equal(t, []string{"os", "env", "empty env", "file", "hostname", "unconditional"}, servers)
+}
+
+func TestEnableIfTemplate(t *testing.T) {
+	cfg := []byte(`
+[templates.disabled]
+  enable_if = "os == 'plan9'"
+
+[[inputs.memcached]]
+  inherit = "disabled"
+
+[outputs.azure_monitor]
+  inherit = "disabled"
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.Empty(t, c.Inputs)
+	require.Empty(t, c.Outputs)
+}
+
+func TestEnableIfErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{
+			name:       "invalid syntax",
+			expression: `"os =="`,
+			expected:   "plugin inputs.memcached: line 2: invalid 'enable_if' option",
+		},
+		{
+			name:       "unknown variable",
+			expression: `"kernel == 'linux'"`,
+			expected:   "undeclared reference to 'kernel'",
+		},
+		{
+			name:       "non-boolean",
+			expression: `"hostname"`,
+			expected:   "expression needs to return a boolean",
+		},
+		{
+			name:       "non-string",
+			expression: `true`,
+			expected:   "line 3: invalid 'enable_if' option: expected a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := "\n[[inputs.memcached]]\n  enable_if = " + tt.expression + "\n"
+			c := config.NewConfig()
+			require.ErrorContains(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath), tt.expected)
+		})
+	}
+}
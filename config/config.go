@@ -99,10 +99,25 @@ type Config struct {
 
 	Persister *persister.Persister
 
+	// RemoteConfigVerifier verifies configurations fetched from URLs if set
+	RemoteConfigVerifier *RemoteConfigVerifier
+
 	NumberSecrets uint64
 
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
+
+	// linter collects the problems found while loading if set
+	linter *linter
+
+	// templates contains the plugin templates defined so far
+	templates map[string]*ast.Table
+
+	// includeStack contains the files currently loaded via 'include'
+	includeStack []string
+
+	// facts contains the host facts for evaluating 'enable_if' conditions
+	facts *hostFacts
 }
 
 // Ordered plugins used to keep the order in which they appear in a file
@@ -123,6 +138,7 @@ func NewConfig() *Config {
 	c := &Config{
 		UnusedFields:      make(map[string]bool),
 		unusedFieldsMutex: &sync.Mutex{},
+		templates:         make(map[string]*ast.Table),
 
 		// Agent defaults:
 		Agent: &AgentConfig{
@@ -275,6 +291,19 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Backend used for storing the plugin states. Supported are "file"
+	// (default), "boltdb" for an embedded key-value store at the location
+	// given by Statefile and "secretstore" for storing the states under the
+	// Statefile key in the secret-store given by StatefileSecretStore.
+	StatefileBackend string `toml:"statefile_backend"`
+
+	// ID of the secret-store to use with the "secretstore" backend.
+	StatefileSecretStore string `toml:"statefile_secretstore"`
+
+	// Interval for periodically storing the plugin states in addition to
+	// storing them on termination. Disabled if zero.
+	StatefileInterval Duration `toml:"statefile_interval"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -293,12 +322,53 @@ type AgentConfig struct {
 	ConfigURLRetryAttempts int `toml:"config_url_retry_attempts"`
 
 	// BufferStrategy is the metric buffer type to use for a given output plugin.
-	// Supported types currently are "memory" and "disk_write_through" (alias: "disk").
+	// Supported types currently are "memory", "disk_write_through" (alias:
+	// "disk"), "disk_segmented" and "hybrid".
 	BufferStrategy string `toml:"buffer_strategy"`
 
 	// BufferDirectory is the directory to store buffer files for serialized
-	// to disk metrics when using the "disk_write_through" buffer strategy.
+	// to disk metrics when using the "disk_write_through", "disk_segmented" or
+	// "hybrid" buffer strategies.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// BufferMaxSize is the maximum size of the buffer files of each output
+	// plugin when using the "disk_segmented" or "hybrid" buffer strategies.
+	// Zero means unlimited.
+	BufferMaxSize Size `toml:"buffer_max_size"`
+
+	// BufferSegmentSize is the size of a single buffer file when using the
+	// "disk_segmented" or "hybrid" buffer strategies.
+	BufferSegmentSize Size `toml:"buffer_segment_size"`
+
+	// BufferOverflowPolicy determines which metrics to drop when reaching the
+	// buffer_max_size, either "drop_oldest" (default) or "drop_newest".
+	BufferOverflowPolicy string `toml:"buffer_overflow_policy"`
+
+	// APIAddress is the address to serve the runtime control API on. Use
+	// "unix://" addresses to listen on a unix socket. The API is disabled if
+	// empty.
+	APIAddress string `toml:"api_address"`
+
+	// SelfstatAddress is the address to serve the internal statistics on in
+	// Prometheus exposition format independent of the outputs. Use "unix://"
+	// addresses to listen on a unix socket. Disabled if empty.
+	SelfstatAddress string `toml:"selfstat_address"`
+
+	// SelfstatOTLPEndpoint is the gRPC endpoint of an OpenTelemetry collector
+	// to push the internal statistics to every SelfstatOTLPInterval
+	// independent of the outputs. Disabled if empty.
+	SelfstatOTLPEndpoint string   `toml:"selfstat_otlp_endpoint"`
+	SelfstatOTLPInterval Duration `toml:"selfstat_otlp_interval"`
+
+	// TLS settings for pushing the internal statistics. The connection is
+	// secured using the system's certificate authorities by default and can
+	// only be unencrypted by explicitly disabling TLS.
+	SelfstatOTLPTLSCA              string `toml:"selfstat_otlp_tls_ca"`
+	SelfstatOTLPTLSCert            string `toml:"selfstat_otlp_tls_cert"`
+	SelfstatOTLPTLSKey             string `toml:"selfstat_otlp_tls_key"`
+	SelfstatOTLPTLSServerName      string `toml:"selfstat_otlp_tls_server_name"`
+	SelfstatOTLPInsecureSkipVerify bool   `toml:"selfstat_otlp_insecure_skip_verify"`
+	SelfstatOTLPTLSEnable          *bool  `toml:"selfstat_otlp_tls_enable"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -408,6 +478,31 @@ func (c *Config) SecretstoreNamesWithSources() string {
 	return getPluginSourcesTable(plugins)
 }
 
+// Routes returns the sorted list of routes declared by the inputs, processors
+// and outputs.
+func (c *Config) Routes() []string {
+	set := make(map[string]bool)
+	for _, input := range c.Inputs {
+		set[input.Config.Route] = true
+	}
+	for _, processor := range c.Processors {
+		set[processor.Config.Route] = true
+	}
+	for _, output := range c.Outputs {
+		for _, route := range output.Config.Routes {
+			set[route] = true
+		}
+	}
+	delete(set, "")
+
+	routes := make([]string, 0, len(set))
+	for route := range set {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
 // PluginNameCounts returns a string of plugin names and their counts.
 // PluginNameCounts returns a list of sorted plugin names and their count
 func PluginNameCounts(plugins []string) []string {
@@ -451,8 +546,9 @@ func sliceContains(name string, list []string) bool {
 	return false
 }
 
-// WalkDirectory collects all toml files that need to be loaded
-func WalkDirectory(path string) ([]string, error) {
+// WalkDirectory collects all toml files that need to be loaded, including yaml
+// and json files if allFormats is set
+func WalkDirectory(path string, allFormats bool) ([]string, error) {
 	var files []string
 	walkfn := func(thispath string, info os.FileInfo, _ error) error {
 		if info == nil {
@@ -468,8 +564,13 @@ func WalkDirectory(path string) ([]string, error) {
 
 			return nil
 		}
-		name := info.Name()
-		if len(name) < 6 || name[len(name)-5:] != ".conf" {
+		switch filepath.Ext(info.Name()) {
+		case ".conf":
+		case ".yaml", ".yml", ".json":
+			if !allFormats {
+				return nil
+			}
+		default:
 			return nil
 		}
 		files = append(files, thispath)
@@ -482,7 +583,9 @@ func WalkDirectory(path string) ([]string, error) {
 //  1. $TELEGRAF_CONFIG_PATH
 //  2. $HOME/.telegraf/telegraf.conf
 //  3. /etc/telegraf/telegraf.conf and /etc/telegraf/telegraf.d/*.conf
-func GetDefaultConfigPath() ([]string, error) {
+//
+// The directory also includes yaml and json files if allFormats is set.
+func GetDefaultConfigPath(allFormats bool) ([]string, error) {
 	envfile := os.Getenv("TELEGRAF_CONFIG_PATH")
 	homefile := os.ExpandEnv("${HOME}/.telegraf/telegraf.conf")
 	etcfile := "/etc/telegraf/telegraf.conf"
@@ -513,7 +616,7 @@ func GetDefaultConfigPath() ([]string, error) {
 		confFiles = append(confFiles, etcfile)
 	}
 	if _, err := os.Stat(etcfolder); err == nil {
-		files, err := WalkDirectory(etcfolder)
+		files, err := WalkDirectory(etcfolder, allFormats)
 		if err != nil {
 			log.Printf("W! unable walk %q: %s", etcfolder, err)
 		}
@@ -540,11 +643,17 @@ func (c *Config) LoadConfig(path string) error {
 		log.Printf("I! Loading config: %s", path)
 	}
 
-	data, _, err := LoadConfigFileWithRetries(path, c.Agent.ConfigURLRetryAttempts)
+	data, remote, err := LoadConfigFileWithRetries(path, c.Agent.ConfigURLRetryAttempts)
 	if err != nil {
 		return fmt.Errorf("loading config file %s failed: %w", path, err)
 	}
 
+	if remote && c.RemoteConfigVerifier != nil {
+		if err := c.RemoteConfigVerifier.Verify(path, data, c.Agent.ConfigURLRetryAttempts); err != nil {
+			return fmt.Errorf("verifying config file %s failed: %w", path, err)
+		}
+	}
+
 	if err = c.LoadConfigData(data, path); err != nil {
 		return fmt.Errorf("loading config file %s failed: %w", path, err)
 	}
@@ -577,6 +686,17 @@ func (c *Config) LoadAll(configFiles ...string) error {
 	}
 	c.NumberSecrets = uint64(count)
 
+	// Link the state persister to its secret-store if requested
+	if c.Persister != nil {
+		if backend, ok := c.Persister.Backend.(*persister.SecretStoreBackend); ok {
+			store, found := c.SecretStores[backend.StoreID]
+			if !found {
+				return fmt.Errorf("unknown secret-store %q for storing states", backend.StoreID)
+			}
+			backend.Store = store
+		}
+	}
+
 	// Let's link all secrets to their secret-stores
 	return c.LinkSecrets()
 }
@@ -593,13 +713,32 @@ func WithSourcePath(path string) cfgDataOption {
 	}
 }
 
-// LoadConfigData loads TOML-formatted config data
+// LoadConfigData loads TOML-formatted config data or YAML and JSON-formatted
+// data if the path has a corresponding extension
 func (c *Config) LoadConfigData(data []byte, path string) error {
-	tbl, err := parseConfig(data)
+	tbl, err := parseConfig(data, path)
 	if err != nil {
 		return fmt.Errorf("error parsing data: %w", err)
 	}
 
+	// Load the included files first to make their templates available
+	if err := c.loadIncludes(tbl, path, c.LoadConfig); err != nil {
+		return err
+	}
+
+	// Resolve the plugin templates
+	if err := c.addTemplates(tbl); err != nil {
+		return err
+	}
+	if err := c.applyTemplates(tbl); err != nil {
+		return err
+	}
+
+	// Skip the plugins not applicable to this host
+	if err := c.applyConditions(tbl); err != nil {
+		return err
+	}
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -658,6 +797,21 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		c.Persister = &persister.Persister{
 			Filename: c.Agent.Statefile,
 		}
+		switch c.Agent.StatefileBackend {
+		case "", "file":
+		case "boltdb":
+			c.Persister.Backend = &persister.BoltDBBackend{Filename: c.Agent.Statefile}
+		case "secretstore":
+			if c.Agent.StatefileSecretStore == "" {
+				return errors.New("'statefile_secretstore' is required for the 'secretstore' statefile backend")
+			}
+			c.Persister.Backend = &persister.SecretStoreBackend{
+				StoreID: c.Agent.StatefileSecretStore,
+				Key:     c.Agent.Statefile,
+			}
+		default:
+			return fmt.Errorf("invalid statefile backend %q", c.Agent.StatefileBackend)
+		}
 	}
 
 	if len(c.UnusedFields) > 0 {
@@ -673,6 +827,10 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
+		if name == "include" || name == "templates" {
+			continue
+		}
+
 		subTable, ok := val.(*ast.Table)
 		if !ok {
 			return fmt.Errorf("invalid configuration, error parsing field %q as table", name)
@@ -811,7 +969,13 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 
 	sort.Sort(c.fileAggProcessors)
 	for _, op := range c.fileAggProcessors {
-		c.AggProcessors = append(c.AggProcessors, op.plugin.(*models.RunningProcessor))
+		// Routed metrics do not pass the aggregators, so processors of a
+		// route are not required after the aggregators.
+		processor := op.plugin.(*models.RunningProcessor)
+		if processor.Config.Route != "" {
+			continue
+		}
+		c.AggProcessors = append(c.AggProcessors, processor)
 	}
 
 	return nil
@@ -855,7 +1019,7 @@ func LoadConfigFileWithRetries(config string, urlRetryAttempts int) ([]byte, boo
 
 	mimeType := http.DetectContentType(buffer)
 	if !strings.Contains(mimeType, "text/plain") {
-		return nil, false, fmt.Errorf("provided config is not a TOML file: %s", config)
+		return nil, false, fmt.Errorf("provided config is not a TOML, YAML or JSON file: %s", config)
 	}
 
 	return buffer, false, nil
@@ -921,11 +1085,22 @@ func requestURLConfig(req *http.Request) ([]byte, error) {
 	return body, nil
 }
 
-// parseConfig loads a TOML configuration from a provided path and
-// returns the AST produced from the TOML parser. When loading the file, it
-// will find environment variables and replace them.
-func parseConfig(contents []byte) (*ast.Table, error) {
+// parseConfig loads a TOML, YAML or JSON configuration, with the format
+// determined by the extension of the given path, and returns the AST produced
+// from the TOML parser. When loading the file, it will find environment
+// variables and replace them.
+func parseConfig(contents []byte, path string) (*ast.Table, error) {
 	contents = trimBOM(contents)
+
+	format := configFormat(path)
+	if format != "toml" {
+		outputBytes, err := substituteEnvironment(contents, OldEnvVarReplacement)
+		if err != nil {
+			return nil, err
+		}
+		return parseYAML(outputBytes)
+	}
+
 	var err error
 	contents, err = removeComments(contents)
 	if err != nil {
@@ -1499,6 +1674,7 @@ func (c *Config) buildProcessor(category, name, source string, tbl *ast.Table) (
 	conf.Order = c.getFieldInt64(tbl, "order")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.Route = c.getFieldString(tbl, "route")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1529,7 +1705,7 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 
 	oldPass := c.getFieldStringSlice(tbl, "pass")
 	if len(oldPass) > 0 {
-		PrintOptionDeprecationNotice(plugin, "pass", telegraf.DeprecationInfo{
+		c.printOptionDeprecation(plugin, "pass", telegraf.DeprecationInfo{
 			Since:     "0.10.4",
 			RemovalIn: "1.35.0",
 			Notice:    "use 'fieldinclude' instead",
@@ -1539,7 +1715,7 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 
 	oldFieldPass := c.getFieldStringSlice(tbl, "fieldpass")
 	if len(oldFieldPass) > 0 {
-		PrintOptionDeprecationNotice(plugin, "fieldpass", telegraf.DeprecationInfo{
+		c.printOptionDeprecation(plugin, "fieldpass", telegraf.DeprecationInfo{
 			Since:     "1.29.0",
 			RemovalIn: "1.40.0",
 			Notice:    "use 'fieldinclude' instead",
@@ -1554,7 +1730,7 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 
 	oldDrop := c.getFieldStringSlice(tbl, "drop")
 	if len(oldDrop) > 0 {
-		PrintOptionDeprecationNotice(plugin, "drop", telegraf.DeprecationInfo{
+		c.printOptionDeprecation(plugin, "drop", telegraf.DeprecationInfo{
 			Since:     "0.10.4",
 			RemovalIn: "1.35.0",
 			Notice:    "use 'fieldexclude' instead",
@@ -1564,7 +1740,7 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 
 	oldFieldDrop := c.getFieldStringSlice(tbl, "fielddrop")
 	if len(oldFieldDrop) > 0 {
-		PrintOptionDeprecationNotice(plugin, "fielddrop", telegraf.DeprecationInfo{
+		c.printOptionDeprecation(plugin, "fielddrop", telegraf.DeprecationInfo{
 			Since:     "1.29.0",
 			RemovalIn: "1.40.0",
 			Notice:    "use 'fieldexclude' instead",
@@ -1618,6 +1794,7 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.NameOverride = c.getFieldString(tbl, "name_override")
 	cp.Alias = c.getFieldString(tbl, "alias")
 	cp.LogLevel = c.getFieldString(tbl, "log_level")
+	cp.Route = c.getFieldString(tbl, "route")
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1653,16 +1830,26 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 		return nil, err
 	}
 
+	switch c.Agent.BufferOverflowPolicy {
+	case "", "drop_oldest", "drop_newest":
+	default:
+		return nil, fmt.Errorf("invalid buffer overflow policy %q", c.Agent.BufferOverflowPolicy)
+	}
+
 	bufferStrategy := c.Agent.BufferStrategy
 	if bufferStrategy == "disk" {
 		bufferStrategy = "disk_write_through"
 	}
+
 	oc := &models.OutputConfig{
-		Name:            name,
-		Source:          source,
-		Filter:          filter,
-		BufferStrategy:  bufferStrategy,
-		BufferDirectory: c.Agent.BufferDirectory,
+		Name:                 name,
+		Source:               source,
+		Filter:               filter,
+		BufferStrategy:       bufferStrategy,
+		BufferDirectory:      c.Agent.BufferDirectory,
+		BufferMaxSize:        int64(c.Agent.BufferMaxSize),
+		BufferSegmentSize:    int64(c.Agent.BufferSegmentSize),
+		BufferOverflowPolicy: c.Agent.BufferOverflowPolicy,
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1677,13 +1864,21 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.DeadLetterFile = c.getFieldString(tbl, "dead_letter_file")
+	oc.DeadLetterOutput = c.getFieldString(tbl, "dead_letter_output")
+	oc.Routes = c.getFieldStringSlice(tbl, "routes")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
 
-	if oc.BufferStrategy == "disk_write_through" {
+	switch oc.BufferStrategy {
+	case "disk_write_through":
 		log.Printf("W! Using disk-write-through buffer strategy for plugin outputs.%s, this is an experimental feature", name)
+	case "disk_segmented":
+		log.Printf("W! Using segmented disk buffer strategy for plugin outputs.%s, this is an experimental feature", name)
+	case "hybrid":
+		log.Printf("W! Using hybrid buffer strategy for plugin outputs.%s, this is an experimental feature", name)
 	}
 
 	// Generate an ID for the plugin
@@ -1697,7 +1892,7 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	case "alias", "always_include_local_tags",
 		"buffer_strategy", "buffer_directory",
 		"collection_jitter", "collection_offset",
-		"data_format", "delay", "drop", "drop_original",
+		"data_format", "dead_letter_file", "dead_letter_output", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
@@ -1706,6 +1901,7 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
+		"route", "routes",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
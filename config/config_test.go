@@ -53,7 +53,7 @@ func TestReadBinaryFile(t *testing.T) {
 	require.NoErrorf(t, cmd.Run(), "stdout: %s, stderr: %s", outb.String(), errb.String())
 
 	c := config.NewConfig()
-	require.ErrorContains(t, c.LoadConfig(binaryFile), "provided config is not a TOML file")
+	require.ErrorContains(t, c.LoadConfig(binaryFile), "provided config is not a TOML, YAML or JSON file")
 }
 
 func TestConfig_LoadSingleInputWithEnvVars(t *testing.T) {
@@ -200,10 +200,100 @@ func TestConfig_LoadSingleInput_WithCommentInArray(t *testing.T) {
 	require.ElementsMatch(t, input.Servers, []string{"localhost"})
 }
 
+func TestConfig_LoadSingleInputYAML(t *testing.T) {
+	c := config.NewConfig()
+	t.Setenv("MY_TEST_SERVER", "192.168.1.1")
+	t.Setenv("TEST_INTERVAL", "10s")
+	confFile := filepath.Join("testdata", "single_plugin_env_vars.yaml")
+	require.NoError(t, c.LoadConfig(confFile))
+
+	input := inputs.Inputs["memcached"]().(*MockupInputPlugin)
+	input.Servers = []string{"192.168.1.1"}
+	input.Command = `Raw command which may or may not contain # in it
+# is unique`
+
+	filter := models.Filter{
+		NameDrop:     []string{"metricname2"},
+		NamePass:     []string{"metricname1", "ip_192.168.1.1_name"},
+		FieldExclude: []string{"other", "stuff"},
+		FieldInclude: []string{"some", "strings"},
+		TagDropFilters: []models.TagFilter{
+			{
+				Name:   "badtag",
+				Values: []string{"othertag"},
+			},
+		},
+		TagPassFilters: []models.TagFilter{
+			{
+				Name:   "goodtag",
+				Values: []string{"mytag", "tagwith#value", "TagWithMultilineSyntax"},
+			},
+		},
+	}
+	require.NoError(t, filter.Compile())
+	inputConfig := &models.InputConfig{
+		Name:     "memcached",
+		Source:   confFile,
+		Filter:   filter,
+		Interval: 10 * time.Second,
+	}
+	inputConfig.Tags = make(map[string]string)
+
+	// Ignore Log, Parser and ID
+	c.Inputs[0].Input.(*MockupInputPlugin).Log = nil
+	c.Inputs[0].Input.(*MockupInputPlugin).parser = nil
+	c.Inputs[0].Config.ID = ""
+	require.Equal(t, input, c.Inputs[0].Input, "Testdata did not produce a correct mockup struct.")
+	require.Equal(t, inputConfig, c.Inputs[0].Config, "Testdata did not produce correct input metadata.")
+}
+
+func TestConfig_LoadSingleInputJSON(t *testing.T) {
+	// The JSON configuration must result in the same plugin as the TOML one
+	expected := config.NewConfig()
+	require.NoError(t, expected.LoadConfig(filepath.Join("testdata", "single_plugin.toml")))
+
+	c := config.NewConfig()
+	confFile := filepath.Join("testdata", "single_plugin.json")
+	require.NoError(t, c.LoadConfig(confFile))
+	require.Len(t, c.Inputs, 1)
+
+	// Ignore Log, Parser and ID
+	for _, cfg := range []*config.Config{expected, c} {
+		cfg.Inputs[0].Input.(*MockupInputPlugin).Log = nil
+		cfg.Inputs[0].Input.(*MockupInputPlugin).parser = nil
+		cfg.Inputs[0].Config.ID = ""
+	}
+	expected.Inputs[0].Config.Source = confFile
+	require.Equal(t, expected.Inputs[0].Input, c.Inputs[0].Input)
+	require.Equal(t, expected.Inputs[0].Config, c.Inputs[0].Config)
+}
+
+func TestConfig_WalkDirectoryFormats(t *testing.T) {
+	dir := t.TempDir()
+	for _, fn := range []string{"a.conf", "b.yaml", "c.yml", "d.json", "e.toml", "f.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fn), nil, 0600))
+	}
+
+	// Only TOML files are loaded by default to not pick up unrelated files
+	files, err := config.WalkDirectory(dir, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a.conf")}, files)
+
+	files, err = config.WalkDirectory(dir, true)
+	require.NoError(t, err)
+	expected := []string{
+		filepath.Join(dir, "a.conf"),
+		filepath.Join(dir, "b.yaml"),
+		filepath.Join(dir, "c.yml"),
+		filepath.Join(dir, "d.json"),
+	}
+	require.Equal(t, expected, files)
+}
+
 func TestConfig_LoadDirectory(t *testing.T) {
 	c := config.NewConfig()
 
-	files, err := config.WalkDirectory("./testdata/subconfig")
+	files, err := config.WalkDirectory("./testdata/subconfig", false)
 	confFile := filepath.Join("testdata", "single_plugin.toml")
 	files = append([]string{confFile}, files...)
 	require.NoError(t, err)
@@ -390,6 +480,12 @@ func TestConfig_FieldNotDefined(t *testing.T) {
 			expected: "line 1: configuration specified the fields [\"not_a_field\"], but they were not used; " +
 				"this is either a typo or this config option does not exist in this version",
 		},
+		{
+			name:     "in input plugin without parser in YAML",
+			filename: "./testdata/invalid_field.yaml",
+			expected: "line 1: configuration specified the fields [\"not_a_field\"], but they were not used; " +
+				"this is either a typo or this config option does not exist in this version",
+		},
 		{
 			name:     "in input plugin with parser",
 			filename: "./testdata/invalid_field_with_parser.toml",
@@ -518,6 +614,32 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_BufferStrategyDiskAlias(t *testing.T) {
+	for strategy, expected := range map[string]string{
+		"":                   "",
+		"memory":             "memory",
+		"disk":               "disk_write_through",
+		"disk_write_through": "disk_write_through",
+		"disk_segmented":     "disk_segmented",
+		"hybrid":             "hybrid",
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			cfg := fmt.Sprintf(`
+[agent]
+  buffer_strategy = %q
+  buffer_directory = %q
+
+[[outputs.azure_monitor]]
+`, strategy, t.TempDir())
+
+			c := config.NewConfig()
+			require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+			require.Len(t, c.Outputs, 1)
+			require.Equal(t, expected, c.Outputs[0].Config.BufferStrategy)
+		})
+	}
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -531,7 +653,7 @@ func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 
 	c := config.NewConfig()
 	t.Setenv("TELEGRAF_CONFIG_PATH", ts.URL)
-	configPath, err := config.GetDefaultConfigPath()
+	configPath, err := config.GetDefaultConfigPath(false)
 	require.NoError(t, err)
 	require.Equal(t, []string{ts.URL}, configPath)
 	require.NoError(t, c.LoadConfig(configPath[0]))
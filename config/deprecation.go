@@ -169,6 +169,9 @@ func (c *Config) collectDeprecationInfo(category, name string, plugin interface{
 func (c *Config) printUserDeprecation(category, name string, plugin interface{}) error {
 	info := c.collectDeprecationInfo(category, name, plugin, false)
 	printPluginDeprecationNotice(info.logLevel, info.Name, info.info)
+	if c.linter != nil {
+		c.linter.deprecation(info)
+	}
 
 	if info.logLevel == telegraf.Error {
 		return errors.New("plugin deprecated")
@@ -371,6 +374,20 @@ func PrintOptionDeprecationNotice(plugin, option string, info telegraf.Deprecati
 	}
 }
 
+// printOptionDeprecation prints the deprecation notice of a generic plugin
+// option and records it when linting.
+func (c *Config) printOptionDeprecation(plugin, option string, info telegraf.DeprecationInfo) {
+	PrintOptionDeprecationNotice(plugin, option, info)
+	if c.linter == nil {
+		return
+	}
+
+	di := DeprecationInfo{Name: option, info: info}
+	if err := di.determineEscalation(); err == nil {
+		c.linter.optionDeprecation(option, di)
+	}
+}
+
 func PrintOptionValueDeprecationNotice(plugin, option string, value interface{}, info telegraf.DeprecationInfo) {
 	// Determine the log-level
 	di := &DeprecationInfo{
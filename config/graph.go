@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// GraphNode is a plugin in the metric pipeline.
+type GraphNode struct {
+	ID       string
+	Category string
+	Label    string
+}
+
+// GraphEdge is a path metrics take between two plugins. The label contains
+// the filters applied on this path.
+type GraphEdge struct {
+	From  string
+	To    string
+	Label []string
+}
+
+// Graph describes the metric pipeline built from the configuration.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// graphTail is the end of a partially built pipeline with the filters
+// applied when leaving the node.
+type graphTail struct {
+	id     string
+	labels []string
+}
+
+// Graph builds the metric pipeline of the configuration as it is started by
+// the agent: inputs, processors, aggregators, processors after aggregators and
+// outputs. Routed inputs pass through the processors of their route only and
+// reach the outputs subscribed to the route.
+func (c *Config) Graph() *Graph {
+	g := &Graph{}
+
+	outputs := make([]string, 0, len(c.Outputs))
+	for i, output := range c.Outputs {
+		outputs = append(outputs, g.addNode(fmt.Sprintf("output_%d", i), "outputs", output.LogName()))
+	}
+
+	skipAggProcessors := c.Agent.SkipProcessorsAfterAggregators != nil && *c.Agent.SkipProcessorsAfterAggregators
+	for _, route := range append([]string{""}, c.Routes()...) {
+		// Start with the inputs of the route
+		tails := make([]graphTail, 0)
+		for i, input := range c.Inputs {
+			if input.Config.Route != route {
+				continue
+			}
+			labels := filterLabels(&input.Config.Filter)
+			if route != "" {
+				labels = append([]string{"route: " + route}, labels...)
+			}
+			id := g.addNode(fmt.Sprintf("input_%d", i), "inputs", input.LogName())
+			tails = append(tails, graphTail{id: id, labels: labels})
+		}
+		if len(tails) == 0 {
+			continue
+		}
+
+		// Processors of the route in order
+		for i, processor := range c.Processors {
+			if processor.Config.Route != route {
+				continue
+			}
+			id := g.addNode(fmt.Sprintf("processor_%d", i), "processors", processorLabel(processor))
+			tails = g.connect(tails, id, filterLabels(&processor.Config.Filter))
+		}
+
+		// Aggregators only receive unrouted metrics and emit their result
+		// through the processors running after the aggregators. The original
+		// metrics bypass the aggregators.
+		if route == "" && len(c.Aggregators) > 0 {
+			aggTails := make([]graphTail, 0, len(c.Aggregators))
+			for i, aggregator := range c.Aggregators {
+				labels := filterLabels(&aggregator.Config.Filter)
+				if aggregator.Config.DropOriginal {
+					labels = append(labels, "drop_original")
+				}
+				id := g.addNode(fmt.Sprintf("aggregator_%d", i), "aggregators", aggregator.LogName())
+				aggTails = append(aggTails, g.connect(tails, id, labels)...)
+			}
+			if !skipAggProcessors {
+				for i, processor := range c.AggProcessors {
+					id := g.addNode(fmt.Sprintf("aggprocessor_%d", i), "processors", processorLabel(processor))
+					aggTails = g.connect(aggTails, id, filterLabels(&processor.Config.Filter))
+				}
+			}
+			tails = append(tails, aggTails...)
+		}
+
+		// Finally the outputs subscribed to the route
+		for i, output := range c.Outputs {
+			if output.SubscribesTo(route) {
+				g.connect(tails, outputs[i], filterLabels(&output.Config.Filter))
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *Graph) addNode(id, category, label string) string {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return id
+		}
+	}
+	g.Nodes = append(g.Nodes, GraphNode{ID: id, Category: category, Label: label})
+	return id
+}
+
+// connect adds edges from all tails to the given node and returns the node
+// as new tail.
+func (g *Graph) connect(tails []graphTail, id string, labels []string) []graphTail {
+	for _, tail := range tails {
+		edgeLabels := make([]string, 0, len(tail.labels)+len(labels))
+		edgeLabels = append(edgeLabels, tail.labels...)
+		edgeLabels = append(edgeLabels, labels...)
+		g.Edges = append(g.Edges, GraphEdge{From: tail.id, To: id, Label: edgeLabels})
+	}
+	return []graphTail{{id: id}}
+}
+
+func processorLabel(processor *models.RunningProcessor) string {
+	if processor.Config.Order == 0 {
+		return processor.LogName()
+	}
+	return fmt.Sprintf("%s (order %d)", processor.LogName(), processor.Config.Order)
+}
+
+// filterLabels returns a description of the metric selection filters.
+func filterLabels(f *models.Filter) []string {
+	labels := make([]string, 0)
+	if len(f.NamePass) > 0 {
+		labels = append(labels, "namepass: "+strings.Join(f.NamePass, ", "))
+	}
+	if len(f.NameDrop) > 0 {
+		labels = append(labels, "namedrop: "+strings.Join(f.NameDrop, ", "))
+	}
+	for _, tf := range sortedTagFilters(f.TagPassFilters) {
+		labels = append(labels, fmt.Sprintf("tagpass: %s=%s", tf.Name, strings.Join(tf.Values, "|")))
+	}
+	for _, tf := range sortedTagFilters(f.TagDropFilters) {
+		labels = append(labels, fmt.Sprintf("tagdrop: %s=%s", tf.Name, strings.Join(tf.Values, "|")))
+	}
+	if f.MetricPass != "" {
+		labels = append(labels, "metricpass: "+f.MetricPass)
+	}
+	return labels
+}
+
+func sortedTagFilters(filters []models.TagFilter) []models.TagFilter {
+	sorted := make([]models.TagFilter, len(filters))
+	copy(sorted, filters)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph telegraf {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, category := range []string{"inputs", "processors", "aggregators", "outputs"} {
+		for _, n := range g.Nodes {
+			if n.Category == category {
+				fmt.Fprintf(&b, "  %s [label=%s];\n", n.ID, dotQuote(n.Label))
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		if len(e.Label) == 0 {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", e.From, e.To, dotQuote(strings.Join(e.Label, "\n")))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, category := range []string{"inputs", "processors", "aggregators", "outputs"} {
+		for _, n := range g.Nodes {
+			if n.Category == category {
+				fmt.Fprintf(&b, "  %s[%s]\n", n.ID, mermaidQuote(n.Label))
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		if len(e.Label) == 0 {
+			fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", e.From, mermaidQuote(strings.Join(e.Label, "<br>")), e.To)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+func TestGraph(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadAll(filepath.Join("testdata", "graph.toml")))
+
+	expected := `digraph telegraf {
+  rankdir=LR;
+  node [shape=box];
+  input_0 [label="inputs.file"];
+  input_1 [label="inputs.file::cache"];
+  processor_1 [label="processors.processor (order 2)"];
+  aggprocessor_0 [label="processors.processor (order 2)"];
+  processor_0 [label="processors.processor::cache"];
+  aggregator_0 [label="aggregators.graphtest"];
+  output_0 [label="outputs.http"];
+  output_1 [label="outputs.http::cache"];
+  input_0 -> processor_1 [label="namepass: cpu, mem\ntagpass: host=a|b"];
+  processor_1 -> aggregator_0 [label="metricpass: name == \"cpu\"\ndrop_original"];
+  aggregator_0 -> aggprocessor_0 [label="tagpass: host=a|b"];
+  processor_1 -> output_0 [label="namedrop: mem"];
+  aggprocessor_0 -> output_0 [label="namedrop: mem"];
+  input_1 -> processor_0 [label="route: cache"];
+  processor_0 -> output_1;
+}
+`
+	var buf bytes.Buffer
+	require.NoError(t, c.Graph().WriteDOT(&buf))
+	require.Equal(t, expected, buf.String())
+
+	expected = `flowchart LR
+  input_0["inputs.file"]
+  input_1["inputs.file::cache"]
+  processor_1["processors.processor (order 2)"]
+  aggprocessor_0["processors.processor (order 2)"]
+  processor_0["processors.processor::cache"]
+  aggregator_0["aggregators.graphtest"]
+  output_0["outputs.http"]
+  output_1["outputs.http::cache"]
+  input_0 -->|"namepass: cpu, mem<br>tagpass: host=a|b"| processor_1
+  processor_1 -->|"metricpass: name == #quot;cpu#quot;<br>drop_original"| aggregator_0
+  aggregator_0 -->|"tagpass: host=a|b"| aggprocessor_0
+  processor_1 -->|"namedrop: mem"| output_0
+  aggprocessor_0 -->|"namedrop: mem"| output_0
+  input_1 -->|"route: cache"| processor_0
+  processor_0 --> output_1
+`
+	buf.Reset()
+	require.NoError(t, c.Graph().WriteMermaid(&buf))
+	require.Equal(t, expected, buf.String())
+}
+
+func TestGraphSkipProcessorsAfterAggregators(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "graph.toml"))
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte("skip_processors_after_aggregators = false"), []byte("skip_processors_after_aggregators = true"), 1)
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(data, EmptySourcePath))
+
+	g := c.Graph()
+	for _, n := range g.Nodes {
+		require.NotEqual(t, "aggprocessor_0", n.ID)
+	}
+	require.Contains(t, g.Edges, GraphEdge{From: "aggregator_0", To: "output_0", Label: []string{"namedrop: mem"}})
+}
+
+type MockupAggregatorPlugin struct{}
+
+func (*MockupAggregatorPlugin) SampleConfig() string {
+	return "Mockup test aggregator plugin"
+}
+
+func (*MockupAggregatorPlugin) Add(telegraf.Metric) {}
+
+func (*MockupAggregatorPlugin) Push(telegraf.Accumulator) {}
+
+func (*MockupAggregatorPlugin) Reset() {}
+
+func init() {
+	aggregators.Add("graphtest", func() telegraf.Aggregator {
+		return &MockupAggregatorPlugin{}
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
 	"github.com/stretchr/testify/require"
 )
 
@@ -340,7 +342,7 @@ func TestParseConfig(t *testing.T) {
 			if tt.setEnv != nil {
 				tt.setEnv(t)
 			}
-			tbl, err := parseConfig([]byte(tt.contents))
+			tbl, err := parseConfig([]byte(tt.contents), EmptySourcePath)
 			if tt.errmsg != "" {
 				require.ErrorContains(t, err, tt.errmsg)
 				return
@@ -354,6 +356,135 @@ func TestParseConfig(t *testing.T) {
 	}
 }
 
+func TestParseYAML(t *testing.T) {
+	yamlConfig := `
+agent:
+  interval: 10s
+  debug: true
+defaults: &defaults
+  timeout: 5
+inputs:
+  file:
+    - files: [a.txt, b.txt]
+      ratio: 1.5
+      count: 0x10
+      since: 2024-01-02T03:04:05Z
+      nested: [[1, 2], [3]]
+      unset: ~
+      options: *defaults
+    - files: []
+`
+	tomlConfig := `
+[agent]
+  interval = "10s"
+  debug = true
+[defaults]
+  timeout = 5
+[[inputs.file]]
+  files = ["a.txt", "b.txt"]
+  ratio = 1.5
+  count = 16
+  since = 2024-01-02T03:04:05Z
+  nested = [[1, 2], [3]]
+  [inputs.file.options]
+    timeout = 5
+[[inputs.file]]
+  files = []
+`
+	actualTbl, err := parseConfig([]byte(yamlConfig), "telegraf.yaml")
+	require.NoError(t, err)
+	expectedTbl, err := parseConfig([]byte(tomlConfig), "telegraf.conf")
+	require.NoError(t, err)
+
+	actual := make(map[string]interface{})
+	require.NoError(t, toml.UnmarshalTable(actualTbl, actual))
+	expected := make(map[string]interface{})
+	require.NoError(t, toml.UnmarshalTable(expectedTbl, expected))
+	require.Equal(t, expected, actual)
+
+	// The line numbers of the YAML file should be kept
+	inputsTbl := actualTbl.Fields["inputs"].(*ast.Table)
+	fileTbls := inputsTbl.Fields["file"].([]*ast.Table)
+	require.Len(t, fileTbls, 2)
+	require.Equal(t, 9, fileTbls[0].Line)
+	require.Equal(t, 10, fileTbls[0].Fields["ratio"].(*ast.KeyValue).Line)
+}
+
+// tomlSource parses the TOML source of a value to check the source is valid
+type tomlSource string
+
+func (s *tomlSource) UnmarshalTOML(data []byte) error {
+	var v struct {
+		Value string `toml:"value"`
+	}
+	if err := toml.Unmarshal(append([]byte("value = "), data...), &v); err != nil {
+		return err
+	}
+	*s = tomlSource(v.Value)
+	return nil
+}
+
+func TestParseYAMLStringEscaping(t *testing.T) {
+	// Go escape sequences like \a or \x1b are not valid in TOML strings
+	yamlConfig := `value: "bell\a escape\e delete\x7f quote\" backslash\\ tab\t newline\n unicode\u00e9"`
+
+	tbl, err := parseConfig([]byte(yamlConfig), "telegraf.yaml")
+	require.NoError(t, err)
+
+	var actual struct {
+		Value tomlSource `toml:"value"`
+	}
+	require.NoError(t, toml.UnmarshalTable(tbl, &actual))
+	expected := "bell\a escape\x1b delete\x7f quote\" backslash\\ tab\t newline\n unicode\u00e9"
+	require.Equal(t, expected, string(actual.Value))
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		errmsg   string
+	}{
+		{
+			name:     "no mapping",
+			contents: "- a\n- b\n",
+			errmsg:   "line 1: expected a mapping at the top level",
+		},
+		{
+			name:     "mixed sequence",
+			contents: "inputs:\n  file:\n    - a: 1\n    - b\n",
+			errmsg:   "line 3: mixing tables and values in a sequence is not supported",
+		},
+		{
+			name:     "merge key",
+			contents: "a: &a\n  x: 1\nb:\n  <<: *a\n",
+			errmsg:   "line 4: merge keys are not supported",
+		},
+		{
+			name:     "invalid syntax",
+			contents: "a: [1, 2\n",
+			errmsg:   "yaml:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tt.contents), "telegraf.yml")
+			require.ErrorContains(t, err, tt.errmsg)
+		})
+	}
+}
+
+func TestConfigFormat(t *testing.T) {
+	require.Equal(t, "toml", configFormat("telegraf.conf"))
+	require.Equal(t, "toml", configFormat(EmptySourcePath))
+	require.Equal(t, "yaml", configFormat("/etc/telegraf/telegraf.d/cpu.YAML"))
+	require.Equal(t, "yaml", configFormat("cpu.yml"))
+	require.Equal(t, "json", configFormat("cpu.json"))
+	require.Equal(t, "yaml", configFormat("https://example.com/configs/cpu.yaml?token=abc"))
+	require.Equal(t, "toml", configFormat("https://example.com/configs/cpu"))
+}
+
 func TestRemoveComments(t *testing.T) {
 	// Read expectation
 	expected, err := os.ReadFile(filepath.Join("testdata", "envvar_comments_expected.toml"))
@@ -0,0 +1,516 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Severities of lint findings
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// Rules checked when linting the configuration
+const (
+	LintRuleInvalidConfig      = "invalid-config"
+	LintRuleInvalidPlugin      = "invalid-plugin"
+	LintRuleUnknownOption      = "unknown-option"
+	LintRuleDeprecatedPlugin   = "deprecated-plugin"
+	LintRuleDeprecatedOption   = "deprecated-option"
+	LintRuleFilterNeverMatches = "filter-never-matches"
+	LintRuleUnreachableOutput  = "unreachable-output"
+	LintRuleDuplicateOrder     = "duplicate-order"
+	LintRuleInitFailed         = "init-failed"
+)
+
+// LintRules contains a short description for each rule checked when linting
+// the configuration.
+var LintRules = map[string]string{
+	LintRuleInvalidConfig:      "The configuration file cannot be loaded or parsed",
+	LintRuleInvalidPlugin:      "The plugin cannot be set up with the given settings",
+	LintRuleUnknownOption:      "The option is not known to the plugin",
+	LintRuleDeprecatedPlugin:   "The plugin is deprecated or removed",
+	LintRuleDeprecatedOption:   "The option is deprecated or removed",
+	LintRuleFilterNeverMatches: "The metric filters of the plugin can never match",
+	LintRuleUnreachableOutput:  "No metric can reach the output",
+	LintRuleDuplicateOrder:     "Multiple processors share the same order",
+	LintRuleInitFailed:         "The plugins cannot be initialized",
+}
+
+// LintFinding describes a problem found in the configuration.
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Plugin   string `json:"plugin,omitempty"`
+	PluginID string `json:"plugin_id,omitempty"`
+}
+
+// Lint loads the given configuration files and checks them for problems
+// without initializing the plugins. In contrast to LoadAll, all problems are
+// collected instead of stopping at the first error. The findings are sorted
+// by file and line.
+func Lint(files ...string) []LintFinding {
+	l := &linter{cfg: NewConfig()}
+	l.cfg.linter = l
+
+	for _, fn := range files {
+		l.file(fn)
+	}
+	l.checkProcessorOrder()
+	l.checkUnreachableOutputs()
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		if l.findings[i].File != l.findings[j].File {
+			return l.findings[i].File < l.findings[j].File
+		}
+		return l.findings[i].Line < l.findings[j].Line
+	})
+	return l.findings
+}
+
+// lintLocation is the location of a plugin in the configuration
+type lintLocation struct {
+	file     string
+	table    *ast.Table
+	plugin   string
+	pluginID string
+}
+
+func (loc *lintLocation) line(option string) int {
+	if loc.table == nil {
+		return 0
+	}
+	if kv, ok := loc.table.Fields[option].(*ast.KeyValue); ok {
+		return kv.Line
+	}
+	return loc.table.Line
+}
+
+type lintProcessor struct {
+	lintLocation
+	processor *models.RunningProcessor
+}
+
+type lintOutput struct {
+	lintLocation
+	output *models.RunningOutput
+}
+
+type linter struct {
+	cfg      *Config
+	findings []LintFinding
+
+	// Location of the plugin currently loaded
+	current *lintLocation
+
+	processors []lintProcessor
+	outputs    []lintOutput
+}
+
+func (l *linter) add(loc *lintLocation, rule, severity, option, msg string) {
+	finding := LintFinding{
+		Rule:     rule,
+		Severity: severity,
+		Message:  msg,
+	}
+	if loc != nil {
+		finding.File = loc.file
+		finding.Line = loc.line(option)
+		finding.Plugin = loc.plugin
+		finding.PluginID = loc.pluginID
+	}
+
+	// Processors are set up twice, so skip identical findings
+	for _, f := range l.findings {
+		if f == finding {
+			return
+		}
+	}
+	l.findings = append(l.findings, finding)
+}
+
+func (l *linter) file(fn string) {
+	loc := &lintLocation{file: fn}
+
+	data, _, err := LoadConfigFile(fn)
+	if err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", err.Error())
+		return
+	}
+	tbl, err := parseConfig(data, fn)
+	if err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", fmt.Sprintf("error parsing data: %v", err))
+		return
+	}
+
+	load := func(include string) error {
+		l.file(include)
+		return nil
+	}
+	if err := l.cfg.loadIncludes(tbl, fn, load); err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", err.Error())
+	}
+	if err := l.cfg.addTemplates(tbl); err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", err.Error())
+	}
+	if err := l.cfg.applyTemplates(tbl); err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", err.Error())
+	}
+	if err := l.cfg.applyConditions(tbl); err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", err.Error())
+	}
+
+	for _, tableName := range []string{"tags", "global_tags"} {
+		if val, ok := tbl.Fields[tableName]; ok {
+			subTable, ok := val.(*ast.Table)
+			if !ok {
+				l.add(loc, LintRuleInvalidConfig, LintError, "", fmt.Sprintf("invalid configuration, bad table name %q", tableName))
+				continue
+			}
+			if err := l.cfg.toml.UnmarshalTable(subTable, l.cfg.Tags); err != nil {
+				l.add(loc, LintRuleInvalidConfig, LintError, "", fmt.Sprintf("error parsing table name %q: %v", tableName, err))
+			}
+		}
+	}
+
+	if val, ok := tbl.Fields["agent"]; ok {
+		if subTable, ok := val.(*ast.Table); ok {
+			l.agent(fn, subTable)
+		} else {
+			l.add(loc, LintRuleInvalidConfig, LintError, "", "invalid configuration, error parsing agent table")
+		}
+	}
+
+	for name, val := range tbl.Fields {
+		if name == "include" || name == "templates" {
+			continue
+		}
+
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			l.add(loc, LintRuleInvalidConfig, LintError, "", fmt.Sprintf("invalid configuration, error parsing field %q as table", name))
+			continue
+		}
+
+		switch name {
+		case "agent", "global_tags", "tags":
+		case "inputs", "plugins", "outputs", "processors", "aggregators", "secretstores":
+			category := name
+			if category == "plugins" {
+				category = "inputs"
+			}
+			for pluginName, pluginVal := range subTable.Fields {
+				switch pluginSubTable := pluginVal.(type) {
+				case *ast.Table:
+					// Legacy single-table format only exists for inputs and
+					// outputs
+					if category != "inputs" && category != "outputs" {
+						l.add(loc, LintRuleInvalidConfig, LintError, "", "unsupported config format: "+pluginName)
+						continue
+					}
+					l.plugin(fn, category, pluginName, pluginSubTable)
+				case []*ast.Table:
+					for _, t := range pluginSubTable {
+						l.plugin(fn, category, pluginName, t)
+					}
+				default:
+					l.add(loc, LintRuleInvalidConfig, LintError, "", "unsupported config format: "+pluginName)
+				}
+			}
+		default:
+			// Assume it's an input for legacy config file support
+			l.plugin(fn, "inputs", name, subTable)
+		}
+	}
+}
+
+func (l *linter) agent(fn string, tbl *ast.Table) {
+	loc := &lintLocation{file: fn, table: tbl, plugin: "agent"}
+
+	l.cfg.UnusedFields = make(map[string]bool)
+	if err := l.cfg.toml.UnmarshalTable(tbl, l.cfg.Agent); err != nil {
+		l.add(loc, LintRuleInvalidConfig, LintError, "", fmt.Sprintf("error parsing [agent]: %v", err))
+	}
+	l.unusedFields(loc)
+}
+
+func (l *linter) plugin(fn, category, name string, tbl *ast.Table) {
+	loc := &lintLocation{
+		file:   fn,
+		table:  tbl,
+		plugin: category + "." + name,
+	}
+	if category != "secretstores" {
+		if id, err := generatePluginID(loc.plugin, tbl); err == nil {
+			loc.pluginID = id
+		}
+	}
+
+	// Report removed plugins as deprecated instead of unknown
+	if info, removed := removedPlugin(category, name); removed {
+		msg := fmt.Sprintf("plugin deprecated since version %s and removed: %s", info.Since, info.Notice)
+		l.add(loc, LintRuleDeprecatedPlugin, LintError, "", msg)
+		return
+	}
+
+	// Reset the state of the previous plugin
+	l.cfg.UnusedFields = make(map[string]bool)
+	l.cfg.errs = nil
+	l.current = loc
+	defer func() { l.current = nil }()
+
+	var err error
+	switch category {
+	case "inputs":
+		n := len(l.cfg.Inputs)
+		if err = l.cfg.addInput(name, fn, tbl); err == nil && len(l.cfg.Inputs) > n {
+			l.checkFilter(loc, &l.cfg.Inputs[n].Config.Filter)
+		}
+	case "outputs":
+		n := len(l.cfg.Outputs)
+		if err = l.cfg.addOutput(name, fn, tbl); err == nil && len(l.cfg.Outputs) > n {
+			output := l.cfg.Outputs[n]
+			l.outputs = append(l.outputs, lintOutput{lintLocation: *loc, output: output})
+			l.checkFilter(loc, &output.Config.Filter)
+		}
+	case "processors":
+		n := len(l.cfg.fileProcessors)
+		if err = l.cfg.addProcessor(name, fn, tbl); err == nil && len(l.cfg.fileProcessors) > n {
+			processor := l.cfg.fileProcessors[n].plugin.(*models.RunningProcessor)
+			l.processors = append(l.processors, lintProcessor{lintLocation: *loc, processor: processor})
+			l.checkFilter(loc, &processor.Config.Filter)
+		}
+	case "aggregators":
+		n := len(l.cfg.Aggregators)
+		if err = l.cfg.addAggregator(name, fn, tbl); err == nil && len(l.cfg.Aggregators) > n {
+			l.checkFilter(loc, &l.cfg.Aggregators[n].Config.Filter)
+		}
+	case "secretstores":
+		err = l.cfg.addSecretStore(name, fn, tbl)
+	}
+
+	// Errors caused by deprecations are already reported
+	if err != nil && !l.hasError(loc, LintRuleDeprecatedPlugin, LintRuleDeprecatedOption) {
+		l.add(loc, LintRuleInvalidPlugin, LintError, "", err.Error())
+	}
+	l.unusedFields(loc)
+}
+
+func (l *linter) hasError(loc *lintLocation, rules ...string) bool {
+	for _, f := range l.findings {
+		if f.Severity == LintError && f.File == loc.file && f.Plugin == loc.plugin && f.PluginID == loc.pluginID {
+			for _, rule := range rules {
+				if f.Rule == rule {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (l *linter) unusedFields(loc *lintLocation) {
+	fields := keys(l.cfg.UnusedFields)
+	sort.Strings(fields)
+	for _, field := range fields {
+		msg := fmt.Sprintf(
+			"configuration specified the field %q, but it was not used; "+
+				"this is either a typo or this config option does not exist in this version",
+			field,
+		)
+		l.add(loc, LintRuleUnknownOption, LintError, field, msg)
+	}
+}
+
+// deprecation records the deprecation notices of the plugin currently loaded.
+func (l *linter) deprecation(info PluginDeprecationInfo) {
+	if info.logLevel != telegraf.None {
+		msg := fmt.Sprintf(
+			"plugin deprecated since version %s and will be removed in %s: %s",
+			info.info.Since, info.info.RemovalIn, info.info.Notice,
+		)
+		l.add(l.current, LintRuleDeprecatedPlugin, lintSeverity(info.logLevel), "", msg)
+	}
+	for _, option := range info.Options {
+		l.optionDeprecation(option.Name, option)
+	}
+}
+
+// optionDeprecation records the deprecation notice of the given option of the
+// plugin currently loaded.
+func (l *linter) optionDeprecation(option string, info DeprecationInfo) {
+	if info.logLevel == telegraf.None {
+		return
+	}
+	msg := fmt.Sprintf(
+		"option %q deprecated since version %s and will be removed in %s: %s",
+		option, info.info.Since, info.info.RemovalIn, info.info.Notice,
+	)
+	l.add(l.current, LintRuleDeprecatedOption, lintSeverity(info.logLevel), option, msg)
+}
+
+func lintSeverity(level telegraf.LogLevel) string {
+	if level == telegraf.Error {
+		return LintError
+	}
+	return LintWarning
+}
+
+func removedPlugin(category, name string) (telegraf.DeprecationInfo, bool) {
+	var registered bool
+	var info telegraf.DeprecationInfo
+	var deprecated bool
+	switch category {
+	case "inputs":
+		_, registered = inputs.Inputs[name]
+		info, deprecated = inputs.Deprecations[name]
+	case "outputs":
+		_, registered = outputs.Outputs[name]
+		info, deprecated = outputs.Deprecations[name]
+	case "processors":
+		_, registered = processors.Processors[name]
+		info, deprecated = processors.Deprecations[name]
+	case "aggregators":
+		_, registered = aggregators.Aggregators[name]
+		info, deprecated = aggregators.Deprecations[name]
+	case "secretstores":
+		_, registered = secretstores.SecretStores[name]
+		info, deprecated = secretstores.Deprecations[name]
+	}
+	return info, !registered && deprecated
+}
+
+// checkFilter reports filters which can never select a metric.
+func (l *linter) checkFilter(loc *lintLocation, f *models.Filter) {
+	if len(f.NamePass) > 0 && len(f.NameDrop) > 0 {
+		drop, err := filter.Compile(f.NameDrop, []rune(f.NameDropSeparators)...)
+		if err == nil && drop != nil && matchesAll(drop, f.NamePass) {
+			l.add(loc, LintRuleFilterNeverMatches, LintWarning, "namepass", "all names passed by 'namepass' are dropped by 'namedrop'")
+		}
+	}
+
+	if len(f.FieldInclude) > 0 && len(f.FieldExclude) > 0 {
+		exclude, err := filter.Compile(f.FieldExclude)
+		if err == nil && exclude != nil && matchesAll(exclude, f.FieldInclude) {
+			l.add(loc, LintRuleFilterNeverMatches, LintWarning, "fieldinclude", "all fields included by 'fieldinclude' are removed by 'fieldexclude'")
+		}
+	}
+
+	if len(f.TagPassFilters) > 0 && len(f.TagDropFilters) > 0 {
+		never := true
+		for _, pass := range f.TagPassFilters {
+			dropped := false
+			for _, drop := range f.TagDropFilters {
+				if drop.Name != pass.Name {
+					continue
+				}
+				dropFilter, err := filter.Compile(drop.Values)
+				if err == nil && dropFilter != nil && matchesAll(dropFilter, pass.Values) {
+					dropped = true
+					break
+				}
+			}
+			if !dropped {
+				never = false
+				break
+			}
+		}
+		if never {
+			l.add(loc, LintRuleFilterNeverMatches, LintWarning, "tagpass", "all tags passed by 'tagpass' are dropped by 'tagdrop'")
+		}
+	}
+
+	if strings.TrimSpace(f.MetricPass) == "false" {
+		l.add(loc, LintRuleFilterNeverMatches, LintWarning, "metricpass", "'metricpass' is always false")
+	}
+}
+
+// matchesAll returns true if all patterns are matched by the filter. As the
+// patterns itself are matched, a filter matching all patterns will also match
+// everything matched by the patterns.
+func matchesAll(f filter.Filter, patterns []string) bool {
+	for _, p := range patterns {
+		if !f.Match(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkProcessorOrder reports processors of the same route sharing the same
+// order as their sequence is then only determined by the position in the
+// configuration.
+func (l *linter) checkProcessorOrder() {
+	type key struct {
+		route string
+		order int64
+	}
+	groups := make(map[key][]lintProcessor)
+	for _, p := range l.processors {
+		if p.processor.Config.Order == 0 {
+			continue
+		}
+		k := key{route: p.processor.Config.Route, order: p.processor.Config.Order}
+		groups[k] = append(groups[k], p)
+	}
+
+	for k, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		for i, p := range group {
+			others := make([]string, 0, len(group)-1)
+			for j, other := range group {
+				if i != j {
+					others = append(others, fmt.Sprintf("%s (%s:%d)", other.plugin, other.file, other.table.Line))
+				}
+			}
+			msg := fmt.Sprintf("order %d is also used by %s", k.order, strings.Join(others, ", "))
+			l.add(&p.lintLocation, LintRuleDuplicateOrder, LintWarning, "order", msg)
+		}
+	}
+}
+
+// checkUnreachableOutputs reports outputs not subscribed to any route with
+// metrics. Unrouted metrics originate from inputs without route, routed
+// metrics from the inputs of the route.
+func (l *linter) checkUnreachableOutputs() {
+	sources := make(map[string]bool)
+	for _, input := range l.cfg.Inputs {
+		sources[input.Config.Route] = true
+	}
+
+	for _, o := range l.outputs {
+		if len(o.output.Config.Routes) == 0 {
+			if !sources[""] {
+				l.add(&o.lintLocation, LintRuleUnreachableOutput, LintWarning, "", "no input without route exists")
+			}
+			continue
+		}
+
+		reachable := false
+		for _, route := range o.output.Config.Routes {
+			reachable = reachable || sources[route]
+		}
+		if !reachable {
+			msg := fmt.Sprintf("no input sends metrics to the routes %q", o.output.Config.Routes)
+			l.add(&o.lintLocation, LintRuleUnreachableOutput, LintWarning, "routes", msg)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	// Fake telegraf's version to get deprecation warnings
+	previous := telegrafVersion
+	telegrafVersion = semver.New("1.30.0")
+	defer func() { telegrafVersion = previous }()
+
+	inputsFile := filepath.Join("testdata", "lint", "inputs.toml")
+	outputsFile := filepath.Join("testdata", "lint", "outputs.toml")
+
+	type finding struct {
+		rule     string
+		severity string
+		file     string
+		line     int
+		plugin   string
+	}
+	expected := []finding{
+		{LintRuleUnknownOption, LintError, inputsFile, 3, "inputs.file"},
+		{LintRuleDeprecatedOption, LintWarning, inputsFile, 4, "inputs.file"},
+		{LintRuleFilterNeverMatches, LintWarning, inputsFile, 8, "inputs.memcached"},
+		{LintRuleFilterNeverMatches, LintWarning, inputsFile, 11, "inputs.exec"},
+		{LintRuleInvalidPlugin, LintError, inputsFile, 16, "inputs.not_a_plugin"},
+		{LintRuleDuplicateOrder, LintWarning, outputsFile, 2, "processors.processor"},
+		{LintRuleDuplicateOrder, LintWarning, outputsFile, 5, "processors.processor"},
+		{LintRuleFilterNeverMatches, LintWarning, outputsFile, 13, "outputs.http"},
+		{LintRuleUnreachableOutput, LintWarning, outputsFile, 20, "outputs.http"},
+		{LintRuleFilterNeverMatches, LintWarning, outputsFile, 21, "outputs.http"},
+	}
+
+	findings := Lint(inputsFile, outputsFile)
+	actual := make([]finding, 0, len(findings))
+	for _, f := range findings {
+		actual = append(actual, finding{f.Rule, f.Severity, f.File, f.Line, f.Plugin})
+		require.NotEmpty(t, f.Message)
+		require.NotEmpty(t, f.PluginID, "no plugin ID in %+v", f)
+		require.Contains(t, LintRules, f.Rule)
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestLintInvalidFile(t *testing.T) {
+	findings := Lint(filepath.Join("testdata", "lint", "non_existing.toml"))
+	require.Len(t, findings, 1)
+	require.Equal(t, LintRuleInvalidConfig, findings[0].Rule)
+	require.Equal(t, LintError, findings[0].Severity)
+}
+
+func TestLintEnableIf(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "telegraf.toml")
+	cfg := `
+[[inputs.file]]
+  enable_if = "os == 'plan9'"
+  not_an_option = true
+
+[[inputs.file]]
+  enable_if = "os =="
+`
+	require.NoError(t, os.WriteFile(fn, []byte(cfg), 0600))
+
+	// Plugins must be linted independent of their condition
+	findings := Lint(fn)
+	require.Len(t, findings, 2)
+	require.Equal(t, LintRuleInvalidConfig, findings[0].Rule)
+	require.Contains(t, findings[0].Message, "invalid 'enable_if' option")
+	require.Equal(t, LintRuleUnknownOption, findings[1].Rule)
+	require.Equal(t, 4, findings[1].Line)
+}
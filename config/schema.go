@@ -0,0 +1,577 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/toml"
+	stringutil "github.com/naoina/go-stringutil"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// SchemaDraft is the JSON Schema dialect of the generated schemas
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaCategories are the plugin categories schemas can be generated for
+var SchemaCategories = []string{"inputs", "outputs", "processors", "aggregators", "secretstores", "parsers", "serializers"}
+
+// Patterns for validating the string representation of durations and sizes
+const (
+	durationPattern = `^(-?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h|d))*$`
+	sizePattern     = `^[0-9]+(\.[0-9]+)?\s*([KkMGTPE]i?)?B?$`
+)
+
+// Schema is a (subset of a) JSON Schema describing a configuration
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	durationType        = reflect.TypeOf(Duration(0))
+	sizeType            = reflect.TypeOf(Size(0))
+	secretType          = reflect.TypeOf(Secret{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	tomlUnmarshalerType = reflect.TypeOf((*toml.Unmarshaler)(nil)).Elem()
+)
+
+// PluginSchema returns the JSON Schema for the configuration of the plugin
+// with the given category and name. The schema is derived from the toml tags
+// and types of the plugin struct, the defaults are taken from a newly created
+// plugin instance. Options common to all plugins of the category, such as
+// filters, are included.
+func PluginSchema(category, name string) (*Schema, error) {
+	s, err := pluginSchema(category, name)
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = SchemaDraft
+	return s, nil
+}
+
+// SchemaBundle returns a JSON Schema for a complete configuration including
+// the agent settings and all available plugins. The plugin schemas are
+// contained in the "$defs" section with "<category>.<name>" as key.
+func SchemaBundle() (*Schema, error) {
+	agentSchema := (&schemaBuilder{}).object(reflect.ValueOf(NewConfig().Agent).Elem())
+	bundle := &Schema{
+		Schema: SchemaDraft,
+		Title:  "Telegraf configuration",
+		Type:   "object",
+		Properties: map[string]*Schema{
+			"agent":       agentSchema,
+			"global_tags": stringMapSchema("tags added to all metrics"),
+			"include": {
+				Type:        []string{"string", "array"},
+				Items:       &Schema{Type: "string"},
+				Description: "files or glob patterns of files to include",
+			},
+			"templates": {
+				Type:                 "object",
+				AdditionalProperties: &Schema{Type: "object"},
+				Description:          "plugin settings referenced via 'inherit'",
+			},
+		},
+		AdditionalProperties: false,
+		Defs:                 make(map[string]*Schema),
+	}
+
+	for _, category := range SchemaCategories {
+		names := schemaPluginNames(category)
+		tables := &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema, len(names)),
+			AdditionalProperties: false,
+		}
+		for _, name := range names {
+			s, err := pluginSchema(category, name)
+			if err != nil {
+				return nil, err
+			}
+			id := category + "." + name
+			bundle.Defs[id] = s
+			tables.Properties[name] = &Schema{
+				Type:  "array",
+				Items: &Schema{Ref: "#/$defs/" + id},
+			}
+		}
+
+		// Parsers and serializers are configured within other plugins
+		if category != "parsers" && category != "serializers" {
+			bundle.Properties[category] = tables
+		}
+	}
+
+	return bundle, nil
+}
+
+func schemaPluginNames(category string) []string {
+	var names []string
+	switch category {
+	case "inputs":
+		for name := range inputs.Inputs {
+			names = append(names, name)
+		}
+	case "outputs":
+		for name := range outputs.Outputs {
+			names = append(names, name)
+		}
+	case "processors":
+		for name := range processors.Processors {
+			names = append(names, name)
+		}
+	case "aggregators":
+		for name := range aggregators.Aggregators {
+			names = append(names, name)
+		}
+	case "secretstores":
+		for name := range secretstores.SecretStores {
+			names = append(names, name)
+		}
+	case "parsers":
+		for name := range parsers.Parsers {
+			names = append(names, name)
+		}
+	case "serializers":
+		for name := range serializers.Serializers {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func pluginSchema(category, name string) (*Schema, error) {
+	var plugin interface{}
+	var info telegraf.DeprecationInfo
+	var deprecated bool
+	switch category {
+	case "inputs":
+		if creator, ok := inputs.Inputs[name]; ok {
+			plugin = creator()
+		}
+		info, deprecated = inputs.Deprecations[name]
+	case "outputs":
+		if creator, ok := outputs.Outputs[name]; ok {
+			plugin = creator()
+		}
+		info, deprecated = outputs.Deprecations[name]
+	case "processors":
+		if creator, ok := processors.Processors[name]; ok {
+			p := creator()
+			if u, ok := p.(processors.HasUnwrap); ok {
+				plugin = u.Unwrap()
+			} else {
+				plugin = p
+			}
+		}
+		info, deprecated = processors.Deprecations[name]
+	case "aggregators":
+		if creator, ok := aggregators.Aggregators[name]; ok {
+			plugin = creator()
+		}
+		info, deprecated = aggregators.Deprecations[name]
+	case "secretstores":
+		if creator, ok := secretstores.SecretStores[name]; ok {
+			plugin = creator("")
+		}
+		info, deprecated = secretstores.Deprecations[name]
+	case "parsers":
+		if creator, ok := parsers.Parsers[name]; ok {
+			plugin = creator("")
+		}
+		info, deprecated = parsers.Deprecations[name]
+	case "serializers":
+		if creator, ok := serializers.Serializers[name]; ok {
+			plugin = creator()
+		}
+		info, deprecated = serializers.Deprecations[name]
+	default:
+		return nil, fmt.Errorf("unknown plugin category %q", category)
+	}
+	if plugin == nil {
+		return nil, fmt.Errorf("undefined but requested %s plugin: %s", strings.TrimSuffix(category, "s"), name)
+	}
+
+	v := reflect.ValueOf(plugin)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("plugin %s.%s is not a struct", category, name)
+	}
+
+	s := (&schemaBuilder{}).object(v)
+	s.Title = category + "." + name
+	if deprecated {
+		s.Deprecated = true
+		s.Description = fmt.Sprintf("deprecated since %s: %s", info.Since, info.Notice)
+	}
+
+	// Add the options common to all plugins of the category
+	for key, option := range commonSchemaOptions(category) {
+		if _, found := s.Properties[key]; !found {
+			s.Properties[key] = option
+		}
+	}
+	if category == "secretstores" {
+		s.Required = append(s.Required, "id")
+	}
+
+	// Plugins using parsers or serializers accept the options of the plugin
+	// selected via 'data_format' which cannot be determined statically.
+	switch plugin.(type) {
+	case telegraf.ParserPlugin, telegraf.ParserFuncPlugin:
+		s.Properties["data_format"] = enumSchema("data format of the parser", "influx", schemaPluginNames("parsers"))
+		s.AdditionalProperties = true
+	case telegraf.SerializerPlugin, telegraf.SerializerFuncPlugin:
+		s.Properties["data_format"] = enumSchema("data format of the serializer", "influx", schemaPluginNames("serializers"))
+		s.AdditionalProperties = true
+	}
+
+	return s, nil
+}
+
+// commonSchemaOptions returns the options handled by the configuration for
+// all plugins of the given category
+func commonSchemaOptions(category string) map[string]*Schema {
+	filters := map[string]*Schema{
+		"alias":              {Type: "string", Description: "name of the plugin instance used in logs"},
+		"log_level":          enumSchema("log level of the plugin instance", nil, []string{"error", "warn", "info", "debug", "trace"}),
+		"namepass":           stringSliceSchema("metric names to pass"),
+		"namepass_separator": {Type: "string", Description: "separators for the namepass patterns"},
+		"namedrop":           stringSliceSchema("metric names to drop"),
+		"namedrop_separator": {Type: "string", Description: "separators for the namedrop patterns"},
+		"fieldinclude":       stringSliceSchema("field keys to keep"),
+		"fieldexclude":       stringSliceSchema("field keys to remove"),
+		"fieldpass":          {Type: "array", Items: &Schema{Type: "string"}, Deprecated: true, Description: "use 'fieldinclude' instead"},
+		"fielddrop":          {Type: "array", Items: &Schema{Type: "string"}, Deprecated: true, Description: "use 'fieldexclude' instead"},
+		"tagpass":            tagFilterSchema("tag values to pass"),
+		"tagdrop":            tagFilterSchema("tag values to drop"),
+		"taginclude":         stringSliceSchema("tag keys to keep"),
+		"tagexclude":         stringSliceSchema("tag keys to remove"),
+		"metricpass":         {Type: "string", Description: "CEL expression for metrics to pass"},
+	}
+	naming := map[string]*Schema{
+		"name_override": {Type: "string", Description: "override for the metric name"},
+		"name_prefix":   {Type: "string", Description: "prefix for the metric name"},
+		"name_suffix":   {Type: "string", Description: "suffix for the metric name"},
+	}
+
+	options := make(map[string]*Schema)
+	if category != "parsers" && category != "serializers" {
+		options["inherit"] = &Schema{
+			Type:        []string{"string", "array"},
+			Items:       &Schema{Type: "string"},
+			Description: "templates to inherit settings from",
+		}
+		options["enable_if"] = &Schema{Type: "string", Description: "CEL expression on host facts enabling the plugin"}
+	}
+	switch category {
+	case "inputs":
+		maps.Copy(options, filters)
+		maps.Copy(options, naming)
+		options["interval"] = durationSchema("gathering interval")
+		options["precision"] = durationSchema("precision of the metric timestamps")
+		options["collection_jitter"] = durationSchema("random delay before gathering")
+		options["collection_offset"] = durationSchema("offset of the gathering within the interval")
+		options["startup_error_behavior"] = enumSchema("behavior on startup errors", "error", []string{"error", "retry", "ignore", "probe"})
+		options["time_source"] = enumSchema("time used for the metric timestamps", "metric", []string{"metric", "collection_start", "collection_end"})
+		options["tags"] = stringMapSchema("tags added to the metrics")
+		options["route"] = &Schema{Type: "string", Description: "route of the metrics"}
+	case "outputs":
+		maps.Copy(options, filters)
+		maps.Copy(options, naming)
+		options["flush_interval"] = durationSchema("flushing interval")
+		options["flush_jitter"] = durationSchema("random delay of flushes")
+		options["metric_batch_size"] = &Schema{Type: "integer", Minimum: new(float64), Description: "maximum number of metrics per write"}
+		options["metric_buffer_limit"] = &Schema{Type: "integer", Minimum: new(float64), Description: "maximum number of buffered metrics"}
+		options["startup_error_behavior"] = enumSchema("behavior on startup errors", "error", []string{"error", "retry", "ignore"})
+		options["dead_letter_file"] = &Schema{Type: "string", Description: "file to append metrics rejected by the output to"}
+		options["dead_letter_output"] = &Schema{Type: "string", Description: "alias of the output receiving metrics rejected by the output"}
+		options["routes"] = stringSliceSchema("routes to receive metrics from")
+	case "processors":
+		maps.Copy(options, filters)
+		options["order"] = &Schema{Type: "integer", Description: "execution order of the processor"}
+		options["route"] = &Schema{Type: "string", Description: "route of the processor"}
+	case "aggregators":
+		maps.Copy(options, filters)
+		maps.Copy(options, naming)
+		options["period"] = durationSchema("aggregation period")
+		options["delay"] = durationSchema("delay before pushing the aggregates")
+		options["grace"] = durationSchema("duration for accepting metrics outside the period")
+		options["drop_original"] = &Schema{Type: "boolean", Description: "drop the original metrics"}
+		options["tags"] = stringMapSchema("tags added to the aggregated metrics")
+	case "secretstores":
+		options["id"] = &Schema{Type: "string", Pattern: `^\w+$`, Description: "identifier of the secret-store"}
+	}
+	return options
+}
+
+// schemaBuilder derives schemas from struct types via reflection
+type schemaBuilder struct {
+	// visiting contains the struct types currently being processed to
+	// avoid infinite recursion on recursive types
+	visiting []reflect.Type
+}
+
+// object returns the schema of the given struct value including the non-zero
+// values as defaults
+func (b *schemaBuilder) object(v reflect.Value) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+
+	if slices.Contains(b.visiting, v.Type()) {
+		s.AdditionalProperties = true
+		return s
+	}
+	b.visiting = append(b.visiting, v.Type())
+	defer func() { b.visiting = b.visiting[:len(b.visiting)-1] }()
+
+	b.addFields(s, v)
+	return s
+}
+
+// addFields adds the fields of the struct value to the schema following the
+// field resolution of the TOML decoder
+func (b *schemaBuilder) addFields(s *Schema, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		ft := t.Field(i)
+		if !ft.IsExported() && !ft.Anonymous {
+			continue
+		}
+
+		key, _, _ := strings.Cut(ft.Tag.Get("toml"), ",")
+		key = strings.TrimSpace(key)
+		if key == "-" {
+			continue
+		}
+		if ft.Anonymous && ft.Type.Kind() == reflect.Struct && key == "" {
+			b.addFields(s, v.Field(i))
+			continue
+		}
+		if !ft.IsExported() {
+			continue
+		}
+		if key == "" {
+			key = stringutil.ToSnakeCase(ft.Name)
+		}
+
+		field := b.value(v.Field(i))
+		if field == nil {
+			continue
+		}
+		if tag, found := ft.Tag.Lookup("deprecated"); found {
+			field.Deprecated = true
+			if parts := strings.Split(tag, ";"); len(parts) > 1 {
+				field.Description = parts[len(parts)-1]
+			}
+		}
+		s.Properties[key] = field
+	}
+}
+
+// value returns the schema for the given value or nil if the value cannot be
+// set via the configuration
+func (b *schemaBuilder) value(v reflect.Value) *Schema {
+	t := v.Type()
+	switch t {
+	case durationType:
+		s := durationSchema("")
+		if d := v.Int(); d != 0 {
+			s.Default = time.Duration(d).String()
+		}
+		return s
+	case sizeType:
+		s := &Schema{Type: []string{"string", "integer"}, Pattern: sizePattern}
+		if size := v.Int(); size != 0 {
+			s.Default = size
+		}
+		return s
+	case secretType:
+		// Never expose the default of secrets
+		return &Schema{Type: "string", Description: "secret, can reference secret-stores using '@{<store id>:<key>}'"}
+	}
+
+	if reflect.PointerTo(t).Implements(tomlUnmarshalerType) {
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		s := &Schema{Type: "string"}
+		if t.Kind() == reflect.String && v.String() != "" {
+			s.Default = v.String()
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s := &Schema{Type: "boolean"}
+		if v.Bool() {
+			s.Default = true
+		}
+		return s
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := &Schema{Type: "integer"}
+		if v.Int() != 0 {
+			s.Default = v.Int()
+		}
+		return s
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := &Schema{Type: "integer", Minimum: new(float64)}
+		if v.Uint() != 0 {
+			s.Default = v.Uint()
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		s := &Schema{Type: "number"}
+		if v.Float() != 0 {
+			s.Default = v.Float()
+		}
+		return s
+	case reflect.String:
+		s := &Schema{Type: "string"}
+		if v.String() != "" {
+			s.Default = v.String()
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		items := b.value(reflect.New(t.Elem()).Elem())
+		if items == nil {
+			return nil
+		}
+		s := &Schema{Type: "array", Items: items}
+		if v.Len() > 0 {
+			s.Default = schemaDefault(v)
+		}
+		return s
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil
+		}
+		elem := b.value(reflect.New(t.Elem()).Elem())
+		if elem == nil {
+			return nil
+		}
+		s := &Schema{Type: "object", AdditionalProperties: elem}
+		if v.Len() > 0 {
+			s.Default = schemaDefault(v)
+		}
+		return s
+	case reflect.Struct:
+		return b.object(v)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return b.value(reflect.New(t.Elem()).Elem())
+		}
+		return b.value(v.Elem())
+	case reflect.Interface:
+		// Interfaces are set by Telegraf, not via the configuration
+		return nil
+	}
+	return nil
+}
+
+// schemaDefault converts a slice or map of basic types to its JSON
+// representation and returns nil for all other values
+func schemaDefault(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			return time.Duration(v.Int()).String()
+		}
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		values := make([]interface{}, 0, v.Len())
+		for i := range v.Len() {
+			e := schemaDefault(v.Index(i))
+			if e == nil {
+				return nil
+			}
+			values = append(values, e)
+		}
+		return values
+	case reflect.Map:
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e := schemaDefault(iter.Value())
+			if e == nil {
+				return nil
+			}
+			values[iter.Key().String()] = e
+		}
+		return values
+	}
+	return nil
+}
+
+func durationSchema(description string) *Schema {
+	return &Schema{
+		Type:        []string{"string", "number"},
+		Pattern:     durationPattern,
+		Description: description,
+	}
+}
+
+func stringSliceSchema(description string) *Schema {
+	return &Schema{Type: "array", Items: &Schema{Type: "string"}, Description: description}
+}
+
+func stringMapSchema(description string) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}, Description: description}
+}
+
+func tagFilterSchema(description string) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: stringSliceSchema(""), Description: description}
+}
+
+func enumSchema(description string, def interface{}, values []string) *Schema {
+	s := &Schema{Type: "string", Default: def, Description: description}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
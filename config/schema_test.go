@@ -0,0 +1,164 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+func TestPluginSchema(t *testing.T) {
+	s, err := config.PluginSchema("inputs", "schematest")
+	require.NoError(t, err)
+	require.Equal(t, config.SchemaDraft, s.Schema)
+	require.Equal(t, "inputs.schematest", s.Title)
+	require.Equal(t, false, s.AdditionalProperties)
+
+	// Plugin options
+	require.Equal(t, "string", s.Properties["servers"].Items.Type)
+	require.Equal(t, []interface{}{"localhost:1234"}, s.Properties["servers"].Default)
+	require.Equal(t, []string{"string", "number"}, s.Properties["timeout"].Type)
+	require.Equal(t, "5s", s.Properties["timeout"].Default)
+	require.Equal(t, []string{"string", "integer"}, s.Properties["max_size"].Type)
+	require.Equal(t, "string", s.Properties["password"].Type)
+	require.Nil(t, s.Properties["password"].Default)
+	require.Equal(t, "boolean", s.Properties["gather_all"].Type)
+	require.True(t, s.Properties["port"].Deprecated)
+	require.Equal(t, "use 'servers' instead", s.Properties["port"].Description)
+	require.Equal(t, "object", s.Properties["endpoint"].Type)
+	require.Contains(t, s.Properties["endpoint"].Properties, "headers")
+	require.Equal(t, "array", s.Properties["endpoints"].Type)
+	require.Equal(t, "object", s.Properties["endpoints"].Items.Type)
+
+	// Embedded options and fields not configurable
+	require.Contains(t, s.Properties, "tls_ca")
+	require.NotContains(t, s.Properties, "log")
+
+	// Common input options
+	require.Contains(t, s.Properties, "interval")
+	require.Contains(t, s.Properties, "namepass")
+	require.Contains(t, s.Properties, "tags")
+}
+
+func TestPluginSchemaUnknown(t *testing.T) {
+	_, err := config.PluginSchema("inputs", "does_not_exist")
+	require.ErrorContains(t, err, "undefined but requested input plugin: does_not_exist")
+
+	_, err = config.PluginSchema("foo", "bar")
+	require.ErrorContains(t, err, `unknown plugin category "foo"`)
+}
+
+func TestPluginSchemaValidation(t *testing.T) {
+	s, err := config.PluginSchema("inputs", "schematest")
+	require.NoError(t, err)
+	schema := compileSchema(t, s)
+
+	valid := `{
+		"servers": ["localhost:4321"],
+		"timeout": "1m30s",
+		"max_size": "10MiB",
+		"password": "@{store:password}",
+		"tls_ca": "/etc/ca.pem",
+		"endpoint": {"url": "http://localhost", "headers": {"a": "b"}},
+		"endpoints": [{"url": "http://localhost"}],
+		"interval": 10,
+		"tagpass": {"cpu": ["cpu0"]},
+		"tags": {"env": "test"}
+	}`
+	require.NoError(t, schema.Validate(decodeJSON(t, valid)))
+
+	for _, invalid := range []string{
+		`{"servres": ["localhost:4321"]}`,
+		`{"timeout": "10 seconds"}`,
+		`{"gather_all": "yes"}`,
+		`{"endpoint": {"uri": "http://localhost"}}`,
+		`{"tags": {"env": 1}}`,
+	} {
+		require.Error(t, schema.Validate(decodeJSON(t, invalid)), invalid)
+	}
+}
+
+func TestSchemaBundle(t *testing.T) {
+	s, err := config.SchemaBundle()
+	require.NoError(t, err)
+	require.Contains(t, s.Defs, "inputs.schematest")
+	require.Equal(t, "10s", s.Properties["agent"].Properties["interval"].Default)
+	schema := compileSchema(t, s)
+
+	valid := `{
+		"agent": {"interval": "10s", "omit_hostname": true},
+		"global_tags": {"dc": "eu"},
+		"inputs": {"schematest": [{"servers": ["localhost:4321"]}, {"timeout": "1s"}]}
+	}`
+	require.NoError(t, schema.Validate(decodeJSON(t, valid)))
+
+	invalid := `{"inputs": {"schematest": [{"timeout": "1s", "unknown": true}]}}`
+	require.Error(t, schema.Validate(decodeJSON(t, invalid)))
+	invalid = `{"inputs": {"does_not_exist": [{}]}}`
+	require.Error(t, schema.Validate(decodeJSON(t, invalid)))
+}
+
+func compileSchema(t *testing.T, s *config.Schema) *jsonschema.Schema {
+	t.Helper()
+
+	buf, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource("schema.json", bytes.NewReader(buf)))
+	schema, err := compiler.Compile("schema.json")
+	require.NoError(t, err)
+	return schema
+}
+
+func decodeJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &v))
+	return v
+}
+
+type schemaEndpoint struct {
+	URL     string            `toml:"url"`
+	Headers map[string]string `toml:"headers"`
+}
+
+// MockupSchemaPlugin is a mockup input plugin covering the supported types
+type MockupSchemaPlugin struct {
+	Servers   []string         `toml:"servers"`
+	Port      int              `toml:"port" deprecated:"1.30.0;use 'servers' instead"`
+	Timeout   config.Duration  `toml:"timeout"`
+	MaxSize   config.Size      `toml:"max_size"`
+	Password  config.Secret    `toml:"password"`
+	GatherAll bool             `toml:"gather_all"`
+	Endpoint  schemaEndpoint   `toml:"endpoint"`
+	Endpoints []schemaEndpoint `toml:"endpoints"`
+	Log       telegraf.Logger  `toml:"-"`
+	tls.ClientConfig
+}
+
+func (*MockupSchemaPlugin) SampleConfig() string {
+	return "Mockup test plugin"
+}
+
+func (*MockupSchemaPlugin) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func init() {
+	inputs.Add("schematest", func() telegraf.Input {
+		return &MockupSchemaPlugin{
+			Servers: []string{"localhost:1234"},
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
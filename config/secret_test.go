@@ -821,6 +821,11 @@ func (s *MockupSecretStore) Set(key, value string) error {
 	return nil
 }
 
+func (s *MockupSecretStore) Delete(key string) error {
+	delete(s.Secrets, key)
+	return nil
+}
+
 func (s *MockupSecretStore) List() ([]string, error) {
 	keys := make([]string, 0, len(s.Secrets))
 	for k := range s.Secrets {
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// pluginCategories are the top-level tables containing plugin definitions
+var pluginCategories = []string{"inputs", "plugins", "outputs", "processors", "aggregators", "secretstores"}
+
+// loadIncludes loads the files referenced by the top-level 'include' option
+// of the given table using the load function. Relative paths are resolved
+// against the directory of the including file and glob patterns are
+// expanded in lexical order.
+func (c *Config) loadIncludes(tbl *ast.Table, path string, load func(string) error) error {
+	files, err := includeFiles(tbl, path)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	c.includeStack = append(c.includeStack, includeKey(path))
+	defer func() { c.includeStack = c.includeStack[:len(c.includeStack)-1] }()
+
+	for _, fn := range files {
+		if slices.Contains(c.includeStack, includeKey(fn)) {
+			return fmt.Errorf("include cycle detected: %s includes %s", path, fn)
+		}
+		if err := load(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeFiles returns the files referenced by the 'include' option
+func includeFiles(tbl *ast.Table, path string) ([]string, error) {
+	node, found := tbl.Fields["include"]
+	if !found {
+		return nil, nil
+	}
+	patterns, err := stringOrStrings(node)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'include' option: %w", err)
+	}
+
+	// Resolve the includes against remote locations
+	if isURL(path) {
+		base, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		files := make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			ref, err := url.Parse(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid include %q: %w", pattern, err)
+			}
+			files = append(files, base.ResolveReference(ref).String())
+		}
+		return files, nil
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		if isURL(pattern) {
+			files = append(files, pattern)
+			continue
+		}
+		if !filepath.IsAbs(pattern) && path != EmptySourcePath {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include %q: %w", pattern, err)
+		}
+		// Report missing files not using wildcards when loading them
+		if len(matches) == 0 && !strings.ContainsAny(pattern, `*?[`) {
+			matches = []string{pattern}
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// includeKey returns the key for identifying a file in the include stack
+func includeKey(path string) string {
+	if path == EmptySourcePath || isURL(path) {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// addTemplates registers the templates of the top-level 'templates' table.
+// Templates are available for all plugins defined in the same or any file
+// loaded later.
+func (c *Config) addTemplates(tbl *ast.Table) error {
+	node, found := tbl.Fields["templates"]
+	if !found {
+		return nil
+	}
+	templates, ok := node.(*ast.Table)
+	if !ok {
+		return errors.New("invalid configuration, error parsing templates table")
+	}
+
+	for name, val := range templates.Fields {
+		template, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing template %q as table", name)
+		}
+		if _, found := c.templates[name]; found {
+			return fmt.Errorf("line %d: template %q already defined", template.Line, name)
+		}
+		c.templates[name] = template
+	}
+
+	// Resolve the inheritance of the templates to report errors early
+	for name := range templates.Fields {
+		if _, err := c.template(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// template returns the template with the given name after resolving its own
+// 'inherit' option. The names of the templates currently being resolved are
+// passed to detect cycles.
+func (c *Config) template(name string, resolving []string) (*ast.Table, error) {
+	template, found := c.templates[name]
+	if !found {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+	if slices.Contains(resolving, name) {
+		return nil, fmt.Errorf("template inheritance cycle: %s -> %s", strings.Join(resolving, " -> "), name)
+	}
+
+	if _, found := template.Fields["inherit"]; found {
+		if err := c.inherit(template, append(slices.Clone(resolving), name)); err != nil {
+			return nil, fmt.Errorf("template %q: %w", name, err)
+		}
+	}
+	return template, nil
+}
+
+// applyTemplates resolves the 'inherit' option of all plugin tables
+func (c *Config) applyTemplates(tbl *ast.Table) error {
+	for _, category := range pluginCategories {
+		node, found := tbl.Fields[category]
+		if !found {
+			continue
+		}
+		categoryTbl, ok := node.(*ast.Table)
+		if !ok {
+			continue
+		}
+
+		for name, pluginVal := range categoryTbl.Fields {
+			var plugins []*ast.Table
+			switch pluginTbl := pluginVal.(type) {
+			case *ast.Table:
+				plugins = []*ast.Table{pluginTbl}
+			case []*ast.Table:
+				plugins = pluginTbl
+			}
+			for _, plugin := range plugins {
+				if err := c.inherit(plugin, nil); err != nil {
+					return fmt.Errorf("plugin %s.%s: line %d: %w", category, name, plugin.Line, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inherit merges the templates referenced by the 'inherit' option into the
+// given table and removes the option. Settings of the table take precedence
+// over the templates and later templates over earlier ones.
+func (c *Config) inherit(tbl *ast.Table, resolving []string) error {
+	node, found := tbl.Fields["inherit"]
+	if !found {
+		return nil
+	}
+	names, err := stringOrStrings(node)
+	if err != nil {
+		return fmt.Errorf("invalid 'inherit' option: %w", err)
+	}
+	delete(tbl.Fields, "inherit")
+
+	for i := len(names) - 1; i >= 0; i-- {
+		template, err := c.template(names[i], resolving)
+		if err != nil {
+			return err
+		}
+		mergeTable(tbl, template)
+	}
+	return nil
+}
+
+// mergeTable adds the fields of src missing in dst to dst. Tables present in
+// both are merged recursively. The added fields are attributed to the line of
+// dst as the template might be defined in another file.
+func mergeTable(dst, src *ast.Table) {
+	for key, srcVal := range src.Fields {
+		dstVal, found := dst.Fields[key]
+		if !found {
+			dst.Fields[key] = copyTableField(srcVal, dst.Line)
+			continue
+		}
+
+		dstTbl, dstOk := dstVal.(*ast.Table)
+		srcTbl, srcOk := srcVal.(*ast.Table)
+		if dstOk && srcOk {
+			mergeTable(dstTbl, srcTbl)
+		}
+	}
+}
+
+// copyTableField deep-copies the given field with the given line to avoid
+// modifying the templates when merging into the copy later. Values are never
+// modified so they can be shared.
+func copyTableField(field interface{}, line int) interface{} {
+	switch v := field.(type) {
+	case *ast.KeyValue:
+		kv := *v
+		kv.Line = line
+		return &kv
+	case *ast.Table:
+		tbl := *v
+		tbl.Line = line
+		tbl.Fields = make(map[string]interface{}, len(v.Fields))
+		for key, val := range v.Fields {
+			tbl.Fields[key] = copyTableField(val, line)
+		}
+		return &tbl
+	case []*ast.Table:
+		tables := make([]*ast.Table, 0, len(v))
+		for _, t := range v {
+			tables = append(tables, copyTableField(t, line).(*ast.Table))
+		}
+		return tables
+	}
+	return field
+}
+
+// stringOrStrings returns the string values of an option that can either be
+// a string or an array of strings
+func stringOrStrings(node interface{}) ([]string, error) {
+	kv, ok := node.(*ast.KeyValue)
+	if !ok {
+		return nil, errors.New("expected a string or an array of strings")
+	}
+
+	switch v := kv.Value.(type) {
+	case *ast.String:
+		return []string{v.Value}, nil
+	case *ast.Array:
+		values := make([]string, 0, len(v.Value))
+		for _, elem := range v.Value {
+			s, ok := elem.(*ast.String)
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a string or an array of strings", kv.Line)
+			}
+			values = append(values, s.Value)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("line %d: expected a string or an array of strings", kv.Line)
+}
@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestTemplatesAndIncludes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(filepath.Join("testdata", "templates", "telegraf.toml")))
+
+	require.Len(t, c.Inputs, 1)
+	input, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.Equal(t, []string{"localhost"}, input.Servers)
+	require.Equal(t, config.Duration(10*time.Second), input.Timeout)
+	require.Equal(t, config.Duration(2*time.Second), input.ReadTimeout)
+	require.Equal(t, config.Duration(time.Second), input.WriteTimeout)
+	require.Equal(t, []string{"memcached"}, c.Inputs[0].Config.Filter.NamePass)
+	require.Equal(t, map[string]string{"env": "prod"}, c.Inputs[0].Config.Tags)
+
+	require.Len(t, c.Outputs, 1)
+	output, ok := c.Outputs[0].Output.(*MockupOutputPlugin)
+	require.True(t, ok)
+	require.Equal(t, "http://localhost:8080", output.URL)
+	expectedHeaders := map[string]string{
+		"Authorization": "Bearer secret",
+		"X-Source":      "telegraf",
+	}
+	require.Equal(t, expectedHeaders, output.Headers)
+	require.Equal(t, "telegraf.example.com", output.ServerName)
+	require.True(t, output.InsecureSkipVerify)
+}
+
+func TestTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name: "unknown template",
+			contents: `
+[[inputs.memcached]]
+  inherit = "foo"
+`,
+			expected: `plugin inputs.memcached: line 2: unknown template "foo"`,
+		},
+		{
+			name: "inheritance cycle",
+			contents: `
+[templates.a]
+  inherit = "b"
+[templates.b]
+  inherit = "a"
+`,
+			expected: "template inheritance cycle",
+		},
+		{
+			name: "invalid inherit",
+			contents: `
+[[inputs.memcached]]
+  inherit = 42
+`,
+			expected: "invalid 'inherit' option: line 3: expected a string or an array of strings",
+		},
+		{
+			name: "missing include",
+			contents: `
+include = "does_not_exist.toml"
+`,
+			expected: "loading config file does_not_exist.toml failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			require.ErrorContains(t, c.LoadConfigData([]byte(tt.contents), config.EmptySourcePath), tt.expected)
+		})
+	}
+}
+
+func TestTemplatesDuplicate(t *testing.T) {
+	cfg := []byte(`
+[templates.a]
+  timeout = "1s"
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.ErrorContains(t, c.LoadConfigData(cfg, config.EmptySourcePath), `template "a" already defined`)
+}
+
+func TestTemplatesTemplateNotModified(t *testing.T) {
+	cfg := []byte(`
+[templates.common]
+  [templates.common.tags]
+    env = "prod"
+
+[[inputs.memcached]]
+  inherit = "common"
+  [inputs.memcached.tags]
+    dc = "a"
+
+[[inputs.memcached]]
+  inherit = "common"
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.Len(t, c.Inputs, 2)
+	require.Equal(t, map[string]string{"env": "prod", "dc": "a"}, c.Inputs[0].Config.Tags)
+	require.Equal(t, map[string]string{"env": "prod"}, c.Inputs[1].Config.Tags)
+}
+
+func TestIncludeCycle(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfig(filepath.Join("testdata", "templates", "cycle", "a.toml"))
+	require.ErrorContains(t, err, "include cycle detected")
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// RemoteConfigVerifier verifies configurations fetched from remote locations
+// using a detached signature or a signed SHA-256 manifest fetched alongside
+// the configuration.
+type RemoteConfigVerifier struct {
+	// Manifest is the location of the manifest containing the SHA-256 sums of
+	// the configurations, relative to the configuration URL. If empty, the
+	// configurations are verified using the detached signature at the
+	// configuration URL with an additional ".sig" suffix.
+	Manifest string
+
+	key crypto.PublicKey
+}
+
+// NewRemoteConfigVerifier creates a verifier using the PEM encoded public key
+// in the given file. Ed25519, ECDSA and RSA keys are supported.
+func NewRemoteConfigVerifier(keyfile, manifest string) (*RemoteConfigVerifier, error) {
+	buf, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, fmt.Errorf("reading public key failed: %w", err)
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("no PEM encoded public key found in %q", keyfile)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key failed: %w", err)
+	}
+
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return &RemoteConfigVerifier{Manifest: manifest, key: key}, nil
+}
+
+// Verify checks the given data fetched from the configuration URL against the
+// detached signature or the manifest. The signature or manifest is fetched
+// with the given number of retries.
+func (v *RemoteConfigVerifier) Verify(configURL string, data []byte, urlRetryAttempts int) error {
+	u, err := url.Parse(configURL)
+	if err != nil {
+		return err
+	}
+
+	if v.Manifest == "" {
+		sigURL := *u
+		sigURL.Path += ".sig"
+		signature, err := fetchConfig(&sigURL, urlRetryAttempts)
+		if err != nil {
+			return fmt.Errorf("fetching signature failed: %w", err)
+		}
+		return v.verifySignature(data, signature)
+	}
+
+	ref, err := url.Parse(v.Manifest)
+	if err != nil {
+		return fmt.Errorf("invalid manifest location: %w", err)
+	}
+	manifestURL := u.ResolveReference(ref)
+	manifest, err := fetchConfig(manifestURL, urlRetryAttempts)
+	if err != nil {
+		return fmt.Errorf("fetching manifest failed: %w", err)
+	}
+	sigURL := *manifestURL
+	sigURL.Path += ".sig"
+	signature, err := fetchConfig(&sigURL, urlRetryAttempts)
+	if err != nil {
+		return fmt.Errorf("fetching manifest signature failed: %w", err)
+	}
+	if err := v.verifySignature(manifest, signature); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+
+	return verifyManifest(manifest, path.Base(u.Path), data)
+}
+
+// verifySignature checks the signature of the given data. The signature can
+// either be in binary or in base64 encoding. ECDSA and RSA signatures are
+// expected over the SHA-256 digest of the data.
+func (v *RemoteConfigVerifier) verifySignature(data, signature []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signature = decoded
+	}
+
+	digest := sha256.Sum256(data)
+	var valid bool
+	switch key := v.key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	if !valid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// verifyManifest checks the SHA-256 sum of the data against the entry for the
+// given name in the manifest. The manifest uses the format of the 'sha256sum'
+// utility with one '<hex digest>  <name>' entry per line.
+func verifyManifest(manifest []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		digest, filename, found := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !found {
+			continue
+		}
+		filename = strings.TrimPrefix(strings.TrimSpace(filename), "*")
+		if filename != name {
+			continue
+		}
+
+		expected, err := hex.DecodeString(digest)
+		if err != nil {
+			return fmt.Errorf("invalid digest for %q in manifest: %w", name, err)
+		}
+		actual := sha256.Sum256(data)
+		if !bytes.Equal(expected, actual[:]) {
+			return fmt.Errorf("SHA-256 sum of %q does not match the manifest", name)
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading manifest failed: %w", err)
+	}
+	return fmt.Errorf("no entry for %q in manifest", name)
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const verifyTestConfig = `
+[[inputs.file]]
+  files = ["/dev/null"]
+`
+
+func writeVerifyTestKey(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	fn := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(fn, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return fn
+}
+
+func serveVerifyTestFiles(t *testing.T, files map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, found := files[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(data); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRemoteConfigVerifierSignature(t *testing.T) {
+	httpLoadConfigRetryInterval = 0 * time.Second
+
+	data := []byte(verifyTestConfig)
+	digest := sha256.Sum256(data)
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecPriv, digest[:])
+	require.NoError(t, err)
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaPriv, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		key       crypto.PublicKey
+		signature []byte
+	}{
+		{
+			name:      "ed25519",
+			key:       edPub,
+			signature: ed25519.Sign(edPriv, data),
+		},
+		{
+			name:      "ed25519 base64",
+			key:       edPub,
+			signature: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(edPriv, data)) + "\n"),
+		},
+		{
+			name:      "ecdsa",
+			key:       &ecPriv.PublicKey,
+			signature: ecSig,
+		},
+		{
+			name:      "rsa",
+			key:       &rsaPriv.PublicKey,
+			signature: rsaSig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := serveVerifyTestFiles(t, map[string][]byte{
+				"/telegraf.conf":     data,
+				"/telegraf.conf.sig": tt.signature,
+			})
+
+			verifier, err := NewRemoteConfigVerifier(writeVerifyTestKey(t, tt.key), "")
+			require.NoError(t, err)
+
+			c := NewConfig()
+			c.RemoteConfigVerifier = verifier
+			require.NoError(t, c.LoadConfig(ts.URL+"/telegraf.conf"))
+			require.Len(t, c.Inputs, 1)
+		})
+	}
+}
+
+func TestRemoteConfigVerifierSignatureInvalid(t *testing.T) {
+	httpLoadConfigRetryInterval = 0 * time.Second
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ts := serveVerifyTestFiles(t, map[string][]byte{
+		"/telegraf.conf":     []byte(verifyTestConfig + "  character_encoding = \"utf-8\"\n"),
+		"/telegraf.conf.sig": ed25519.Sign(priv, []byte(verifyTestConfig)),
+	})
+
+	verifier, err := NewRemoteConfigVerifier(writeVerifyTestKey(t, pub), "")
+	require.NoError(t, err)
+
+	c := NewConfig()
+	c.Agent.ConfigURLRetryAttempts = 1
+	c.RemoteConfigVerifier = verifier
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/telegraf.conf"), "invalid signature")
+	require.Empty(t, c.Inputs)
+
+	// Missing signatures must be rejected as well
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/telegraf.conf.sig"), "fetching signature failed")
+}
+
+func TestRemoteConfigVerifierInclude(t *testing.T) {
+	httpLoadConfigRetryInterval = 0 * time.Second
+
+	// Files included by a verified configuration must be verified as well
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data := []byte("include = [\"include.conf\"]\n")
+	ts := serveVerifyTestFiles(t, map[string][]byte{
+		"/telegraf.conf":     data,
+		"/telegraf.conf.sig": ed25519.Sign(priv, data),
+		"/include.conf":      []byte(verifyTestConfig),
+		"/include.conf.sig":  ed25519.Sign(priv, []byte("something else")),
+	})
+
+	verifier, err := NewRemoteConfigVerifier(writeVerifyTestKey(t, pub), "")
+	require.NoError(t, err)
+
+	c := NewConfig()
+	c.Agent.ConfigURLRetryAttempts = 1
+	c.RemoteConfigVerifier = verifier
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/telegraf.conf"), "invalid signature")
+	require.Empty(t, c.Inputs)
+}
+
+func TestRemoteConfigVerifierManifest(t *testing.T) {
+	httpLoadConfigRetryInterval = 0 * time.Second
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	data := []byte(verifyTestConfig)
+	digest := sha256.Sum256(data)
+	manifest := []byte(hex.EncodeToString(digest[:]) + "  telegraf.conf\n" +
+		hex.EncodeToString(digest[:]) + " *modified.conf\n")
+
+	ts := serveVerifyTestFiles(t, map[string][]byte{
+		"/configs/telegraf.conf": data,
+		"/configs/modified.conf": append(data, []byte("  character_encoding = \"utf-8\"\n")...),
+		"/configs/other.conf":    data,
+		"/SHA256SUMS":            manifest,
+		"/SHA256SUMS.sig":        ed25519.Sign(priv, manifest),
+		"/unsigned/SHA256SUMS":   manifest,
+	})
+
+	verifier, err := NewRemoteConfigVerifier(writeVerifyTestKey(t, pub), "../SHA256SUMS")
+	require.NoError(t, err)
+
+	c := NewConfig()
+	c.Agent.ConfigURLRetryAttempts = 1
+	c.RemoteConfigVerifier = verifier
+	require.NoError(t, c.LoadConfig(ts.URL+"/configs/telegraf.conf"))
+	require.Len(t, c.Inputs, 1)
+
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/configs/modified.conf"), "does not match the manifest")
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/configs/other.conf"), `no entry for "other.conf" in manifest`)
+
+	verifier.Manifest = "/unsigned/SHA256SUMS"
+	require.ErrorContains(t, c.LoadConfig(ts.URL+"/configs/telegraf.conf"), "fetching manifest signature failed")
+	require.Len(t, c.Inputs, 1)
+}
+
+func TestNewRemoteConfigVerifierInvalidKey(t *testing.T) {
+	_, err := NewRemoteConfigVerifier(filepath.Join(t.TempDir(), "missing.pem"), "")
+	require.ErrorContains(t, err, "reading public key failed")
+
+	fn := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(fn, []byte("not a key"), 0600))
+	_, err = NewRemoteConfigVerifier(fn, "")
+	require.ErrorContains(t, err, "no PEM encoded public key found")
+}
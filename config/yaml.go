@@ -0,0 +1,249 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/toml/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// configFormat returns the format of the configuration at the given path
+// based on the file extension. The path might also be an URL.
+func configFormat(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	}
+	return "toml"
+}
+
+// parseYAML converts a YAML or JSON configuration into the AST of the
+// equivalent TOML configuration. Mappings become tables and sequences of
+// mappings become arrays of tables, so the plugin tables are specified as
+//
+//	inputs:
+//	  cpu:
+//	    - percpu: true
+//
+// Null values are treated as if the key would not be present.
+func parseYAML(contents []byte) (*ast.Table, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, err
+	}
+
+	// Handle empty documents
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return &ast.Table{Line: 1, Fields: make(map[string]interface{})}, nil
+	}
+
+	root := resolveYAMLAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping at the top level", root.Line)
+	}
+	return yamlToTable("", root)
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func yamlToTable(name string, node *yaml.Node) (*ast.Table, error) {
+	tbl := &ast.Table{
+		Line:   node.Line,
+		Name:   name,
+		Fields: make(map[string]interface{}, len(node.Content)/2),
+		Type:   ast.TableTypeNormal,
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := resolveYAMLAlias(node.Content[i])
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: keys have to be scalars", keyNode.Line)
+		}
+		key := keyNode.Value
+
+		// Merge keys as in "<<: *defaults" are not supported
+		if keyNode.ShortTag() == "!!merge" {
+			return nil, fmt.Errorf("line %d: merge keys are not supported", keyNode.Line)
+		}
+		if _, found := tbl.Fields[key]; found {
+			return nil, fmt.Errorf("line %d: key %q is already defined", keyNode.Line, key)
+		}
+
+		valueNode := resolveYAMLAlias(node.Content[i+1])
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			sub, err := yamlToTable(key, valueNode)
+			if err != nil {
+				return nil, err
+			}
+			sub.Line = keyNode.Line
+			tbl.Fields[key] = sub
+		case yaml.SequenceNode:
+			if isYAMLTableArray(valueNode) {
+				tables := make([]*ast.Table, 0, len(valueNode.Content))
+				for _, item := range valueNode.Content {
+					sub, err := yamlToTable(key, resolveYAMLAlias(item))
+					if err != nil {
+						return nil, err
+					}
+					sub.Type = ast.TableTypeArray
+					tables = append(tables, sub)
+				}
+				tbl.Fields[key] = tables
+				continue
+			}
+			fallthrough
+		default:
+			if valueNode.ShortTag() == "!!null" {
+				continue
+			}
+			value, err := yamlToValue(valueNode)
+			if err != nil {
+				return nil, err
+			}
+			tbl.Fields[key] = &ast.KeyValue{Key: key, Value: value, Line: keyNode.Line}
+		}
+	}
+
+	return tbl, nil
+}
+
+// isYAMLTableArray checks if all elements of the sequence are mappings
+func isYAMLTableArray(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if resolveYAMLAlias(item).Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlToValue converts scalars and sequences of scalars to TOML values. The
+// data of the values is set to the equivalent TOML source as it is passed to
+// the unmarshallers of the options.
+func yamlToValue(node *yaml.Node) (ast.Value, error) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		values := make([]ast.Value, 0, len(node.Content))
+		sources := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			item = resolveYAMLAlias(item)
+			if item.Kind == yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: mixing tables and values in a sequence is not supported", item.Line)
+			}
+			v, err := yamlToValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			sources = append(sources, v.Source())
+		}
+		return &ast.Array{Value: values, Data: []rune("[" + strings.Join(sources, ", ") + "]")}, nil
+	case yaml.ScalarNode:
+	default:
+		return nil, fmt.Errorf("line %d: unsupported node", node.Line)
+	}
+
+	switch node.ShortTag() {
+	case "!!str":
+		return &ast.String{Value: node.Value, Data: []rune(tomlString(node.Value))}, nil
+	case "!!int":
+		var v int64
+		if err := node.Decode(&v); err != nil {
+			var u uint64
+			if errU := node.Decode(&u); errU != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			s := strconv.FormatUint(u, 10)
+			return &ast.Integer{Value: s, Data: []rune(s)}, nil
+		}
+		s := strconv.FormatInt(v, 10)
+		return &ast.Integer{Value: s, Data: []rune(s)}, nil
+	case "!!float":
+		var v float64
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("line %d: infinite and not-a-number values are not supported", node.Line)
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return &ast.Float{Value: s, Data: []rune(s)}, nil
+	case "!!bool":
+		var v bool
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		s := strconv.FormatBool(v)
+		return &ast.Boolean{Value: s, Data: []rune(s)}, nil
+	case "!!timestamp":
+		var v time.Time
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		s := v.Format(time.RFC3339Nano)
+		return &ast.Datetime{Value: s, Data: []rune(s)}, nil
+	case "!!null":
+		return nil, fmt.Errorf("line %d: null values are not supported in sequences", node.Line)
+	case "!!binary":
+		return nil, fmt.Errorf("line %d: binary values are not supported", node.Line)
+	}
+	return nil, fmt.Errorf("line %d: unsupported tag %q", node.Line, node.ShortTag())
+}
+
+// tomlString quotes the given string as TOML basic string. In contrast to Go
+// strings, TOML only supports a limited set of escape sequences, so all other
+// control characters are escaped as unicode code points.
+func tomlString(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
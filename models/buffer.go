@@ -79,6 +79,12 @@ type Buffer interface {
 	// Stats returns the buffer statistics such as rejected, dropped and accepted metrics
 	Stats() BufferStats
 
+	// Fill returns the fill level of the buffer in percent of its capacity,
+	// i.e. the number of metrics for memory buffers and the size of the files
+	// for size-limited disk buffers. The boolean is false for buffers without
+	// a capacity limit.
+	Fill() (float64, bool)
+
 	// Close finalizes the buffer and closes all open resources
 	Close() error
 }
@@ -94,8 +100,27 @@ type BufferStats struct {
 	BufferLimit     selfstat.Stat
 }
 
+// BufferSettings holds the options of size-limited disk buffers.
+type BufferSettings struct {
+	// MaxSize is the maximum number of bytes stored on disk, zero means
+	// unlimited.
+	MaxSize int64
+	// SegmentSize is the size in bytes after which a new segment file is
+	// started.
+	SegmentSize int64
+	// OverflowPolicy determines which metrics to drop when reaching the
+	// maximum size, either "drop_oldest" or "drop_newest".
+	OverflowPolicy string
+}
+
 // NewBuffer returns a new empty Buffer with the given capacity.
 func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+	return NewBufferWithSettings(name, id, alias, capacity, strategy, path, BufferSettings{})
+}
+
+// NewBufferWithSettings returns a new empty Buffer with the given capacity
+// using the given settings for disk-based buffer strategies.
+func NewBufferWithSettings(name, id, alias string, capacity int, strategy, path string, settings BufferSettings) (Buffer, error) {
 	registerGob()
 
 	bs := NewBufferStats(name, alias, capacity)
@@ -105,6 +130,10 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 		return NewMemoryBuffer(capacity, bs)
 	case "disk_write_through":
 		return NewDiskBuffer(id, path, bs)
+	case "disk_segmented":
+		return NewSegmentedDiskBuffer(id, path, settings, bs)
+	case "hybrid":
+		return NewHybridBuffer(id, path, capacity, settings, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
@@ -173,3 +202,10 @@ func (b *BufferStats) metricDropped(m telegraf.Metric) {
 	b.MetricsDropped.Incr(1)
 	m.Reject()
 }
+
+// metricDiscarded accounts for a dropped metric that could not be restored
+// and thus cannot be rejected.
+func (b *BufferStats) metricDiscarded() {
+	AgentMetricsDropped.Incr(1)
+	b.MetricsDropped.Incr(1)
+}
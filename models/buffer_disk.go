@@ -266,6 +266,12 @@ func (b *DiskBuffer) Stats() BufferStats {
 	return b.BufferStats
 }
 
+// Fill always reports an unlimited buffer as the write-ahead log is not
+// limited in size
+func (*DiskBuffer) Fill() (float64, bool) {
+	return 0, false
+}
+
 func (b *DiskBuffer) Close() error {
 	if err := b.file.Close(); err != nil {
 		return fmt.Errorf("closing buffer failed: %w", err)
@@ -0,0 +1,811 @@
+package models
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	// DefaultBufferSegmentSize is the default size of a single segment file
+	DefaultBufferSegmentSize = 16 * 1024 * 1024
+
+	segmentFileExtension = ".seg"
+	segmentHeadFile      = "head"
+
+	// Each record is prefixed by the length and the CRC32 checksum of the data
+	segmentRecordHeaderSize = 8
+)
+
+var errCorruptRecord = errors.New("corrupt record")
+
+// segment describes a single append-only file holding serialized metrics
+type segment struct {
+	path  string
+	first uint64 // sequence number of the first entry in the segment
+	count uint64 // number of entries in the segment
+	size  int64  // size of the segment file in bytes
+}
+
+func (s *segment) end() uint64 {
+	return s.first + s.count
+}
+
+// segmentEntry describes the position of a single entry in the buffer
+type segmentEntry struct {
+	seq  uint64
+	size int64
+}
+
+// SegmentedDiskBuffer stores metrics in rotating segment files on disk and
+// enforces a maximum size of all files by dropping metrics according to the
+// configured overflow policy.
+type SegmentedDiskBuffer struct {
+	BufferStats
+	sync.Mutex
+
+	path           string
+	maxSize        int64
+	segmentSize    int64
+	overflowPolicy string
+
+	segments []*segment
+	writer   *os.File // handle of the last segment, nil if a new segment must be started
+	size     int64    // total size of all segment files in bytes
+
+	head       uint64 // sequence number of the oldest entry in the buffer
+	headOffset int64  // offset of the oldest entry in the first segment file
+	tail       uint64 // sequence number of the next entry to write
+
+	// Sequence number after the last entry read from disk on telegraf launch.
+	// Used to know whether to discard tracking metrics.
+	originalEnd uint64
+
+	// The mask contains the entries already removed during a previous
+	// transaction. Those entries should not be contained in new batches and
+	// are sorted by sequence number. The mask is persisted together with the
+	// head to not send the entries again after a restart.
+	mask []segmentEntry
+
+	// Sequence numbers of the metrics in the currently running transaction
+	batch []uint64
+}
+
+func NewSegmentedDiskBuffer(id, path string, settings BufferSettings, stats BufferStats) (*SegmentedDiskBuffer, error) {
+	policy := settings.OverflowPolicy
+	switch policy {
+	case "":
+		policy = "drop_oldest"
+	case "drop_oldest", "drop_newest":
+	default:
+		return nil, fmt.Errorf("invalid buffer overflow policy %q", policy)
+	}
+	if settings.MaxSize < 0 {
+		return nil, fmt.Errorf("invalid maximum buffer size %d", settings.MaxSize)
+	}
+
+	// Keep the segments small compared to the maximum size as we can only
+	// drop complete segments when the limit is reached.
+	segmentSize := settings.SegmentSize
+	if segmentSize <= 0 {
+		segmentSize = DefaultBufferSegmentSize
+	}
+	if settings.MaxSize > 0 {
+		segmentSize = min(segmentSize, max(settings.MaxSize/4, 1))
+	}
+
+	dirPath := filepath.Join(path, id)
+	if err := os.MkdirAll(dirPath, 0750); err != nil {
+		return nil, fmt.Errorf("creating buffer directory failed: %w", err)
+	}
+
+	buf := &SegmentedDiskBuffer{
+		BufferStats:    stats,
+		path:           dirPath,
+		maxSize:        settings.MaxSize,
+		segmentSize:    segmentSize,
+		overflowPolicy: policy,
+	}
+	if err := buf.restore(); err != nil {
+		return nil, fmt.Errorf("restoring buffer from %q failed: %w", dirPath, err)
+	}
+	if buf.length() > 0 {
+		buf.originalEnd = buf.tail
+	}
+	buf.BufferSize.Set(int64(buf.length()))
+
+	return buf, nil
+}
+
+func (b *SegmentedDiskBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.length()
+}
+
+func (b *SegmentedDiskBuffer) length() int {
+	var n uint64
+	for i, s := range b.segments {
+		if i == 0 {
+			n += s.end() - b.head
+		} else {
+			n += s.count
+		}
+	}
+	return int(n) - len(b.mask)
+}
+
+func (b *SegmentedDiskBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.add(metrics, true)
+}
+
+// spill adds metrics already accounted for by another buffer
+func (b *SegmentedDiskBuffer) spill(metrics []telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.add(metrics, false)
+}
+
+func (b *SegmentedDiskBuffer) add(metrics []telegraf.Metric, account bool) int {
+	dropped := 0
+	for _, m := range metrics {
+		n, added := b.addMetric(m)
+		if added && account {
+			b.metricAdded()
+		}
+		dropped += n
+	}
+
+	if b.writer != nil {
+		if err := b.writer.Sync(); err != nil {
+			log.Printf("E! Syncing buffer segment failed: %v", err)
+		}
+	}
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+func (b *SegmentedDiskBuffer) addMetric(m telegraf.Metric) (int, bool) {
+	data, err := metric.ToBytes(m)
+	if err != nil {
+		panic(err)
+	}
+	recordSize := int64(len(data) + segmentRecordHeaderSize)
+
+	// Make room for the new metric if a size limit is set
+	var dropped int
+	if b.maxSize > 0 {
+		if recordSize > b.maxSize {
+			b.metricDropped(m)
+			return 1, false
+		}
+		for b.size+recordSize > b.maxSize {
+			if b.overflowPolicy == "drop_newest" {
+				b.metricDropped(m)
+				return dropped + 1, false
+			}
+			n, ok := b.evictOldest()
+			if !ok {
+				b.metricDropped(m)
+				return dropped + 1, false
+			}
+			dropped += n
+		}
+	}
+
+	if err := b.write(data); err != nil {
+		log.Printf("E! Writing metric to buffer failed: %v", err)
+		b.metricDropped(m)
+		return dropped + 1, false
+	}
+
+	return dropped, true
+}
+
+func (b *SegmentedDiskBuffer) BeginTransaction(batchSize int) *Transaction {
+	b.Lock()
+	defer b.Unlock()
+
+	if batchSize <= 0 || b.length() == 0 {
+		return &Transaction{}
+	}
+
+	metrics := make([]telegraf.Metric, 0, batchSize)
+	entries := make([]segmentEntry, 0, batchSize)
+	var stale []segmentEntry
+	err := b.iterate(func(seq uint64, size int64, data []byte) bool {
+		if b.isRemoved(seq) {
+			return true
+		}
+
+		// Skip tracking metrics of older instances of telegraf. Tracking
+		// metrics can be skipped here as metric.Accept() is only called once
+		// the data is successfully written, so any tracking metrics from
+		// older instances can be dropped and reacquired to have accurate
+		// tracking information.
+		m, err := metric.FromBytes(data)
+		if err != nil {
+			if errors.Is(err, metric.ErrSkipTracking) {
+				stale = append(stale, segmentEntry{seq: seq, size: size})
+				return true
+			}
+			// non-recoverable error in deserialization, abort
+			log.Printf("E! raw metric data: %v", data)
+			panic(err)
+		}
+		if _, ok := m.(telegraf.TrackingMetric); ok && seq < b.originalEnd {
+			stale = append(stale, segmentEntry{seq: seq, size: size})
+			return true
+		}
+
+		metrics = append(metrics, m)
+		entries = append(entries, segmentEntry{seq: seq, size: size})
+		return len(metrics) < batchSize
+	})
+	if err != nil {
+		panic(err) // can only occur with a corrupt or inaccessible segment file
+	}
+
+	// Remove the stale tracking metrics from the buffer
+	if len(stale) > 0 {
+		for range stale {
+			b.metricDiscarded()
+		}
+		b.remove(stale...)
+		b.advance()
+		b.BufferSize.Set(int64(b.length()))
+	}
+
+	if len(metrics) == 0 {
+		return &Transaction{}
+	}
+	b.batch = make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		b.batch = append(b.batch, e.seq)
+	}
+
+	return &Transaction{Batch: metrics, valid: true, state: entries}
+}
+
+func (b *SegmentedDiskBuffer) EndTransaction(tx *Transaction) {
+	if len(tx.Batch) == 0 {
+		return
+	}
+
+	// Ignore invalid transactions and make sure they can only be finished once
+	if !tx.valid {
+		return
+	}
+	tx.valid = false
+
+	// Get the metric positions from the transaction
+	entries := tx.state.([]segmentEntry)
+
+	b.Lock()
+	defer b.Unlock()
+
+	// Metrics with a sequence number before the head were evicted while the
+	// transaction was running and are not part of the buffer anymore.
+	remove := make([]segmentEntry, 0, len(tx.Accept)+len(tx.Reject))
+	for _, idx := range tx.Accept {
+		b.metricWritten(tx.Batch[idx])
+		if entries[idx].seq >= b.head {
+			remove = append(remove, entries[idx])
+		}
+	}
+	for _, idx := range tx.Reject {
+		b.metricRejected(tx.Batch[idx])
+		if entries[idx].seq >= b.head {
+			remove = append(remove, entries[idx])
+		}
+	}
+	for _, idx := range tx.InferKeep() {
+		if entries[idx].seq < b.head {
+			b.metricDropped(tx.Batch[idx])
+		}
+	}
+	b.batch = nil
+
+	b.remove(remove...)
+	b.advance()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *SegmentedDiskBuffer) Stats() BufferStats {
+	return b.BufferStats
+}
+
+func (b *SegmentedDiskBuffer) Fill() (float64, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.maxSize <= 0 {
+		return 0, false
+	}
+	return 100.0 * float64(b.size) / float64(b.maxSize), true
+}
+
+func (b *SegmentedDiskBuffer) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.closeWriter(); err != nil {
+		return fmt.Errorf("closing buffer failed: %w", err)
+	}
+
+	// Remove all remaining data on disk to make sure we won't get any metric
+	// in cases where the buffer is empty.
+	if b.length() == 0 {
+		return os.RemoveAll(b.path)
+	}
+
+	return b.storeHead()
+}
+
+// write appends the given data as a new record to the last segment, starting
+// a new segment if required
+func (b *SegmentedDiskBuffer) write(data []byte) error {
+	recordSize := int64(len(data) + segmentRecordHeaderSize)
+
+	var current *segment
+	if len(b.segments) > 0 {
+		current = b.segments[len(b.segments)-1]
+	}
+	if b.writer == nil || current.count > 0 && current.size+recordSize > b.segmentSize {
+		if err := b.closeWriter(); err != nil {
+			return err
+		}
+		current = &segment{
+			path:  filepath.Join(b.path, fmt.Sprintf("%020d%s", b.tail, segmentFileExtension)),
+			first: b.tail,
+		}
+		f, err := os.OpenFile(current.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return fmt.Errorf("creating segment failed: %w", err)
+		}
+		b.writer = f
+		if len(b.segments) == 0 {
+			b.head = b.tail
+			b.headOffset = 0
+		}
+		b.segments = append(b.segments, current)
+	}
+
+	record := make([]byte, segmentRecordHeaderSize, recordSize)
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(data))
+	record = append(record, data...)
+	if _, err := b.writer.Write(record); err != nil {
+		// Remove partially written data to keep the segment readable
+		if terr := b.writer.Truncate(current.size); terr != nil {
+			log.Printf("E! Truncating segment %q failed: %v", current.path, terr)
+		}
+		return err
+	}
+
+	current.count++
+	current.size += recordSize
+	b.size += recordSize
+	b.tail++
+
+	return nil
+}
+
+func (b *SegmentedDiskBuffer) closeWriter() error {
+	if b.writer == nil {
+		return nil
+	}
+	err := b.writer.Close()
+	b.writer = nil
+	return err
+}
+
+// evictOldest removes the oldest segment and drops all metrics contained in
+// it. Metrics of the running transaction are accounted for when ending the
+// transaction.
+func (b *SegmentedDiskBuffer) evictOldest() (int, bool) {
+	if len(b.segments) == 0 {
+		return 0, false
+	}
+
+	// Make sure we start a new segment for the next write if we are about to
+	// remove the segment currently written to
+	if len(b.segments) == 1 {
+		if err := b.closeWriter(); err != nil {
+			log.Printf("E! Closing segment failed: %v", err)
+		}
+	}
+
+	var dropped int
+	err := b.iterateSegment(b.segments[0], b.head, b.headOffset, func(seq uint64, _ int64, data []byte) bool {
+		if b.isRemoved(seq) {
+			return true
+		}
+		if _, found := slices.BinarySearch(b.batch, seq); found {
+			return true
+		}
+
+		dropped++
+		m, err := metric.FromBytes(data)
+		if err != nil {
+			b.metricDiscarded()
+			return true
+		}
+		if _, ok := m.(telegraf.TrackingMetric); ok && seq < b.originalEnd {
+			b.metricDiscarded()
+			return true
+		}
+		b.metricDropped(m)
+		return true
+	})
+	if err != nil {
+		log.Printf("E! Reading segment %q failed: %v", b.segments[0].path, err)
+	}
+	b.dropSegment()
+
+	return dropped, true
+}
+
+// dropSegment deletes the first segment and moves the head to the next one
+func (b *SegmentedDiskBuffer) dropSegment() {
+	s := b.segments[0]
+	if err := os.Remove(s.path); err != nil {
+		log.Printf("E! Removing segment %q failed: %v", s.path, err)
+	}
+	b.segments = b.segments[1:]
+	b.size -= s.size
+
+	b.headOffset = 0
+	if len(b.segments) > 0 {
+		b.head = b.segments[0].first
+	} else {
+		b.head = b.tail
+	}
+
+	// Forget about all removed entries before the new head
+	idx, _ := slices.BinarySearchFunc(b.mask, b.head, func(e segmentEntry, seq uint64) int {
+		return cmp.Compare(e.seq, seq)
+	})
+	b.mask = b.mask[idx:]
+}
+
+// remove marks the given entries as removed
+func (b *SegmentedDiskBuffer) remove(entries ...segmentEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	b.mask = append(b.mask, entries...)
+	slices.SortFunc(b.mask, func(x, y segmentEntry) int {
+		return cmp.Compare(x.seq, y.seq)
+	})
+}
+
+func (b *SegmentedDiskBuffer) isRemoved(seq uint64) bool {
+	_, found := slices.BinarySearchFunc(b.mask, seq, func(e segmentEntry, seq uint64) int {
+		return cmp.Compare(e.seq, seq)
+	})
+	return found
+}
+
+// advance moves the head over all removed entries at the front of the buffer
+// and deletes fully consumed segments
+func (b *SegmentedDiskBuffer) advance() {
+	var moved bool
+	for len(b.segments) > 0 {
+		if b.head >= b.segments[0].end() {
+			if len(b.segments) == 1 {
+				if err := b.closeWriter(); err != nil {
+					log.Printf("E! Closing segment failed: %v", err)
+				}
+			}
+			b.dropSegment()
+			moved = true
+			continue
+		}
+
+		if len(b.mask) == 0 || b.mask[0].seq != b.head {
+			break
+		}
+		b.headOffset += b.mask[0].size
+		b.head++
+		b.mask = b.mask[1:]
+		moved = true
+	}
+
+	// Persist the head and the removed entries after the head
+	if moved || len(b.mask) > 0 {
+		if err := b.storeHead(); err != nil {
+			log.Printf("E! Storing buffer head failed: %v", err)
+		}
+	}
+}
+
+// iterate calls the given function for all entries starting at the head of
+// the buffer until the function returns false
+func (b *SegmentedDiskBuffer) iterate(fn func(seq uint64, size int64, data []byte) bool) error {
+	for i, s := range b.segments {
+		start, offset := s.first, int64(0)
+		if i == 0 {
+			start, offset = b.head, b.headOffset
+		}
+
+		var stopped bool
+		err := b.iterateSegment(s, start, offset, func(seq uint64, size int64, data []byte) bool {
+			stopped = !fn(seq, size, data)
+			return !stopped
+		})
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// iterateSegment calls the given function for all entries in the segment
+// starting at the given sequence number and file offset
+func (*SegmentedDiskBuffer) iterateSegment(s *segment, start uint64, offset int64, fn func(seq uint64, size int64, data []byte) bool) error {
+	if start >= s.end() {
+		return nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(f)
+	remaining := s.size - offset
+	for seq := start; seq < s.end(); seq++ {
+		data, err := readRecord(reader, remaining)
+		if err != nil {
+			return fmt.Errorf("reading entry %d of %q failed: %w", seq, s.path, err)
+		}
+		size := int64(len(data) + segmentRecordHeaderSize)
+		remaining -= size
+		if !fn(seq, size, data) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// restore loads the segments and head position from disk
+func (b *SegmentedDiskBuffer) restore() error {
+	entries, err := os.ReadDir(b.path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileExtension) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(name, segmentFileExtension), 10, 64)
+		if err != nil {
+			log.Printf("W! Ignoring invalid segment file %q in buffer directory", name)
+			continue
+		}
+
+		s, err := scanSegment(filepath.Join(b.path, name), first)
+		if err != nil {
+			return err
+		}
+		if s.count == 0 {
+			if err := os.Remove(s.path); err != nil {
+				return err
+			}
+			continue
+		}
+		b.segments = append(b.segments, s)
+		b.size += s.size
+	}
+	slices.SortFunc(b.segments, func(x, y *segment) int {
+		return cmp.Compare(x.first, y.first)
+	})
+	for i := 1; i < len(b.segments); i++ {
+		if b.segments[i].first < b.segments[i-1].end() {
+			return fmt.Errorf("segment %q overlaps with previous segment", b.segments[i].path)
+		}
+	}
+
+	head, removed, err := b.loadHead()
+	if err != nil {
+		return err
+	}
+
+	if len(b.segments) == 0 {
+		b.head, b.tail = head, head
+		return nil
+	}
+	b.tail = b.segments[len(b.segments)-1].end()
+
+	// Remove the segments that were already fully consumed and find the
+	// offset of the head entry in the first remaining segment
+	b.head = b.segments[0].first
+	for len(b.segments) > 0 && head >= b.segments[0].end() {
+		b.dropSegment()
+	}
+	if len(b.segments) == 0 {
+		return nil
+	}
+	if head > b.head {
+		err := b.iterateSegment(b.segments[0], b.head, 0, func(seq uint64, size int64, _ []byte) bool {
+			if seq >= head {
+				return false
+			}
+			b.headOffset += size
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		b.head = head
+	}
+
+	// Continue writing to the last segment
+	last := b.segments[len(b.segments)-1]
+	f, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	b.writer = f
+
+	// Restore the entries removed after the head in the previous run
+	if len(removed) == 0 {
+		return nil
+	}
+	err = b.iterate(func(seq uint64, size int64, _ []byte) bool {
+		if _, found := slices.BinarySearch(removed, seq); found {
+			b.mask = append(b.mask, segmentEntry{seq: seq, size: size})
+		}
+		return seq < removed[len(removed)-1]
+	})
+	if err != nil {
+		return err
+	}
+	b.advance()
+
+	return nil
+}
+
+// loadHead reads the sequence number of the head entry and the sorted
+// sequence numbers of the entries removed after the head
+func (b *SegmentedDiskBuffer) loadHead() (uint64, []uint64, error) {
+	buf, err := os.ReadFile(filepath.Join(b.path, segmentHeadFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+
+	lines := strings.Fields(string(buf))
+	if len(lines) == 0 {
+		return 0, nil, errors.New("parsing buffer head failed: empty file")
+	}
+	head, err := strconv.ParseUint(lines[0], 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parsing buffer head failed: %w", err)
+	}
+	removed := make([]uint64, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		seq, err := strconv.ParseUint(line, 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("parsing removed buffer entry failed: %w", err)
+		}
+		removed = append(removed, seq)
+	}
+	slices.Sort(removed)
+
+	return head, removed, nil
+}
+
+// storeHead atomically persists the sequence number of the head entry
+// followed by the sequence numbers of the removed entries, one per line
+func (b *SegmentedDiskBuffer) storeHead() error {
+	var buf strings.Builder
+	buf.WriteString(strconv.FormatUint(b.head, 10))
+	for _, e := range b.mask {
+		buf.WriteByte('\n')
+		buf.WriteString(strconv.FormatUint(e.seq, 10))
+	}
+
+	fn := filepath.Join(b.path, segmentHeadFile)
+	tmp := fn + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+// scanSegment determines the number of valid entries in the given segment
+// file and truncates incomplete or corrupt records at the end of the file
+// e.g. caused by a crash.
+func scanSegment(path string, first uint64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0640)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &segment{path: path, first: first}
+	reader := bufio.NewReader(f)
+	for {
+		data, err := readRecord(reader, stat.Size()-s.size)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errCorruptRecord) {
+			log.Printf("W! Truncating incomplete data at offset %d in segment %q", s.size, path)
+			if err := f.Truncate(s.size); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.count++
+		s.size += int64(len(data) + segmentRecordHeaderSize)
+	}
+
+	return s, nil
+}
+
+// readRecord reads a single record of at most the given size returning io.EOF
+// if the end of the segment is reached and io.ErrUnexpectedEOF for incomplete
+// records.
+func readRecord(r io.Reader, limit int64) ([]byte, error) {
+	var header [segmentRecordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	if int64(length)+segmentRecordHeaderSize > limit {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != checksum {
+		return nil, errCorruptRecord
+	}
+	return data, nil
+}
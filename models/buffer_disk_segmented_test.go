@@ -0,0 +1,282 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newSegmentedTestBuffer(t *testing.T, path string, settings BufferSettings) *SegmentedDiskBuffer {
+	t.Helper()
+
+	buf, err := NewBufferWithSettings("test", "id123", "", 0, "disk_segmented", path, settings)
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsWritten.Set(0)
+	buf.Stats().MetricsRejected.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+
+	diskBuf, ok := buf.(*SegmentedDiskBuffer)
+	require.True(t, ok, "buffer is not a segmented disk buffer")
+	return diskBuf
+}
+
+func segmentedTestMetrics(t *testing.T, count int) ([]telegraf.Metric, int64) {
+	t.Helper()
+
+	registerGob()
+
+	metrics := make([]telegraf.Metric, 0, count)
+	for i := range count {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(i)}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+
+	// Determine the size of a record on disk
+	data, err := metric.ToBytes(metrics[0])
+	require.NoError(t, err)
+
+	return metrics, int64(len(data) + segmentRecordHeaderSize)
+}
+
+func TestSegmentedDiskBufferInvalidPolicy(t *testing.T) {
+	_, err := NewBufferWithSettings("test", "id123", "", 0, "disk_segmented", t.TempDir(), BufferSettings{OverflowPolicy: "foo"})
+	require.ErrorContains(t, err, "invalid buffer overflow policy")
+}
+
+func TestSegmentedDiskBufferRotation(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	buf := newSegmentedTestBuffer(t, t.TempDir(), BufferSettings{SegmentSize: 3 * recordSize})
+	defer buf.Close()
+
+	buf.Add(metrics...)
+	require.Equal(t, 10, buf.Len())
+	require.Len(t, buf.segments, 4)
+	require.Equal(t, 10*recordSize, buf.size)
+
+	// Consuming the first segment should remove the file
+	first := buf.segments[0].path
+	tx := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, metrics[:3], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	require.Equal(t, 7, buf.Len())
+	require.Len(t, buf.segments, 3)
+	require.Equal(t, 7*recordSize, buf.size)
+	require.NoFileExists(t, first)
+
+	// Read the remaining metrics
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[3:], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	require.Equal(t, 0, buf.Len())
+	require.Empty(t, buf.segments)
+	require.Zero(t, buf.size)
+	require.Equal(t, int64(10), buf.Stats().MetricsWritten.Get())
+}
+
+func TestSegmentedDiskBufferDropOldest(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	settings := BufferSettings{
+		MaxSize:        8 * recordSize,
+		SegmentSize:    2 * recordSize,
+		OverflowPolicy: "drop_oldest",
+	}
+	buf := newSegmentedTestBuffer(t, t.TempDir(), settings)
+	defer buf.Close()
+
+	// Adding more metrics than fitting into the buffer should evict the
+	// oldest segment
+	dropped := buf.Add(metrics...)
+	require.Equal(t, 2, dropped)
+	require.Equal(t, 8, buf.Len())
+	require.LessOrEqual(t, buf.size, settings.MaxSize)
+	require.Equal(t, int64(10), buf.Stats().MetricsAdded.Get())
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+
+	tx := buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[2:], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+}
+
+func TestSegmentedDiskBufferDropNewest(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	settings := BufferSettings{
+		MaxSize:        8 * recordSize,
+		SegmentSize:    2 * recordSize,
+		OverflowPolicy: "drop_newest",
+	}
+	buf := newSegmentedTestBuffer(t, t.TempDir(), settings)
+	defer buf.Close()
+
+	// Adding more metrics than fitting into the buffer should reject the
+	// new metrics
+	dropped := buf.Add(metrics...)
+	require.Equal(t, 2, dropped)
+	require.Equal(t, 8, buf.Len())
+	require.Equal(t, int64(8), buf.Stats().MetricsAdded.Get())
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+
+	tx := buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[:8], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+}
+
+func TestSegmentedDiskBufferEvictDuringTransaction(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	settings := BufferSettings{
+		MaxSize:     4 * recordSize,
+		SegmentSize: recordSize,
+	}
+	buf := newSegmentedTestBuffer(t, t.TempDir(), settings)
+	defer buf.Close()
+
+	buf.Add(metrics[:4]...)
+	tx := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(t, metrics[:2], tx.Batch)
+
+	// Evict the metrics of the running transaction; those should not be
+	// accounted for until the transaction ends
+	dropped := buf.Add(metrics[4:6]...)
+	require.Zero(t, dropped)
+	require.Equal(t, 4, buf.Len())
+
+	// Keeping the evicted metrics must drop them
+	tx.KeepAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+	require.Equal(t, 4, buf.Len())
+
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[2:6], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, int64(4), buf.Stats().MetricsWritten.Get())
+}
+
+func TestSegmentedDiskBufferReopen(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	path := t.TempDir()
+	settings := BufferSettings{SegmentSize: 3 * recordSize}
+	buf := newSegmentedTestBuffer(t, path, settings)
+
+	buf.Add(metrics...)
+	tx := buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, metrics[:4], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.NoError(t, buf.Close())
+
+	// Reopen the buffer and make sure we continue where we left off
+	reopened := newSegmentedTestBuffer(t, path, settings)
+	defer reopened.Close()
+	require.Equal(t, 6, reopened.Len())
+
+	reopened.Add(metrics[0])
+	tx = reopened.BeginTransaction(10)
+	expected := append(append([]telegraf.Metric{}, metrics[4:]...), metrics[0])
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+	tx.AcceptAll()
+	reopened.EndTransaction(tx)
+	require.Equal(t, 0, reopened.Len())
+}
+
+func TestSegmentedDiskBufferReopenRemovedEntries(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 10)
+
+	path := t.TempDir()
+	settings := BufferSettings{SegmentSize: 3 * recordSize}
+	buf := newSegmentedTestBuffer(t, path, settings)
+
+	// Only accept metrics after the head so they cannot be dropped by
+	// advancing the head
+	buf.Add(metrics...)
+	tx := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, metrics[:5], tx.Batch)
+	tx.Accept = []int{1, 2, 4}
+	buf.EndTransaction(tx)
+	require.Equal(t, 7, buf.Len())
+	require.NoError(t, buf.Close())
+
+	// Reopen the buffer and make sure the accepted metrics are not sent again
+	reopened := newSegmentedTestBuffer(t, path, settings)
+	defer reopened.Close()
+	require.Equal(t, 7, reopened.Len())
+
+	tx = reopened.BeginTransaction(10)
+	expected := append([]telegraf.Metric{metrics[0], metrics[3]}, metrics[5:]...)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+	tx.AcceptAll()
+	reopened.EndTransaction(tx)
+	require.Equal(t, 0, reopened.Len())
+}
+
+func TestSegmentedDiskBufferEmptyClose(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 5)
+
+	path := t.TempDir()
+	buf := newSegmentedTestBuffer(t, path, BufferSettings{})
+	buf.Add(metrics...)
+
+	tx := buf.BeginTransaction(5)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+
+	// Closing an empty buffer should remove all files
+	require.NoError(t, buf.Close())
+	require.NoDirExists(t, filepath.Join(path, "id123"))
+
+	reopened := newSegmentedTestBuffer(t, path, BufferSettings{})
+	defer reopened.Close()
+	require.Equal(t, 0, reopened.Len())
+	require.Empty(t, reopened.BeginTransaction(5).Batch)
+}
+
+func TestSegmentedDiskBufferTruncatedSegment(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 3)
+
+	path := t.TempDir()
+	buf := newSegmentedTestBuffer(t, path, BufferSettings{})
+	buf.Add(metrics...)
+	require.NoError(t, buf.Close())
+
+	// Simulate a crash while writing a record
+	matches, err := filepath.Glob(filepath.Join(path, "id123", "*"+segmentFileExtension))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	f, err := os.OpenFile(matches[0], os.O_WRONLY|os.O_APPEND, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xff, 0x00, 0x00, 0x00, 0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The incomplete record should be ignored and new metrics must be readable
+	reopened := newSegmentedTestBuffer(t, path, BufferSettings{})
+	defer reopened.Close()
+	require.Equal(t, 3, reopened.Len())
+
+	reopened.Add(metrics[0])
+	tx := reopened.BeginTransaction(10)
+	expected := append(append([]telegraf.Metric{}, metrics...), metrics[0])
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+}
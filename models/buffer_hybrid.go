@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// HybridBuffer keeps metrics in memory as long as the output keeps up and
+// spills them to disk once the memory buffer is full. Metrics on disk are
+// older than the ones in memory and are thus written first.
+type HybridBuffer struct {
+	BufferStats
+	sync.Mutex
+
+	memory *MemoryBuffer
+	disk   *SegmentedDiskBuffer
+
+	// Currently running transaction and the buffer it originates from
+	tx     *Transaction
+	source Buffer
+}
+
+func NewHybridBuffer(id, path string, capacity int, settings BufferSettings, stats BufferStats) (*HybridBuffer, error) {
+	memory, err := NewMemoryBuffer(capacity, stats)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := NewSegmentedDiskBuffer(id, path, settings, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &HybridBuffer{
+		BufferStats: stats,
+		memory:      memory,
+		disk:        disk,
+	}
+	buf.BufferSize.Set(int64(buf.length()))
+
+	return buf, nil
+}
+
+func (b *HybridBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *HybridBuffer) length() int {
+	return b.memory.Len() + b.disk.Len()
+}
+
+func (b *HybridBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	dropped := 0
+	for len(metrics) > 0 {
+		if b.memory.free() == 0 {
+			dropped += b.spill()
+		}
+
+		n := min(b.memory.free(), len(metrics))
+		if n == 0 {
+			// The memory buffer is completely occupied by the running
+			// transaction, so directly write the metrics to disk
+			dropped += b.disk.Add(metrics...)
+			break
+		}
+		dropped += b.memory.Add(metrics[:n]...)
+		metrics = metrics[n:]
+	}
+
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+func (b *HybridBuffer) BeginTransaction(batchSize int) *Transaction {
+	b.Lock()
+	defer b.Unlock()
+
+	// Replay the metrics from disk first as those are the oldest ones
+	var tx *Transaction
+	if b.disk.Len() > 0 {
+		tx = b.disk.BeginTransaction(batchSize)
+		b.source = b.disk
+	}
+	if tx == nil || len(tx.Batch) == 0 {
+		tx = b.memory.BeginTransaction(batchSize)
+		b.source = b.memory
+	}
+	b.tx = tx
+	b.BufferSize.Set(int64(b.length()))
+
+	return tx
+}
+
+func (b *HybridBuffer) EndTransaction(tx *Transaction) {
+	b.Lock()
+	defer b.Unlock()
+
+	// Ignore transactions not started by this buffer or already finished
+	if tx != b.tx || b.source == nil {
+		return
+	}
+
+	b.source.EndTransaction(tx)
+	b.tx = nil
+	b.source = nil
+
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *HybridBuffer) Stats() BufferStats {
+	return b.BufferStats
+}
+
+// Fill reports the fill level of the disk part as the memory part spills to
+// disk when full and metrics are only dropped once the disk part is full
+func (b *HybridBuffer) Fill() (float64, bool) {
+	return b.disk.Fill()
+}
+
+func (b *HybridBuffer) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	// Persist the metrics kept in memory to not lose them on shutdown
+	b.spill()
+
+	return errors.Join(
+		b.memory.Close(),
+		b.disk.Close(),
+	)
+}
+
+// spill moves all metrics in memory not being part of a running transaction
+// to disk and returns the number of metrics dropped in this process
+func (b *HybridBuffer) spill() int {
+	metrics := b.memory.drain()
+	if len(metrics) == 0 {
+		return 0
+	}
+	return b.disk.spill(metrics)
+}
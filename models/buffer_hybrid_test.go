@@ -0,0 +1,159 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newHybridTestBuffer(t *testing.T, path string, capacity int) *HybridBuffer {
+	t.Helper()
+
+	buf, err := NewBuffer("test", "id123", "", capacity, "hybrid", path)
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsWritten.Set(0)
+	buf.Stats().MetricsRejected.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+
+	hybridBuf, ok := buf.(*HybridBuffer)
+	require.True(t, ok, "buffer is not a hybrid buffer")
+	return hybridBuf
+}
+
+func TestHybridBufferMemoryOnly(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 6)
+
+	buf := newHybridTestBuffer(t, t.TempDir(), 5)
+	defer buf.Close()
+
+	// As long as the output keeps up nothing should be written to disk
+	for i := 0; i < len(metrics); i += 3 {
+		buf.Add(metrics[i : i+3]...)
+		require.Equal(t, 3, buf.memory.Len())
+		require.Equal(t, 0, buf.disk.Len())
+		require.Empty(t, buf.disk.segments)
+
+		tx := buf.BeginTransaction(5)
+		testutil.RequireMetricsEqual(t, metrics[i:i+3], tx.Batch)
+		tx.AcceptAll()
+		buf.EndTransaction(tx)
+	}
+
+	require.Equal(t, int64(6), buf.Stats().MetricsAdded.Get())
+	require.Equal(t, int64(6), buf.Stats().MetricsWritten.Get())
+	require.Equal(t, int64(0), buf.Stats().MetricsDropped.Get())
+}
+
+func TestHybridBufferSpill(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 8)
+
+	buf := newHybridTestBuffer(t, t.TempDir(), 5)
+	defer buf.Close()
+
+	// Exceeding the memory capacity should move the oldest metrics to disk
+	// instead of dropping them
+	dropped := buf.Add(metrics...)
+	require.Zero(t, dropped)
+	require.Equal(t, 8, buf.Len())
+	require.Equal(t, 3, buf.memory.Len())
+	require.Equal(t, 5, buf.disk.Len())
+	require.Equal(t, int64(8), buf.Stats().MetricsAdded.Get())
+
+	// Metrics on disk must be replayed first
+	tx := buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[:5], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[5:], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	require.Equal(t, 0, buf.Len())
+	require.Equal(t, int64(8), buf.Stats().MetricsWritten.Get())
+	require.Equal(t, int64(0), buf.Stats().MetricsDropped.Get())
+}
+
+func TestHybridBufferSpillDuringTransaction(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 10)
+
+	buf := newHybridTestBuffer(t, t.TempDir(), 5)
+	defer buf.Close()
+
+	buf.Add(metrics[:5]...)
+	tx := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, metrics[:5], tx.Batch)
+
+	// Metrics added while the transaction occupies the memory must go to disk
+	buf.Add(metrics[5:]...)
+	require.Equal(t, 10, buf.Len())
+
+	// Keeping the batch must not drop any metric
+	tx.KeepAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 10, buf.Len())
+	require.Equal(t, int64(0), buf.Stats().MetricsDropped.Get())
+
+	var actual []telegraf.Metric
+	for buf.Len() > 0 {
+		tx := buf.BeginTransaction(5)
+		actual = append(actual, tx.Batch...)
+		tx.AcceptAll()
+		buf.EndTransaction(tx)
+	}
+	testutil.RequireMetricsEqual(t, metrics, actual, testutil.SortMetrics())
+}
+
+func TestHybridBufferCloseSpills(t *testing.T) {
+	metrics, _ := segmentedTestMetrics(t, 3)
+
+	path := t.TempDir()
+	buf := newHybridTestBuffer(t, path, 5)
+	buf.Add(metrics...)
+	require.Equal(t, 0, buf.disk.Len())
+
+	// Closing the buffer must persist the metrics in memory
+	require.NoError(t, buf.Close())
+
+	reopened := newHybridTestBuffer(t, path, 5)
+	defer reopened.Close()
+	require.Equal(t, 3, reopened.Len())
+	require.Equal(t, 3, reopened.disk.Len())
+
+	tx := reopened.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, metrics, tx.Batch)
+	tx.AcceptAll()
+	reopened.EndTransaction(tx)
+	require.Equal(t, 0, reopened.Len())
+}
+
+func TestHybridBufferFill(t *testing.T) {
+	metrics, recordSize := segmentedTestMetrics(t, 4)
+
+	settings := BufferSettings{MaxSize: 100 * recordSize}
+	b, err := NewBufferWithSettings("test", "id123", "", 2, "hybrid", t.TempDir(), settings)
+	require.NoError(t, err)
+	defer b.Close()
+
+	// Metrics in memory do not count as those are spilled to disk if needed
+	b.Add(metrics[:2]...)
+	fill, ok := b.Fill()
+	require.True(t, ok)
+	require.Zero(t, fill)
+
+	b.Add(metrics[2:]...)
+	fill, ok = b.Fill()
+	require.True(t, ok)
+	require.InDelta(t, 2.0, fill, 0)
+
+	// Without a size limit the buffer is unlimited
+	unlimited := newHybridTestBuffer(t, t.TempDir(), 2)
+	defer unlimited.Close()
+	_, ok = unlimited.Fill()
+	require.False(t, ok)
+}
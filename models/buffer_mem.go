@@ -119,6 +119,35 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 	b.BufferSize.Set(int64(b.length()))
 }
 
+// free returns the number of metrics that can be added without dropping any
+// metric, including those of a running transaction.
+func (b *MemoryBuffer) free() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return max(b.cap-b.size-b.batchSize, 0)
+}
+
+// drain removes all metrics not being part of a running transaction from the
+// buffer without accounting for them and returns those metrics ordered from
+// oldest to newest.
+func (b *MemoryBuffer) drain() []telegraf.Metric {
+	b.Lock()
+	defer b.Unlock()
+
+	metrics := make([]telegraf.Metric, 0, b.size)
+	current := b.first
+	for range b.size {
+		metrics = append(metrics, b.buf[current])
+		b.buf[current] = nil
+		current = b.next(current)
+	}
+	b.first = b.last
+	b.size = 0
+
+	return metrics
+}
+
 func (*MemoryBuffer) Close() error {
 	return nil
 }
@@ -127,6 +156,16 @@ func (b *MemoryBuffer) Stats() BufferStats {
 	return b.BufferStats
 }
 
+func (b *MemoryBuffer) Fill() (float64, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.cap <= 0 {
+		return 0, false
+	}
+	return 100.0 * float64(b.length()) / float64(b.cap), true
+}
+
 func (b *MemoryBuffer) length() int {
 	return min(b.size+b.batchSize, b.cap)
 }
@@ -24,7 +24,7 @@ func (s *BufferSuiteTest) SetupTest() {
 	switch s.bufferType {
 	case "", "memory":
 		s.hasMaxCapacity = true
-	case "disk_write_through":
+	case "disk_write_through", "disk_segmented", "hybrid":
 		path, err := os.MkdirTemp("", "*-buffer-test")
 		s.Require().NoError(err)
 		s.bufferPath = path
@@ -47,6 +47,14 @@ func TestDiskBufferSuite(t *testing.T) {
 	suite.Run(t, &BufferSuiteTest{bufferType: "disk_write_through"})
 }
 
+func TestSegmentedDiskBufferSuite(t *testing.T) {
+	suite.Run(t, &BufferSuiteTest{bufferType: "disk_segmented"})
+}
+
+func TestHybridBufferSuite(t *testing.T) {
+	suite.Run(t, &BufferSuiteTest{bufferType: "hybrid"})
+}
+
 func (s *BufferSuiteTest) newTestBuffer(capacity int) Buffer {
 	s.T().Helper()
 	buf, err := NewBuffer("test", "123", "", capacity, s.bufferType, s.bufferPath)
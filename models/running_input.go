@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -30,6 +32,11 @@ type RunningInput struct {
 	gatherStart time.Time
 	gatherEnd   time.Time
 
+	lastGather      GatherStatus
+	lastGatherMutex sync.Mutex
+	gatherRequest   chan struct{}
+	paused          atomic.Bool
+
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
@@ -54,8 +61,9 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 	SetLoggerOnPlugin(input, logger)
 
 	return &RunningInput{
-		Input:  input,
-		Config: config,
+		Input:         input,
+		Config:        config,
+		gatherRequest: make(chan struct{}, 1),
 		MetricsGathered: selfstat.Register(
 			"gather",
 			"metrics_gathered",
@@ -80,6 +88,16 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 	}
 }
 
+// GatherStatus describes the outcome of the last gather cycle of an input.
+type GatherStatus struct {
+	Time     time.Time
+	Duration time.Duration
+	Err      error
+
+	// LastSuccess is the start time of the last gather cycle without error
+	LastSuccess time.Time
+}
+
 // InputConfig is the common config for all inputs.
 type InputConfig struct {
 	Name                 string
@@ -93,6 +111,7 @@ type InputConfig struct {
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
+	Route                string
 
 	NameOverride            string
 	MeasurementPrefix       string
@@ -256,14 +275,67 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	gacc := &gatherAccumulator{Accumulator: acc}
 	r.gatherStart = time.Now()
-	err := r.Input.Gather(acc)
+	err := r.Input.Gather(gacc)
 	r.gatherEnd = time.Now()
 
-	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+	status := GatherStatus{
+		Time:     r.gatherStart,
+		Duration: r.gatherEnd.Sub(r.gatherStart),
+		Err:      err,
+	}
+	if status.Err == nil {
+		status.Err = gacc.lastError()
+	}
+	r.lastGatherMutex.Lock()
+	status.LastSuccess = r.lastGather.LastSuccess
+	if status.Err == nil {
+		status.LastSuccess = status.Time
+	}
+	r.lastGather = status
+	r.lastGatherMutex.Unlock()
+
+	r.GatherTime.Incr(status.Duration.Nanoseconds())
 	return err
 }
 
+// LastGather returns the status of the last gather cycle
+func (r *RunningInput) LastGather() GatherStatus {
+	r.lastGatherMutex.Lock()
+	defer r.lastGatherMutex.Unlock()
+	return r.lastGather
+}
+
+// RequestGather schedules an immediate gather cycle of the input
+func (r *RunningInput) RequestGather() {
+	select {
+	case r.gatherRequest <- struct{}{}:
+	default:
+	}
+}
+
+// GatherRequested returns a channel signaling requests for an immediate
+// gather cycle
+func (r *RunningInput) GatherRequested() <-chan struct{} {
+	return r.gatherRequest
+}
+
+// Pause suspends the periodic gathering of the input
+func (r *RunningInput) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume continues the periodic gathering of the input
+func (r *RunningInput) Resume() {
+	r.paused.Store(false)
+}
+
+// Paused returns true if periodic gathering is suspended
+func (r *RunningInput) Paused() bool {
+	return r.paused.Load()
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
@@ -276,3 +348,26 @@ func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
 }
+
+// gatherAccumulator records the last error reported during a gather cycle
+type gatherAccumulator struct {
+	telegraf.Accumulator
+
+	err error
+	sync.Mutex
+}
+
+func (a *gatherAccumulator) AddError(err error) {
+	if err != nil {
+		a.Lock()
+		a.err = err
+		a.Unlock()
+	}
+	a.Accumulator.AddError(err)
+}
+
+func (a *gatherAccumulator) lastError() error {
+	a.Lock()
+	defer a.Unlock()
+	return a.err
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -39,8 +40,18 @@ type OutputConfig struct {
 	NamePrefix   string
 	NameSuffix   string
 
-	BufferStrategy  string
-	BufferDirectory string
+	BufferStrategy       string
+	BufferDirectory      string
+	BufferMaxSize        int64
+	BufferSegmentSize    int64
+	BufferOverflowPolicy string
+
+	DeadLetterFile   string
+	DeadLetterOutput string
+
+	// Routes the output subscribes to, outputs without routes receive the
+	// metrics not assigned to any route.
+	Routes []string
 
 	LogLevel string
 }
@@ -56,9 +67,10 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
-	MetricsFiltered selfstat.Stat
-	WriteTime       selfstat.Stat
-	StartupErrors   selfstat.Stat
+	MetricsFiltered     selfstat.Stat
+	MetricsDeadLettered selfstat.Stat
+	WriteTime           selfstat.Stat
+	StartupErrors       selfstat.Stat
 
 	BatchReady chan time.Time
 
@@ -68,6 +80,13 @@ type RunningOutput struct {
 	started bool
 	retries uint64
 
+	flushRequest        chan struct{}
+	paused              atomic.Bool
+	consecutiveFailures atomic.Int64
+
+	deadLetterFile   *deadLetterFile
+	deadLetterOutput atomic.Pointer[RunningOutput]
+
 	aggMutex sync.Mutex
 }
 
@@ -100,7 +119,12 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
+	settings := BufferSettings{
+		MaxSize:        config.BufferMaxSize,
+		SegmentSize:    config.BufferSegmentSize,
+		OverflowPolicy: config.BufferOverflowPolicy,
+	}
+	b, err := NewBufferWithSettings(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory, settings)
 	if err != nil {
 		panic(err)
 	}
@@ -108,6 +132,7 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 	ro := &RunningOutput{
 		buffer:            b,
 		BatchReady:        make(chan time.Time, 1),
+		flushRequest:      make(chan struct{}, 1),
 		Output:            output,
 		Config:            config,
 		MetricBufferLimit: bufferLimit,
@@ -117,6 +142,11 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"metrics_filtered",
 			tags,
 		),
+		MetricsDeadLettered: selfstat.Register(
+			"write",
+			"metrics_dead_lettered",
+			tags,
+		),
 		WriteTime: selfstat.RegisterTiming(
 			"write",
 			"write_time_ns",
@@ -137,6 +167,15 @@ func (r *RunningOutput) LogName() string {
 	return logName("outputs", r.Config.Name, r.Config.Alias)
 }
 
+// SubscribesTo returns true if the output receives the metrics of the given
+// route. An empty route denotes the metrics not assigned to any route.
+func (r *RunningOutput) SubscribesTo(route string) bool {
+	if len(r.Config.Routes) == 0 {
+		return route == ""
+	}
+	return slices.Contains(r.Config.Routes, route)
+}
+
 func (r *RunningOutput) metricFiltered(metric telegraf.Metric) {
 	r.MetricsFiltered.Incr(1)
 	metric.Drop()
@@ -162,6 +201,14 @@ func (r *RunningOutput) Init() error {
 			return err
 		}
 	}
+
+	if r.Config.DeadLetterFile != "" && r.deadLetterFile == nil {
+		f, err := newDeadLetterFile(r.Config.DeadLetterFile)
+		if err != nil {
+			return err
+		}
+		r.deadLetterFile = f
+	}
 	return nil
 }
 
@@ -204,6 +251,12 @@ func (r *RunningOutput) Close() {
 	if err := r.buffer.Close(); err != nil {
 		r.log.Errorf("Error closing output buffer: %v", err)
 	}
+
+	if r.deadLetterFile != nil {
+		if err := r.deadLetterFile.close(); err != nil {
+			r.log.Errorf("Error closing dead-letter file: %v", err)
+		}
+	}
 }
 
 // AddMetric adds a metric to the output.
@@ -357,13 +410,27 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	elapsed := time.Since(start)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
 
+	// Partial writes are successful writes as the output received the metrics
+	var writeErr *internal.PartialWriteError
+	if err == nil || errors.As(err, &writeErr) {
+		r.consecutiveFailures.Store(0)
+	} else {
+		r.consecutiveFailures.Add(1)
+	}
+
 	if err == nil {
 		r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
 	}
 	return err
 }
 
-func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
+// ConsecutiveFailures returns the number of failed writes since the last
+// successful write.
+func (r *RunningOutput) ConsecutiveFailures() int64 {
+	return r.consecutiveFailures.Load()
+}
+
+func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// No error indicates all metrics were written successfully
 	if err == nil {
 		tx.AcceptAll()
@@ -381,17 +448,50 @@ func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// Transfer the accepted and rejected indices based on the write error values
 	tx.Accept = writeErr.MetricsAccept
 	tx.Reject = writeErr.MetricsReject
+
+	// Route the rejected metrics to the dead-letter sinks
+	r.deadLetter(tx, writeErr)
 }
 
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
-	if r.Config.BufferStrategy == "disk_write_through" {
+	switch r.Config.BufferStrategy {
+	case "disk_write_through", "disk_segmented", "hybrid":
 		r.log.Debugf("Buffer fullness: %d metrics", nBuffer)
-	} else {
+	default:
 		r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
 	}
 }
 
+// RequestFlush schedules an immediate flush of the output
+func (r *RunningOutput) RequestFlush() {
+	select {
+	case r.flushRequest <- struct{}{}:
+	default:
+	}
+}
+
+// FlushRequested returns a channel signaling requests for an immediate flush
+func (r *RunningOutput) FlushRequested() <-chan struct{} {
+	return r.flushRequest
+}
+
+// Pause suspends the periodic flushing of the output, metrics are still
+// buffered
+func (r *RunningOutput) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume continues the periodic flushing of the output
+func (r *RunningOutput) Resume() {
+	r.paused.Store(false)
+}
+
+// Paused returns true if periodic flushing is suspended
+func (r *RunningOutput) Paused() bool {
+	return r.paused.Load()
+}
+
 func (r *RunningOutput) Log() telegraf.Logger {
 	return r.log
 }
@@ -399,3 +499,9 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// BufferFill returns the fill level of the buffer in percent of its capacity
+// and false if the buffer does not have a capacity limit.
+func (r *RunningOutput) BufferFill() (float64, bool) {
+	return r.buffer.Fill()
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// DeadLetterReasonTag is the tag added to dead-letter metrics containing the
+// reason for rejecting the metric.
+const DeadLetterReasonTag = "dead_letter_reason"
+
+// deadLetterFile appends the dead-letter metrics in line-protocol format to
+// a file.
+type deadLetterFile struct {
+	file       *os.File
+	serializer *influx.Serializer
+
+	sync.Mutex
+}
+
+func newDeadLetterFile(filename string) (*deadLetterFile, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("opening dead-letter file failed: %w", err)
+	}
+
+	serializer := &influx.Serializer{UintSupport: true, SortFields: true}
+	if err := serializer.Init(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("initializing dead-letter serializer failed: %w", err)
+	}
+
+	return &deadLetterFile{file: f, serializer: serializer}, nil
+}
+
+func (d *deadLetterFile) write(m telegraf.Metric) error {
+	d.Lock()
+	defer d.Unlock()
+
+	buf, err := d.serializer.Serialize(m)
+	if err != nil {
+		return err
+	}
+	_, err = d.file.Write(buf)
+	return err
+}
+
+func (d *deadLetterFile) close() error {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.file.Close()
+}
+
+// SetDeadLetterOutput sets the output receiving the metrics rejected by this
+// output. Setting nil disables forwarding rejected metrics to an output.
+func (r *RunningOutput) SetDeadLetterOutput(output *RunningOutput) {
+	r.deadLetterOutput.Store(output)
+}
+
+// DeadLetterOutput returns the output receiving the metrics rejected by this
+// output if any.
+func (r *RunningOutput) DeadLetterOutput() *RunningOutput {
+	return r.deadLetterOutput.Load()
+}
+
+// addDeadLetter adds a metric rejected by another output to the buffer. The
+// metric bypasses filtering and name modifications to keep the original
+// metric for replay.
+func (r *RunningOutput) addDeadLetter(m telegraf.Metric) {
+	dropped := r.buffer.Add(m)
+	atomic.AddInt64(&r.droppedMetrics, int64(dropped))
+}
+
+// deadLetter routes the rejected metrics of the transaction to the configured
+// dead-letter sinks with the rejection reason attached as tag.
+func (r *RunningOutput) deadLetter(tx *Transaction, writeErr *internal.PartialWriteError) {
+	output := r.deadLetterOutput.Load()
+	if output == nil && r.deadLetterFile == nil {
+		return
+	}
+
+	for i, idx := range tx.Reject {
+		if idx < 0 || idx >= len(tx.Batch) {
+			continue
+		}
+
+		// Use the error for the specific metric if available
+		reason := writeErr.Err
+		if len(writeErr.MetricsRejectErrors) == len(writeErr.MetricsReject) {
+			reason = writeErr.MetricsRejectErrors[i]
+		}
+		if reason == nil {
+			reason = errors.New("rejected by output")
+		}
+
+		// Do not hand out tracking metrics as the original metric is rejected
+		// and thus already reported to the input.
+		m := tx.Batch[idx]
+		if um, ok := m.(telegraf.UnwrappableMetric); ok {
+			m = um.Unwrap()
+		}
+		m = m.Copy()
+		m.AddTag(DeadLetterReasonTag, reason.Error())
+
+		if r.deadLetterFile != nil {
+			if err := r.deadLetterFile.write(m); err != nil {
+				r.log.Errorf("Writing dead-letter metric failed: %v", err)
+			}
+		}
+		if output != nil {
+			output.addDeadLetter(m)
+		}
+		r.MetricsDeadLettered.Incr(1)
+	}
+}
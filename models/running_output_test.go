@@ -2,6 +2,9 @@ package models
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -431,16 +434,17 @@ func TestRunningOutputInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
-				"buffer_limit":     10,
-				"buffer_size":      0,
-				"errors":           0,
-				"metrics_added":    0,
-				"metrics_rejected": 0,
-				"metrics_dropped":  0,
-				"metrics_filtered": 0,
-				"metrics_written":  0,
-				"write_time_ns":    0,
-				"startup_errors":   0,
+				"buffer_limit":          10,
+				"buffer_size":           0,
+				"errors":                0,
+				"metrics_added":         0,
+				"metrics_rejected":      0,
+				"metrics_dropped":       0,
+				"metrics_filtered":      0,
+				"metrics_dead_lettered": 0,
+				"metrics_written":       0,
+				"write_time_ns":         0,
+				"startup_errors":        0,
 			},
 			time.Unix(0, 0),
 		),
@@ -864,6 +868,63 @@ func TestRunningOutputWriteBatchPartialSuccessAndLoss(t *testing.T) {
 	require.Zero(t, model.buffer.Len())
 }
 
+func TestRunningOutputDeadLetter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dead_letter.influx")
+
+	rejected := 0
+	plugin := &mockOutput{
+		batchAcceptSize:  4,
+		metricFatalIndex: &rejected,
+	}
+	model := NewRunningOutput(plugin, &OutputConfig{DeadLetterFile: filename}, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+
+	target := NewRunningOutput(&mockOutput{}, &OutputConfig{Alias: "dlq"}, 5, 10)
+	require.NoError(t, target.Init())
+	require.NoError(t, target.Connect())
+	defer target.Close()
+	model.SetDeadLetterOutput(target)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The rejected metric should be routed to the file and the target output
+	// with the reason attached
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+	require.Equal(t, int64(1), model.MetricsDeadLettered.Get())
+
+	expected := first5[0].Copy()
+	expected.AddTag(DeadLetterReasonTag, internal.ErrSizeLimitReached.Error())
+	require.NoError(t, target.Write())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, target.Output.(*mockOutput).Metrics())
+
+	model.Close()
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(buf), "\n"))
+	require.Contains(t, string(buf), "metric1,"+DeadLetterReasonTag+"=")
+}
+
+func TestRunningOutputConsecutiveFailures(t *testing.T) {
+	plugin := &mockOutput{batchAcceptSize: -1}
+	model := NewRunningOutput(plugin, &OutputConfig{}, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	model.AddMetric(first5[0])
+	require.Error(t, model.Write())
+	require.Error(t, model.Write())
+	require.Equal(t, int64(2), model.ConsecutiveFailures())
+
+	// A successful write resets the failures
+	plugin.batchAcceptSize = 0
+	require.NoError(t, model.Write())
+	require.Zero(t, model.ConsecutiveFailures())
+}
+
 // Benchmark adding metrics.
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
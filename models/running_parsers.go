@@ -1,6 +1,7 @@
 package models
 
 import (
+	"io"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -83,6 +84,41 @@ func (r *RunningParser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return m, err
 }
 
+// ParseStream uses the streaming interface of the parser if implemented and
+// falls back to reading the data at once and calling Parse otherwise.
+func (r *RunningParser) ParseStream(reader io.Reader, fn func(telegraf.Metric) error) error {
+	parser, ok := r.Parser.(telegraf.StreamingParser)
+	if !ok {
+		buf, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		metrics, err := r.Parse(buf)
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Exclude the time spent in the function from the parse time
+	var consumed time.Duration
+	start := time.Now()
+	err := parser.ParseStream(reader, func(m telegraf.Metric) error {
+		r.MetricsParsed.Incr(1)
+		begin := time.Now()
+		defer func() { consumed += time.Since(begin) }()
+		return fn(m)
+	})
+	r.ParseTime.Incr((time.Since(start) - consumed).Nanoseconds())
+
+	return err
+}
+
 func (r *RunningParser) ParseLine(line string) (telegraf.Metric, error) {
 	start := time.Now()
 	m, err := r.Parser.ParseLine(line)
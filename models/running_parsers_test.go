@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestRunningParserParseStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser telegraf.Parser
+	}{
+		{
+			name:   "non-streaming parser",
+			parser: &mockParser{},
+		},
+		{
+			name:   "streaming parser",
+			parser: &mockStreamingParser{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := models.NewRunningParser(tt.parser, &models.ParserConfig{DataFormat: "mock", Alias: tt.name})
+			require.NoError(t, rp.Init())
+			parsed := rp.MetricsParsed.Get()
+
+			var names []string
+			err := rp.ParseStream(strings.NewReader("a\nb\nc\n"), func(m telegraf.Metric) error {
+				names = append(names, m.Name())
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"a", "b", "c"}, names)
+			require.Equal(t, int64(3), rp.MetricsParsed.Get()-parsed)
+
+			// Errors of the function are passed through
+			stop := errors.New("stop")
+			err = rp.ParseStream(strings.NewReader("a\nb\n"), func(telegraf.Metric) error { return stop })
+			require.ErrorIs(t, err, stop)
+		})
+	}
+}
+
+// mockParser creates a metric named after each line of the input
+type mockParser struct{}
+
+func (*mockParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	for _, line := range strings.Fields(string(buf)) {
+		metrics = append(metrics, metric.New(line, nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	}
+	return metrics, nil
+}
+
+func (p *mockParser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil || len(metrics) == 0 {
+		return nil, err
+	}
+	return metrics[0], nil
+}
+
+func (*mockParser) SetDefaultTags(map[string]string) {}
+
+type mockStreamingParser struct {
+	mockParser
+}
+
+func (*mockStreamingParser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := fn(metric.New(scanner.Text(), nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -30,6 +30,7 @@ type ProcessorConfig struct {
 	Order    int64
 	Filter   Filter
 	LogLevel string
+	Route    string
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -109,6 +110,11 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
+	// Serialize the calls with accessing the plugin state e.g. when
+	// checkpointing the state while running
+	rp.Lock()
+	defer rp.Unlock()
+
 	return rp.Processor.Add(m, acc)
 }
 
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// InputStatus is the status of a running input.
+type InputStatus struct {
+	ID         string
+	Name       string
+	Alias      string
+	Interval   time.Duration
+	Paused     bool
+	LastGather GatherStatus
+}
+
+// OutputStatus is the status of a running output.
+type OutputStatus struct {
+	ID                  string
+	Name                string
+	Alias               string
+	Paused              bool
+	BufferSize          int
+	BufferLimit         int     // maximum number of metrics of memory buffers, zero otherwise
+	BufferFill          float64 // fill of the buffer in percent, only valid if BufferLimited is set
+	BufferLimited       bool
+	ConsecutiveFailures int64
+}
+
+// StatusProvider reports the status of the running plugins.
+type StatusProvider interface {
+	InputStatus() []InputStatus
+	OutputStatus() []OutputStatus
+}
+
+// StatusConsumer is implemented by plugins requiring the status of the running
+// plugins of the agent. The agent sets the provider before initializing the
+// plugin.
+type StatusConsumer interface {
+	SetStatusProvider(provider StatusProvider)
+}
@@ -1,5 +1,7 @@
 package telegraf
 
+import "io"
+
 // Parser is an interface defining functions that a parser plugin must satisfy.
 type Parser interface {
 	// Parse takes a byte buffer separated by newlines
@@ -22,6 +24,18 @@ type Parser interface {
 	SetDefaultTags(tags map[string]string)
 }
 
+// StreamingParser is an optional interface for parsers able to parse data
+// incrementally from a reader instead of requiring the whole payload in memory.
+type StreamingParser interface {
+	Parser
+
+	// ParseStream parses the data read from the given reader and passes each
+	// metric to the given function as soon as it is available. Parsing stops
+	// at the first error of the parser or the function. Metrics passed to the
+	// function before the error are not revoked.
+	ParseStream(r io.Reader, fn func(Metric) error) error
+}
+
 // ParserFunc is a function to create a new instance of a parser
 type ParserFunc func() (Parser, error)
 
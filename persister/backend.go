@@ -0,0 +1,71 @@
+package persister
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Backend reads and writes the serialized plugin states keyed by the plugin
+// ID. Read must return an error wrapping os.ErrNotExist if no states were
+// written before.
+type Backend interface {
+	Read() (map[string][]byte, error)
+	Write(states map[string][]byte) error
+}
+
+// FileBackend stores the states as JSON document in a single file. The file is
+// replaced atomically on write so a crash never leaves a partially written
+// state file behind.
+type FileBackend struct {
+	Filename string
+}
+
+func (b *FileBackend) Read() (map[string][]byte, error) {
+	in, err := os.ReadFile(b.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var states map[string][]byte
+	if err := json.Unmarshal(in, &states); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	return states, nil
+}
+
+func (b *FileBackend) Write(states map[string][]byte) error {
+	serialized, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("marshalling states failed: %w", err)
+	}
+
+	// Write to a temporary file in the same directory and rename it afterwards
+	// as renaming is atomic on the same filesystem.
+	f, err := os.CreateTemp(filepath.Dir(b.Filename), filepath.Base(b.Filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary states file for %q failed: %w", b.Filename, err)
+	}
+	tmpname := f.Name()
+	defer os.Remove(tmpname)
+
+	if _, err := f.Write(serialized); err != nil {
+		f.Close()
+		return fmt.Errorf("writing states file failed: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing states file failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing states file failed: %w", err)
+	}
+
+	if err := os.Rename(tmpname, b.Filename); err != nil {
+		return fmt.Errorf("replacing states file %q failed: %w", b.Filename, err)
+	}
+
+	return nil
+}
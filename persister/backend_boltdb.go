@@ -0,0 +1,85 @@
+package persister
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("states")
+
+// BoltDBBackend stores the states in an embedded BoltDB key-value store using
+// the plugin ID as key. All states are replaced in a single transaction.
+type BoltDBBackend struct {
+	Filename string
+}
+
+func (b *BoltDBBackend) open(readonly bool) (*bolt.DB, error) {
+	// Do not block forever if another process holds the database lock
+	options := &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: readonly,
+	}
+	db, err := bolt.Open(b.Filename, 0o600, options)
+	if err != nil {
+		return nil, fmt.Errorf("opening database %q failed: %w", b.Filename, err)
+	}
+	return db, nil
+}
+
+func (b *BoltDBBackend) Read() (map[string][]byte, error) {
+	if _, err := os.Stat(b.Filename); err != nil {
+		return nil, err
+	}
+
+	db, err := b.open(true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	states := make(map[string][]byte)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		if bucket == nil {
+			return os.ErrNotExist
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			// The values are only valid during the transaction
+			states[string(k)] = append([]byte(nil), v...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func (b *BoltDBBackend) Write(states map[string][]byte) error {
+	db, err := b.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(boltBucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return fmt.Errorf("clearing states failed: %w", err)
+		}
+		bucket, err := tx.CreateBucket(boltBucket)
+		if err != nil {
+			return fmt.Errorf("creating states bucket failed: %w", err)
+		}
+		for id, state := range states {
+			if err := bucket.Put([]byte(id), state); err != nil {
+				return fmt.Errorf("writing state for %q failed: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
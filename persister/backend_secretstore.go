@@ -0,0 +1,68 @@
+package persister
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+)
+
+// SecretStoreBackend stores the states as JSON document under a single key of
+// a secret-store. This allows to keep the states in a remote or encrypted
+// location provided by the secret-store plugin.
+type SecretStoreBackend struct {
+	StoreID string
+	Key     string
+
+	// Store is the secret-store referenced by StoreID and must be set before
+	// reading or writing.
+	Store telegraf.SecretStore
+}
+
+func (b *SecretStoreBackend) Read() (map[string][]byte, error) {
+	if b.Store == nil {
+		return nil, errors.New("secret-store not linked")
+	}
+
+	// Secret-stores do not report missing keys in a uniform way, so check
+	// the key list instead.
+	keys, err := b.Store.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing keys of secret-store %q failed: %w", b.StoreID, err)
+	}
+	if !slices.Contains(keys, b.Key) {
+		return nil, fmt.Errorf("key %q in secret-store %q: %w", b.Key, b.StoreID, os.ErrNotExist)
+	}
+
+	in, err := b.Store.Get(b.Key)
+	if err != nil {
+		return nil, fmt.Errorf("getting key %q of secret-store %q failed: %w", b.Key, b.StoreID, err)
+	}
+
+	var states map[string][]byte
+	if err := json.Unmarshal(in, &states); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	return states, nil
+}
+
+func (b *SecretStoreBackend) Write(states map[string][]byte) error {
+	if b.Store == nil {
+		return errors.New("secret-store not linked")
+	}
+
+	serialized, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("marshalling states failed: %w", err)
+	}
+
+	if err := b.Store.Set(b.Key, string(serialized)); err != nil {
+		return fmt.Errorf("setting key %q of secret-store %q failed: %w", b.Key, b.StoreID, err)
+	}
+
+	return nil
+}
@@ -3,8 +3,8 @@ package persister
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 )
@@ -12,16 +12,28 @@ import (
 type Persister struct {
 	Filename string
 
+	// Backend to read the states from and write the states to. If unset, the
+	// states are stored in the file given by Filename.
+	Backend Backend
+
 	register map[string]telegraf.StatefulPlugin
+	sync.Mutex
 }
 
 func (p *Persister) Init() error {
 	p.register = make(map[string]telegraf.StatefulPlugin)
 
+	if p.Backend == nil {
+		p.Backend = &FileBackend{Filename: p.Filename}
+	}
+
 	return nil
 }
 
 func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
+	p.Lock()
+	defer p.Unlock()
+
 	if _, found := p.register[id]; found {
 		return fmt.Errorf("plugin with ID %q already registered", id)
 	}
@@ -30,18 +42,22 @@ func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
 	return nil
 }
 
+func (p *Persister) Unregister(id string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.register, id)
+}
+
 func (p *Persister) Load() error {
-	// Read the states from disk
-	in, err := os.ReadFile(p.Filename)
+	// Read the id to serialized states map
+	states, err := p.Backend.Read()
 	if err != nil {
-		return fmt.Errorf("reading states file failed: %w", err)
+		return fmt.Errorf("reading states failed: %w", err)
 	}
 
-	// Unmarshal the id to serialized states map
-	var states map[string][]byte
-	if err := json.Unmarshal(in, &states); err != nil {
-		return fmt.Errorf("unmarshalling states failed: %w", err)
-	}
+	p.Lock()
+	defer p.Unlock()
 
 	// Get the initialized state as blueprint for unmarshalling
 	for id, serialized := range states {
@@ -70,6 +86,9 @@ func (p *Persister) Load() error {
 }
 
 func (p *Persister) Store() error {
+	p.Lock()
+	defer p.Unlock()
+
 	states := make(map[string][]byte)
 
 	// Collect the states and serialize the individual data chunks
@@ -82,20 +101,8 @@ func (p *Persister) Store() error {
 		states[id] = state
 	}
 
-	// Serialize the states
-	serialized, err := json.Marshal(states)
-	if err != nil {
-		return fmt.Errorf("marshalling states failed: %w", err)
-	}
-
-	// Write the states to disk
-	f, err := os.Create(p.Filename)
-	if err != nil {
-		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(serialized); err != nil {
+	// Write the states to the backend
+	if err := p.Backend.Write(states); err != nil {
 		return fmt.Errorf("writing states failed: %w", err)
 	}
 
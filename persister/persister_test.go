@@ -0,0 +1,150 @@
+package persister
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+type mockupState struct {
+	Name   string
+	Offset uint64
+}
+
+type mockupPlugin struct {
+	state mockupState
+}
+
+func (p *mockupPlugin) GetState() interface{} {
+	return p.state
+}
+
+func (p *mockupPlugin) SetState(state interface{}) error {
+	p.state = state.(mockupState)
+	return nil
+}
+
+type mockupSecretStore struct {
+	secrets map[string][]byte
+}
+
+func (*mockupSecretStore) Init() error {
+	return nil
+}
+
+func (*mockupSecretStore) SampleConfig() string {
+	return ""
+}
+
+func (s *mockupSecretStore) Get(key string) ([]byte, error) {
+	return s.secrets[key], nil
+}
+
+func (s *mockupSecretStore) Set(key, value string) error {
+	s.secrets[key] = []byte(value)
+	return nil
+}
+
+func (s *mockupSecretStore) Delete(key string) error {
+	delete(s.secrets, key)
+	return nil
+}
+
+func (s *mockupSecretStore) List() ([]string, error) {
+	keys := make([]string, 0, len(s.secrets))
+	for k := range s.secrets {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (*mockupSecretStore) GetResolver(string) (telegraf.ResolveFunc, error) {
+	return nil, nil
+}
+
+func TestBackendsStoreLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend func(dir string) Backend
+	}{
+		{
+			name: "file",
+			backend: func(dir string) Backend {
+				return &FileBackend{Filename: filepath.Join(dir, "states.json")}
+			},
+		},
+		{
+			name: "boltdb",
+			backend: func(dir string) Backend {
+				return &BoltDBBackend{Filename: filepath.Join(dir, "states.db")}
+			},
+		},
+		{
+			name: "secretstore",
+			backend: func(string) Backend {
+				return &SecretStoreBackend{
+					StoreID: "mystore",
+					Key:     "states",
+					Store:   &mockupSecretStore{secrets: make(map[string][]byte)},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := tt.backend(t.TempDir())
+
+			// Loading without stored states should report a missing state
+			empty := &Persister{Backend: backend}
+			require.NoError(t, empty.Init())
+			require.ErrorIs(t, empty.Load(), os.ErrNotExist)
+
+			// Store the states of the plugins
+			store := &Persister{Backend: backend}
+			require.NoError(t, store.Init())
+			for _, id := range []string{"a", "b"} {
+				plugin := &mockupPlugin{state: mockupState{Name: id, Offset: 42}}
+				require.NoError(t, store.Register(id, plugin))
+			}
+			require.NoError(t, store.Store())
+
+			// Overwrite the states with a single plugin
+			store.Unregister("b")
+			require.NoError(t, store.Store())
+
+			// Restore the states
+			load := &Persister{Backend: backend}
+			require.NoError(t, load.Init())
+			a := &mockupPlugin{}
+			b := &mockupPlugin{}
+			require.NoError(t, load.Register("a", a))
+			require.NoError(t, load.Register("b", b))
+			require.NoError(t, load.Load())
+			require.Equal(t, mockupState{Name: "a", Offset: 42}, a.state)
+			require.Empty(t, b.state)
+		})
+	}
+}
+
+func TestFileBackendAtomicWrite(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "states.json")
+	require.NoError(t, os.WriteFile(filename, []byte("previous"), 0o600))
+
+	backend := &FileBackend{Filename: filename}
+	require.NoError(t, backend.Write(map[string][]byte{"a": []byte(`"state"`)}))
+
+	// The file must be replaced without leaving temporary files behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	states, err := backend.Read()
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a": []byte(`"state"`)}, states)
+}
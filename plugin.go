@@ -49,7 +49,11 @@ type StatefulPlugin interface {
 	// serialized to JSON. The best choice is a structure defined in
 	// your plugin.
 	// Note: This function has to be callable directly after the
-	// plugin's Init() function if there is any!
+	// plugin's Init() function if there is any! When periodic state
+	// checkpoints are enabled, the function is called while the plugin
+	// is running. Calls for processors and aggregators are serialized
+	// with Add/Apply/Push by the agent, inputs and outputs must make
+	// sure accessing the state is safe for concurrent use.
 	GetState() interface{}
 
 	// SetState is called by the Persister once after loading and
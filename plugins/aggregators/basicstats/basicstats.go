@@ -3,10 +3,12 @@ package basicstats
 
 import (
 	_ "embed"
+	"fmt"
 	"math"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -61,6 +63,30 @@ type basicstats struct {
 	TIME     time.Time // intermediate value for rate
 }
 
+// aggregateState is the serializable form of an aggregate
+type aggregateState struct {
+	Name   string                     `json:"name"`
+	Tags   map[string]string          `json:"tags"`
+	Fields map[string]basicstatsState `json:"fields"`
+}
+
+// basicstatsState is the serializable form of the basicstats of a field
+type basicstatsState struct {
+	Count    float64       `json:"count"`
+	Min      float64       `json:"min"`
+	Max      float64       `json:"max"`
+	Sum      float64       `json:"sum"`
+	Mean     float64       `json:"mean"`
+	Diff     float64       `json:"diff"`
+	Rate     float64       `json:"rate"`
+	Interval time.Duration `json:"interval"`
+	Last     float64       `json:"last"`
+	First    float64       `json:"first"`
+	M2       float64       `json:"m2"`
+	Previous float64       `json:"previous"`
+	Time     time.Time     `json:"time"`
+}
+
 func (*BasicStats) SampleConfig() string {
 	return sampleConfig
 }
@@ -231,6 +257,69 @@ func (b *BasicStats) Reset() {
 	b.cache = make(map[uint64]aggregate)
 }
 
+func (b *BasicStats) GetState() interface{} {
+	state := make([]aggregateState, 0, len(b.cache))
+	for _, a := range b.cache {
+		fields := make(map[string]basicstatsState, len(a.fields))
+		for k, v := range a.fields {
+			fields[k] = basicstatsState{
+				Count:    v.count,
+				Min:      v.min,
+				Max:      v.max,
+				Sum:      v.sum,
+				Mean:     v.mean,
+				Diff:     v.diff,
+				Rate:     v.rate,
+				Interval: v.interval,
+				Last:     v.last,
+				First:    v.first,
+				M2:       v.M2,
+				Previous: v.PREVIOUS,
+				Time:     v.TIME,
+			}
+		}
+		state = append(state, aggregateState{Name: a.name, Tags: a.tags, Fields: fields})
+	}
+	return state
+}
+
+func (b *BasicStats) SetState(state interface{}) error {
+	aggregates, ok := state.([]aggregateState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	for _, s := range aggregates {
+		a := aggregate{
+			name:   s.Name,
+			tags:   s.Tags,
+			fields: make(map[string]basicstats, len(s.Fields)),
+		}
+		if a.tags == nil {
+			a.tags = make(map[string]string)
+		}
+		for k, v := range s.Fields {
+			a.fields[k] = basicstats{
+				count:    v.Count,
+				min:      v.Min,
+				max:      v.Max,
+				sum:      v.Sum,
+				mean:     v.Mean,
+				diff:     v.Diff,
+				rate:     v.Rate,
+				interval: v.Interval,
+				last:     v.Last,
+				first:    v.First,
+				M2:       v.M2,
+				PREVIOUS: v.Previous,
+				TIME:     v.Time,
+			}
+		}
+		id := metric.New(a.name, a.tags, nil, time.Time{}).HashID()
+		b.cache[id] = a
+	}
+	return nil
+}
+
 // member function for logging.
 func (b *BasicStats) parseStats() *configuredStats {
 	parsed := &configuredStats{}
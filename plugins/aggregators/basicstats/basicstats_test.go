@@ -1,12 +1,14 @@
 package basicstats
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -824,3 +826,45 @@ func TestBasicStatsWithOnlyFirst(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
 }
+
+func TestBasicStatsStatePersistence(t *testing.T) {
+	stats := []string{"count", "min", "max", "mean", "s2", "sum", "diff", "rate", "interval", "last", "first"}
+
+	// Reference aggregating all metrics without interruption
+	reference := newBasicStats()
+	reference.Stats = stats
+	reference.Log = testutil.Logger{}
+	require.NoError(t, reference.Init())
+	reference.Add(m1)
+	reference.Add(m2)
+
+	var expected testutil.Accumulator
+	reference.Push(&expected)
+
+	// Aggregate the first metric and persist the state
+	previous := newBasicStats()
+	previous.Stats = stats
+	previous.Log = testutil.Logger{}
+	require.NoError(t, previous.Init())
+	previous.Add(m1)
+
+	var pi telegraf.StatefulPlugin = previous
+	serialized, err := json.Marshal(pi.GetState())
+	require.NoError(t, err)
+
+	// Restore the state and aggregate the second metric
+	var state []aggregateState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	plugin := newBasicStats()
+	plugin.Stats = stats
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	pi = plugin
+	require.NoError(t, pi.SetState(state))
+	plugin.Add(m2)
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected.GetTelegrafMetrics(), acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -3,10 +3,13 @@ package derivative
 
 import (
 	_ "embed"
+	"fmt"
+	"maps"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -36,6 +39,21 @@ type event struct {
 	time   time.Time
 }
 
+// aggregateState is the serializable form of an aggregate
+type aggregateState struct {
+	Name     string            `json:"name"`
+	Tags     map[string]string `json:"tags"`
+	First    eventState        `json:"first"`
+	Last     eventState        `json:"last"`
+	RollOver uint              `json:"roll_over"`
+}
+
+// eventState is the serializable form of an event
+type eventState struct {
+	Fields map[string]float64 `json:"fields"`
+	Time   time.Time          `json:"time"`
+}
+
 func (d *Derivative) Init() error {
 	d.Suffix = strings.TrimSpace(d.Suffix)
 	d.Variable = strings.TrimSpace(d.Variable)
@@ -125,6 +143,54 @@ func (d *Derivative) Reset() {
 	}
 }
 
+func (d *Derivative) GetState() interface{} {
+	state := make([]aggregateState, 0, len(d.cache))
+	for _, a := range d.cache {
+		// Copy the fields as those are modified when adding metrics
+		state = append(state, aggregateState{
+			Name:     a.name,
+			Tags:     a.tags,
+			First:    eventState{Fields: maps.Clone(a.first.fields), Time: a.first.time},
+			Last:     eventState{Fields: maps.Clone(a.last.fields), Time: a.last.time},
+			RollOver: a.rollOver,
+		})
+	}
+	return state
+}
+
+func (d *Derivative) SetState(state interface{}) error {
+	aggregates, ok := state.([]aggregateState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	for _, s := range aggregates {
+		a := &aggregate{
+			name:     s.Name,
+			tags:     s.Tags,
+			first:    &event{fields: s.First.Fields, time: s.First.Time},
+			rollOver: s.RollOver,
+		}
+		if a.tags == nil {
+			a.tags = make(map[string]string)
+		}
+		if a.first.fields == nil {
+			a.first.fields = make(map[string]float64)
+		}
+		// Keep first and last identical if they were the same event
+		if s.Last.Time.Equal(s.First.Time) {
+			a.last = a.first
+		} else {
+			a.last = &event{fields: s.Last.Fields, time: s.Last.Time}
+			if a.last.fields == nil {
+				a.last.fields = make(map[string]float64)
+			}
+		}
+		id := metric.New(a.name, a.tags, nil, time.Time{}).HashID()
+		d.cache[id] = a
+	}
+	return nil
+}
+
 func newAggregate(in telegraf.Metric) *aggregate {
 	event := newEvent(in)
 	return &aggregate{
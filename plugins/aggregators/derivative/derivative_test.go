@@ -1,11 +1,13 @@
 package derivative
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -416,3 +418,41 @@ func TestCalculatesCorrectDerivativeOnTwoConsecutivePeriods(t *testing.T) {
 		"value_rate": 2.0,
 	})
 }
+
+func TestStatePersistence(t *testing.T) {
+	// Add the first event and persist the state
+	previous := newDerivative()
+	previous.Variable = "parameter"
+	previous.Log = testutil.Logger{}
+	require.NoError(t, previous.Init())
+	previous.Add(start)
+
+	var pi telegraf.StatefulPlugin = previous
+	serialized, err := json.Marshal(pi.GetState())
+	require.NoError(t, err)
+
+	// Restore the state and add the second event
+	var state []aggregateState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	derivative := newDerivative()
+	derivative.Variable = "parameter"
+	derivative.Log = testutil.Logger{}
+	require.NoError(t, derivative.Init())
+	pi = derivative
+	require.NoError(t, pi.SetState(state))
+	derivative.Add(finish)
+
+	acc := testutil.Accumulator{}
+	derivative.Push(&acc)
+
+	expectedFields := map[string]interface{}{
+		"increasing_rate": 100.0,
+		"decreasing_rate": -10.0,
+		"unchanged_rate":  0.0,
+	}
+	expectedTags := map[string]string{
+		"state": "full",
+	}
+	acc.AssertContainsTaggedFields(t, "TestMetric", expectedFields, expectedTags)
+}
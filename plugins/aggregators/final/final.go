@@ -9,6 +9,8 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
 //go:embed sample.conf
@@ -19,6 +21,8 @@ type Final struct {
 	SeriesTimeout          config.Duration `toml:"series_timeout"`
 	KeepOriginalFieldNames bool            `toml:"keep_original_field_names"`
 
+	Log telegraf.Logger `toml:"-"`
+
 	// The last metric for all series which are active
 	metricCache map[uint64]telegraf.Metric
 }
@@ -77,6 +81,42 @@ func (m *Final) Push(acc telegraf.Accumulator) {
 func (*Final) Reset() {
 }
 
+func (m *Final) GetState() interface{} {
+	s := &serializers_influx.Serializer{UintSupport: true}
+	if err := s.Init(); err != nil {
+		m.Log.Errorf("Initializing serializer failed: %v", err)
+		return []byte(nil)
+	}
+	v := make([]telegraf.Metric, 0, len(m.metricCache))
+	for _, metric := range m.metricCache {
+		v = append(v, metric)
+	}
+	state, err := s.SerializeBatch(v)
+	if err != nil {
+		m.Log.Errorf("Serializing metric cache failed: %v", err)
+	}
+	return state
+}
+
+func (m *Final) SetState(state interface{}) error {
+	data, ok := state.([]byte)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	p := &influx.Parser{}
+	if err := p.Init(); err != nil {
+		return err
+	}
+	metrics, err := p.Parse(data)
+	if err != nil {
+		return fmt.Errorf("parsing state failed: %w", err)
+	}
+	for _, metric := range metrics {
+		m.Add(metric)
+	}
+	return nil
+}
+
 func newFinal() *Final {
 	return &Final{
 		SeriesTimeout: config.Duration(5 * time.Minute),
@@ -306,3 +306,49 @@ func TestKeepOriginalFieldNames(t *testing.T) {
 
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
 }
+
+func TestStatePersistence(t *testing.T) {
+	tags := map[string]string{"foo": "bar"}
+	m1 := metric.New("m1",
+		tags,
+		map[string]interface{}{"a": int64(1), "b": uint64(2), "c": "text"},
+		time.Unix(1530939936, 0))
+	m2 := metric.New("m2",
+		tags,
+		map[string]interface{}{"a": 2.5},
+		time.Unix(1530939937, 0))
+
+	// Collect some metrics and get the state
+	previous := newFinal()
+	previous.OutputStrategy = "periodic"
+	previous.Log = testutil.Logger{}
+	require.NoError(t, previous.Init())
+	previous.Add(m1)
+	previous.Add(m2)
+
+	var pi telegraf.StatefulPlugin = previous
+	state := pi.GetState()
+
+	// Restore the state and check the cached metrics are output
+	final := newFinal()
+	final.OutputStrategy = "periodic"
+	final.Log = testutil.Logger{}
+	require.NoError(t, final.Init())
+	pi = final
+	require.NoError(t, pi.SetState(state))
+
+	var acc testutil.Accumulator
+	final.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("m1",
+			tags,
+			map[string]interface{}{"a_final": int64(1), "b_final": uint64(2), "c_final": "text"},
+			time.Unix(1530939936, 0)),
+		metric.New("m2",
+			tags,
+			map[string]interface{}{"a_final": 2.5},
+			time.Unix(1530939937, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
@@ -0,0 +1,19 @@
+package opentelemetry
+
+import (
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Logger adapts the Telegraf logger to the logger interface of the
+// influxdb-observability converters
+type Logger struct {
+	telegraf.Logger
+}
+
+// Debug logs a debug message followed by the given key-value pairs
+func (l Logger) Debug(msg string, kv ...interface{}) {
+	format := msg + strings.Repeat(" %s=%q", len(kv)/2)
+	l.Logger.Debugf(format, kv...)
+}
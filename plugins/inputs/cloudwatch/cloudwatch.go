@@ -58,6 +58,7 @@ type CloudWatch struct {
 	queryDimensions map[string]*map[string]string
 	windowStart     time.Time
 	windowEnd       time.Time
+	windowLock      sync.Mutex
 }
 
 type cloudwatchMetric struct {
@@ -334,6 +335,9 @@ func (c *CloudWatch) getFilteredMetrics() ([]filteredMetric, error) {
 }
 
 func (c *CloudWatch) updateWindow(relativeTo time.Time) {
+	c.windowLock.Lock()
+	defer c.windowLock.Unlock()
+
 	windowEnd := relativeTo.Add(-time.Duration(c.Delay))
 
 	if c.windowEnd.IsZero() {
@@ -347,6 +351,28 @@ func (c *CloudWatch) updateWindow(relativeTo time.Time) {
 	c.windowEnd = windowEnd
 }
 
+// GetState returns the end of the last queried window so the next window
+// continues where the previous run left off.
+func (c *CloudWatch) GetState() interface{} {
+	c.windowLock.Lock()
+	defer c.windowLock.Unlock()
+
+	return c.windowEnd
+}
+
+func (c *CloudWatch) SetState(state interface{}) error {
+	windowEnd, ok := state.(time.Time)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+
+	c.windowLock.Lock()
+	defer c.windowLock.Unlock()
+
+	c.windowEnd = windowEnd
+	return nil
+}
+
 // getDataQueries gets all of the possible queries so we can maximize the request payload.
 func (c *CloudWatch) getDataQueries(filteredMetrics []filteredMetric) map[string][]types.MetricDataQuery {
 	if c.cache != nil && c.cache.queries != nil && c.cache.metrics != nil && time.Since(c.cache.built) < c.cache.ttl {
@@ -2,6 +2,7 @@ package cloudwatch
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"testing"
@@ -697,3 +698,36 @@ func (*mockClient) GetMetricData(
 		},
 	}, nil
 }
+
+func TestStatePersistence(t *testing.T) {
+	previous := &CloudWatch{
+		Namespaces: []string{"AWS/ELB"},
+		Delay:      config.Duration(1 * time.Minute),
+		Period:     config.Duration(1 * time.Minute),
+		Log:        testutil.Logger{},
+	}
+	previous.updateWindow(time.Now().Add(-10 * time.Minute))
+
+	var pi telegraf.StatefulPlugin = previous
+	serialized, err := json.Marshal(pi.GetState())
+	require.NoError(t, err)
+
+	// Restore the state and check the window continues where the previous
+	// run stopped instead of only querying a single period
+	var state time.Time
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	plugin := &CloudWatch{
+		Namespaces: []string{"AWS/ELB"},
+		Delay:      config.Duration(1 * time.Minute),
+		Period:     config.Duration(1 * time.Minute),
+		Log:        testutil.Logger{},
+	}
+	pi = plugin
+	require.NoError(t, pi.SetState(state))
+
+	now := time.Now()
+	plugin.updateWindow(now)
+	require.True(t, plugin.windowStart.Equal(previous.windowEnd))
+	require.EqualValues(t, now.Add(-time.Duration(plugin.Delay)), plugin.windowEnd)
+}
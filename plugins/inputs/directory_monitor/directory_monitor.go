@@ -70,6 +70,10 @@ type DirectoryMonitor struct {
 	fileRegexesToMatch  []*regexp.Regexp
 	fileRegexesToIgnore []*regexp.Regexp
 	filesToProcess      chan string
+
+	// Number of lines already sent per file for files not completely read
+	progress     map[string]int64
+	progressLock sync.Mutex
 }
 
 func (*DirectoryMonitor) SampleConfig() string {
@@ -142,6 +146,34 @@ func (monitor *DirectoryMonitor) Init() error {
 		return fmt.Errorf("config option parse_method: %w", err)
 	}
 
+	monitor.progress = make(map[string]int64)
+
+	return nil
+}
+
+func (monitor *DirectoryMonitor) GetState() interface{} {
+	monitor.progressLock.Lock()
+	defer monitor.progressLock.Unlock()
+
+	state := make(map[string]int64, len(monitor.progress))
+	for k, v := range monitor.progress {
+		state[k] = v
+	}
+	return state
+}
+
+func (monitor *DirectoryMonitor) SetState(state interface{}) error {
+	progress, ok := state.(map[string]int64)
+	if !ok {
+		return errors.New("state has to be of type 'map[string]int64'")
+	}
+
+	monitor.progressLock.Lock()
+	defer monitor.progressLock.Unlock()
+
+	for k, v := range progress {
+		monitor.progress[k] = v
+	}
 	return nil
 }
 
@@ -280,6 +312,14 @@ func (monitor *DirectoryMonitor) read(filePath string) {
 		return
 	}
 
+	// Keep partially read files in place when stopping, the remaining lines
+	// will be read on the next run.
+	if errors.Is(err, context.Canceled) {
+		monitor.Log.Debugf("Stopped reading file %q", filePath)
+		return
+	}
+	monitor.setProgress(filePath, -1)
+
 	// Handle a file read error. We don't halt execution but do document, log, and move the problematic file.
 	if err != nil {
 		monitor.Log.Errorf("Error while reading file: %q: %v", filePath, err)
@@ -339,21 +379,66 @@ func (monitor *DirectoryMonitor) parseFile(parser telegraf.Parser, reader io.Rea
 	scanner := bufio.NewScanner(reader)
 	scanner.Split(splitter)
 
+	// Skip the lines already sent in a previous run. The lines are still
+	// parsed to keep the parser state, e.g. for header lines.
+	skip := monitor.getProgress(fileName)
+	var line int64
 	for scanner.Scan() {
+		line++
 		metrics, err := monitor.parseMetrics(parser, scanner.Bytes(), fileName)
 		if err != nil {
 			return err
 		}
+		if line <= skip {
+			continue
+		}
 
 		if err := monitor.sendMetrics(metrics); err != nil {
 			return err
 		}
+		monitor.setProgress(fileName, line)
 	}
 
 	return scanner.Err()
 }
 
 func (monitor *DirectoryMonitor) parseAtOnce(parser telegraf.Parser, reader io.Reader, fileName string) error {
+	// Skip the metrics already sent in a previous run. In contrast to parsing
+	// line-by-line, the progress counts the metrics sent for the file.
+	skip := monitor.getProgress(fileName)
+	var count int64
+	send := func(m telegraf.Metric) error {
+		count++
+		if count <= skip {
+			return nil
+		}
+		if err := monitor.sendMetrics([]telegraf.Metric{m}); err != nil {
+			return err
+		}
+		monitor.setProgress(fileName, count)
+		return nil
+	}
+
+	// Parse while reading if supported to avoid holding the whole file in
+	// memory
+	if sp, ok := parser.(telegraf.StreamingParser); ok {
+		err := sp.ParseStream(reader, func(m telegraf.Metric) error {
+			if monitor.FileTag != "" {
+				m.AddTag(monitor.FileTag, filepath.Base(fileName))
+			}
+			return send(m)
+		})
+		if err != nil && !errors.Is(err, parsers.ErrEOF) {
+			return err
+		}
+		if count == 0 {
+			once.Do(func() {
+				monitor.Log.Debug(internal.NoMetricsCreatedMsg)
+			})
+		}
+		return nil
+	}
+
 	bytes, err := io.ReadAll(reader)
 	if err != nil {
 		return err
@@ -364,7 +449,12 @@ func (monitor *DirectoryMonitor) parseAtOnce(parser telegraf.Parser, reader io.R
 		return err
 	}
 
-	return monitor.sendMetrics(metrics)
+	for _, m := range metrics {
+		if err := send(m); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (monitor *DirectoryMonitor) parseMetrics(parser telegraf.Parser, line []byte, fileName string) (metrics []telegraf.Metric, err error) {
@@ -403,6 +493,27 @@ func (monitor *DirectoryMonitor) sendMetrics(metrics []telegraf.Metric) error {
 	return nil
 }
 
+func (monitor *DirectoryMonitor) getProgress(filePath string) int64 {
+	monitor.progressLock.Lock()
+	defer monitor.progressLock.Unlock()
+
+	return monitor.progress[filePath]
+}
+
+// setProgress records the number of lines or, when parsing at once, metrics
+// sent for the given file. A negative number removes the file from the
+// progress list.
+func (monitor *DirectoryMonitor) setProgress(filePath string, count int64) {
+	monitor.progressLock.Lock()
+	defer monitor.progressLock.Unlock()
+
+	if count < 0 {
+		delete(monitor.progress, filePath)
+		return
+	}
+	monitor.progress[filePath] = count
+}
+
 func (monitor *DirectoryMonitor) moveFile(srcPath, dstBaseDir string) {
 	// Appends any subdirectories in the srcPath to the dstBaseDir and
 	// creates those subdirectories.
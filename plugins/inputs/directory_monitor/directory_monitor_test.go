@@ -7,12 +7,15 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -680,3 +683,201 @@ func TestParseSubdirectoriesFilesIgnore(t *testing.T) {
 	_, err = os.Stat(filepath.Join(finishedDirectory, testJSONFile))
 	require.NoError(t, err)
 }
+
+func TestStatePersistence(t *testing.T) {
+	acc := testutil.Accumulator{}
+
+	// Establish process directory and finished directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        defaultParseMethod,
+	}
+	require.NoError(t, r.Init())
+	r.Log = testutil.Logger{}
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := csv.Parser{
+			HeaderRowCount: 1,
+		}
+		err := parser.Init()
+		return &parser, err
+	})
+
+	// Write csv file to process into the 'process' directory.
+	filename := filepath.Join(processDirectory, "test.csv")
+	require.NoError(t, os.WriteFile(filename, []byte("thing,color\nsky,blue\ngrass,green\nclifford,red\n"), 0640))
+
+	// Restore the state of a previous run that already sent the first two
+	// lines including the header.
+	var pi telegraf.StatefulPlugin = &r
+	require.NoError(t, pi.SetState(map[string]int64{filename: 2}))
+	require.Equal(t, map[string]int64{filename: 2}, pi.GetState())
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(2)
+	r.Stop()
+
+	require.NoError(t, acc.FirstError())
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, "green", acc.Metrics[0].Fields["color"])
+	require.Equal(t, "red", acc.Metrics[1].Fields["color"])
+
+	// The file is completely processed so the state must be empty
+	require.Empty(t, pi.GetState())
+}
+
+func TestParseCompleteFileStreaming(t *testing.T) {
+	acc := testutil.Accumulator{}
+
+	// Establish process directory and finished directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        "at-once",
+		FileTag:            "filename",
+	}
+	require.NoError(t, r.Init())
+	r.Log = testutil.Logger{}
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+
+	// Write influx file to process into the 'process' directory.
+	filename := filepath.Join(processDirectory, "test.influx")
+	data := "cpu value=1 1700000000000000000\ncpu value=2 1700000001000000000\ncpu value=3 1700000002000000000\n"
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0640))
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(3)
+	r.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"filename": "test.influx"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"filename": "test.influx"}, map[string]interface{}{"value": 2.0}, time.Unix(1700000001, 0)),
+		metric.New("cpu", map[string]string{"filename": "test.influx"}, map[string]interface{}{"value": 3.0}, time.Unix(1700000002, 0)),
+	}
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.FileExists(t, filepath.Join(finishedDirectory, "test.influx"))
+}
+
+func TestParseCompleteFileStreamingError(t *testing.T) {
+	acc := testutil.Accumulator{}
+
+	// Establish process, finished and error directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+	errorDirectory := t.TempDir()
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		ErrorDirectory:     errorDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        "at-once",
+	}
+	require.NoError(t, r.Init())
+	r.Log = testutil.Logger{}
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+
+	// Write a file with an invalid line after valid ones
+	filename := filepath.Join(processDirectory, "test.influx")
+	data := "cpu value=1 1700000000000000000\ncpu value=2 1700000001000000000\ncpu value=\n"
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0640))
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(errorDirectory, "test.influx"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	r.Stop()
+
+	// The metrics parsed before the error are sent as when parsing
+	// line-by-line
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1700000001, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The file is moved to the error directory so the state must be empty
+	require.Empty(t, r.GetState())
+}
+
+func TestParseCompleteFileStreamingStatePersistence(t *testing.T) {
+	acc := testutil.Accumulator{}
+
+	// Establish process directory and finished directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        "at-once",
+	}
+	require.NoError(t, r.Init())
+	r.Log = testutil.Logger{}
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+
+	// Write influx file to process into the 'process' directory.
+	filename := filepath.Join(processDirectory, "test.influx")
+	data := "cpu value=1 1700000000000000000\ncpu value=2 1700000001000000000\ncpu value=3 1700000002000000000\n"
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0640))
+
+	// Restore the state of a previous run that already sent the first two
+	// metrics of the file
+	require.NoError(t, r.SetState(map[string]int64{filename: 2}))
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(1)
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(finishedDirectory, "test.influx"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	r.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(1700000002, 0)),
+	}
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The file is completely processed so the state must be empty
+	require.Empty(t, r.GetState())
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -464,3 +465,55 @@ func TestCSVBehavior(t *testing.T) {
 	actual := acc.GetTelegrafMetrics()
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 }
+
+func TestStreamingParser(t *testing.T) {
+	parserFunc := func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	}
+	parser, err := parserFunc()
+	require.NoError(t, err)
+	require.Implements(t, (*telegraf.StreamingParser)(nil), parser)
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.influx")
+	require.NoError(t, os.WriteFile(valid, []byte("cpu value=1 1700000000000000000\ncpu value=2 1700000001000000000\n"), 0600))
+	invalid := filepath.Join(dir, "invalid.influx")
+	require.NoError(t, os.WriteFile(invalid, []byte("cpu value=1 1700000000000000000\ncpu value=\n"), 0600))
+
+	// All metrics of a valid file are added
+	plugin := &File{
+		Files:   []string{valid},
+		FileTag: "filename",
+		Log:     testutil.Logger{},
+	}
+	plugin.SetParserFunc(parserFunc)
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"filename": "valid.influx"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"filename": "valid.influx"}, map[string]interface{}{"value": 2.0}, time.Unix(1700000001, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Metrics parsed before an error in the file are still added
+	plugin = &File{
+		Files:   []string{invalid},
+		FileTag: "filename",
+		Log:     testutil.Logger{},
+	}
+	plugin.SetParserFunc(parserFunc)
+	require.NoError(t, plugin.Init())
+
+	expected = []telegraf.Metric{
+		metric.New("cpu", map[string]string{"filename": "invalid.influx"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+	}
+
+	acc.ClearMetrics()
+	require.ErrorContains(t, plugin.Gather(&acc), "could not parse")
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
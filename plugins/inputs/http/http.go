@@ -183,34 +183,52 @@ func (h *HTTP) gatherURL(acc telegraf.Accumulator, url string) error {
 			h.SuccessStatusCodes)
 	}
 
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading body failed: %w", err)
-	}
-
 	// Instantiate a new parser for the new data to avoid trouble with stateful parsers
 	parser, err := h.parserFunc()
 	if err != nil {
 		return fmt.Errorf("instantiating parser failed: %w", err)
 	}
-	metrics, err := parser.Parse(b)
-	if err != nil {
-		return fmt.Errorf("parsing metrics failed: %w", err)
-	}
 
-	if len(metrics) == 0 {
-		once.Do(func() {
-			h.Log.Debug(internal.NoMetricsCreatedMsg)
-		})
-	}
-
-	for _, metric := range metrics {
+	addMetric := func(metric telegraf.Metric) {
 		if !metric.HasTag("url") {
 			metric.AddTag("url", url)
 		}
 		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
 	}
 
+	// Pass the metrics on while parsing if supported to avoid holding the
+	// whole body in memory
+	var count int
+	if sp, ok := parser.(telegraf.StreamingParser); ok {
+		err := sp.ParseStream(resp.Body, func(metric telegraf.Metric) error {
+			addMetric(metric)
+			count++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("parsing metrics failed: %w", err)
+		}
+	} else {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading body failed: %w", err)
+		}
+		metrics, err := parser.Parse(b)
+		if err != nil {
+			return fmt.Errorf("parsing metrics failed: %w", err)
+		}
+		for _, metric := range metrics {
+			addMetric(metric)
+		}
+		count = len(metrics)
+	}
+
+	if count == 0 {
+		once.Do(func() {
+			h.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+	}
+
 	return nil
 }
 
@@ -470,6 +470,71 @@ func TestHTTPWithCSVFormat(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestHTTPWithStreamingParser(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch r.URL.Path {
+		case "/valid":
+			body = "cpu value=1 1700000000000000000\ncpu value=2 1700000001000000000\n"
+		case "/invalid":
+			body = "cpu value=1 1700000000000000000\ncpu value=\n"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	defer fakeServer.Close()
+
+	parserFunc := func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	}
+	parser, err := parserFunc()
+	require.NoError(t, err)
+	require.Implements(t, (*telegraf.StreamingParser)(nil), parser)
+
+	// All metrics of a valid body are added
+	address := fakeServer.URL + "/valid"
+	plugin := &httpplugin.HTTP{
+		URLs: []string{address},
+		Log:  testutil.Logger{},
+	}
+	plugin.SetParserFunc(parserFunc)
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"url": address}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		testutil.MustMetric("cpu", map[string]string{"url": address}, map[string]interface{}{"value": 2.0}, time.Unix(1700000001, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Metrics parsed before an error in the body are still added
+	address = fakeServer.URL + "/invalid"
+	plugin = &httpplugin.HTTP{
+		URLs: []string{address},
+		Log:  testutil.Logger{},
+	}
+	plugin.SetParserFunc(parserFunc)
+	require.NoError(t, plugin.Init())
+
+	expected = []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"url": address}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+	}
+
+	acc.ClearMetrics()
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "parsing metrics failed")
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 const (
 	httpOverUnixScheme = "http+unix"
 )
@@ -59,6 +59,7 @@ type (
 		client              metricClient
 		timeSeriesConfCache *timeSeriesConfCache
 		prevEnd             time.Time
+		prevEndLock         sync.Mutex
 	}
 
 	// listTimeSeriesFilter contains resource labels and metric labels
@@ -132,8 +133,10 @@ func (s *Stackdriver) Gather(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	s.prevEndLock.Lock()
 	start, end := s.updateWindow(s.prevEnd)
 	s.prevEnd = end
+	s.prevEndLock.Unlock()
 
 	tsConfs, err := s.generateTimeSeriesConfs(ctx, start, end)
 	if err != nil {
@@ -191,6 +194,28 @@ func (s *Stackdriver) initializeStackdriverClient(ctx context.Context) error {
 	return nil
 }
 
+// GetState returns the end of the last collection window so the next window
+// continues where the previous run left off.
+func (s *Stackdriver) GetState() interface{} {
+	s.prevEndLock.Lock()
+	defer s.prevEndLock.Unlock()
+
+	return s.prevEnd
+}
+
+func (s *Stackdriver) SetState(state interface{}) error {
+	prevEnd, ok := state.(time.Time)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+
+	s.prevEndLock.Lock()
+	defer s.prevEndLock.Unlock()
+
+	s.prevEnd = prevEnd
+	return nil
+}
+
 // Returns the start and end time for the next collection.
 func (s *Stackdriver) updateWindow(prevEnd time.Time) (start, end time.Time) {
 	if time.Duration(s.Window) != 0 {
@@ -1231,3 +1231,16 @@ func TestNewListTimeSeriesFilter(_ *testing.T) {
 
 func TestTimeSeriesConfCacheIsValid(_ *testing.T) {
 }
+
+func TestStatePersistence(t *testing.T) {
+	prevEnd := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+
+	plugin := &Stackdriver{}
+	var pi telegraf.StatefulPlugin = plugin
+	require.NoError(t, pi.SetState(prevEnd))
+	require.Equal(t, prevEnd, pi.GetState())
+
+	// The next window must continue where the previous run left off
+	start, _ := plugin.updateWindow(plugin.prevEnd)
+	require.Equal(t, prevEnd, start)
+}
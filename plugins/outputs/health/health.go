@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"net"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -45,8 +47,13 @@ type Health struct {
 	Contains              []*Contains     `toml:"contains"`
 	MaxTimeBetweenMetrics config.Duration `toml:"max_time_between_metrics"`
 
+	MaxBufferFill               float64 `toml:"max_buffer_fill"`
+	MaxConsecutiveWriteFailures int64   `toml:"max_consecutive_write_failures"`
+	MaxMissedGathers            int64   `toml:"max_missed_gathers"`
+
 	Log      telegraf.Logger `toml:"-"`
 	checkers []Checker
+	status   models.StatusProvider
 
 	wg             sync.WaitGroup
 	server         *http.Server
@@ -55,6 +62,7 @@ type Health struct {
 	address        string
 	tlsConf        *tls.Config
 	lastMetricTime time.Time
+	startTime      time.Time
 
 	mu      sync.Mutex
 	healthy bool
@@ -89,6 +97,10 @@ func (h *Health) Init() error {
 		return err
 	}
 
+	if h.hasStatusChecks() && h.status == nil {
+		return errors.New("plugin checks require the status of the agent which is not available")
+	}
+
 	h.checkers = make([]Checker, 0)
 	for i := range h.Compares {
 		h.checkers = append(h.checkers, h.Compares[i])
@@ -123,6 +135,7 @@ func (h *Health) Connect() error {
 	// Initialize lastMetricTime here to fail if no metrics are received
 	// before the configured max timeout.
 	h.lastMetricTime = time.Now()
+	h.startTime = h.lastMetricTime
 	h.wg.Add(1)
 	go func() {
 		defer h.wg.Done()
@@ -154,12 +167,29 @@ func (h *Health) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
 		healthy = healthy && time.Since(h.lastMetricTime) < time.Duration(h.MaxTimeBetweenMetrics)
 	}
 
+	// Report the failing plugins if checking the agent status
+	var report *StatusReport
+	if h.hasStatusChecks() {
+		failures := h.checkStatus(time.Now())
+		healthy = healthy && len(failures) == 0
+		report = &StatusReport{Healthy: healthy, Failures: failures}
+	}
+
 	if !healthy {
 		code = http.StatusServiceUnavailable
 	}
 
 	rw.Header().Set("Server", internal.ProductToken())
-	http.Error(rw, http.StatusText(code), code)
+	if report == nil {
+		http.Error(rw, http.StatusText(code), code)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	if err := json.NewEncoder(rw).Encode(report); err != nil {
+		h.Log.Errorf("Encoding status report failed: %v", err)
+	}
 }
 
 // Write runs all checks over the metric batch and adjust health state.
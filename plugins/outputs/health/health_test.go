@@ -1,6 +1,8 @@
 package health_test
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/outputs/health"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -330,3 +333,139 @@ func TestTimeBetweenMetrics(t *testing.T) {
 		})
 	}
 }
+
+type mockStatusProvider struct {
+	inputs  []models.InputStatus
+	outputs []models.OutputStatus
+}
+
+func (p *mockStatusProvider) InputStatus() []models.InputStatus {
+	return p.inputs
+}
+
+func (p *mockStatusProvider) OutputStatus() []models.OutputStatus {
+	return p.outputs
+}
+
+func TestPluginChecks(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name             string
+		inputs           []models.InputStatus
+		outputs          []models.OutputStatus
+		expectedCode     int
+		expectedFailures []health.PluginFailure
+	}{
+		{
+			name: "healthy",
+			inputs: []models.InputStatus{
+				{ID: "in1", Name: "cpu", Interval: time.Minute, LastGather: models.GatherStatus{LastSuccess: now}},
+			},
+			outputs: []models.OutputStatus{
+				{ID: "out1", Name: "file", BufferSize: 10, BufferLimit: 100, BufferFill: 10, BufferLimited: true, ConsecutiveFailures: 1},
+				{ID: "out2", Name: "file", BufferSize: 1000},
+			},
+			expectedCode:     200,
+			expectedFailures: []health.PluginFailure{},
+		},
+		{
+			name: "buffer full",
+			outputs: []models.OutputStatus{
+				{ID: "out1", Name: "file", Alias: "local", BufferSize: 80, BufferLimit: 100, BufferFill: 80, BufferLimited: true},
+				{ID: "out2", Name: "file", BufferSize: 5000, BufferFill: 90, BufferLimited: true},
+			},
+			expectedCode: 503,
+			expectedFailures: []health.PluginFailure{
+				{
+					Plugin:  "outputs.file::local",
+					ID:      "out1",
+					Check:   "buffer_fill",
+					Message: "buffer 80.0% full (80 of 100 metrics)",
+				},
+				{
+					Plugin:  "outputs.file",
+					ID:      "out2",
+					Check:   "buffer_fill",
+					Message: "buffer 90.0% full (5000 metrics)",
+				},
+			},
+		},
+		{
+			name: "write failures",
+			outputs: []models.OutputStatus{
+				{ID: "out1", Name: "file", BufferLimit: 100, ConsecutiveFailures: 3},
+			},
+			expectedCode: 503,
+			expectedFailures: []health.PluginFailure{
+				{
+					Plugin:  "outputs.file",
+					ID:      "out1",
+					Check:   "write_failures",
+					Message: "3 consecutive failed writes",
+				},
+			},
+		},
+		{
+			name: "missed gathers",
+			inputs: []models.InputStatus{
+				{
+					ID:       "in1",
+					Name:     "cpu",
+					Interval: time.Minute,
+					LastGather: models.GatherStatus{
+						Err:         errors.New("broken"),
+						LastSuccess: now.Add(-5*time.Minute - time.Second),
+					},
+				},
+				{
+					ID:         "in2",
+					Name:       "mem",
+					Interval:   time.Minute,
+					Paused:     true,
+					LastGather: models.GatherStatus{LastSuccess: now.Add(-time.Hour)},
+				},
+			},
+			expectedCode: 503,
+			expectedFailures: []health.PluginFailure{
+				{
+					Plugin:  "inputs.cpu",
+					ID:      "in1",
+					Check:   "missed_gathers",
+					Message: "no successful gather for 5 intervals, last error: broken",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dut := health.NewHealth()
+			dut.ServiceAddress = "tcp://127.0.0.1:0"
+			dut.Log = testutil.Logger{}
+			dut.MaxBufferFill = 75
+			dut.MaxConsecutiveWriteFailures = 3
+			dut.MaxMissedGathers = 3
+			dut.SetStatusProvider(&mockStatusProvider{inputs: tt.inputs, outputs: tt.outputs})
+			require.NoError(t, dut.Init())
+			require.NoError(t, dut.Connect())
+			defer dut.Close()
+
+			resp, err := http.Get(dut.Origin())
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.expectedCode, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var report health.StatusReport
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			require.Equal(t, tt.expectedCode == 200, report.Healthy)
+			require.Equal(t, tt.expectedFailures, report.Failures)
+		})
+	}
+}
+
+func TestPluginChecksWithoutAgent(t *testing.T) {
+	dut := health.NewHealth()
+	dut.MaxBufferFill = 75
+	require.ErrorContains(t, dut.Init(), "plugin checks require the status of the agent")
+}
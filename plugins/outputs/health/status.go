@@ -0,0 +1,99 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// PluginFailure describes a plugin failing one of the agent checks.
+type PluginFailure struct {
+	Plugin  string `json:"plugin"`
+	ID      string `json:"id"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// StatusReport is the response body if agent checks are enabled.
+type StatusReport struct {
+	Healthy  bool            `json:"healthy"`
+	Failures []PluginFailure `json:"failures"`
+}
+
+// SetStatusProvider implements models.StatusConsumer to access the status of
+// the running plugins.
+func (h *Health) SetStatusProvider(provider models.StatusProvider) {
+	h.status = provider
+}
+
+func (h *Health) hasStatusChecks() bool {
+	return h.MaxBufferFill > 0 || h.MaxConsecutiveWriteFailures > 0 || h.MaxMissedGathers > 0
+}
+
+// checkStatus evaluates the agent checks against the status of the running
+// plugins and returns the failing plugins.
+func (h *Health) checkStatus(now time.Time) []PluginFailure {
+	failures := make([]PluginFailure, 0)
+
+	if h.MaxMissedGathers > 0 {
+		for _, input := range h.status.InputStatus() {
+			if input.Paused || input.Interval <= 0 {
+				continue
+			}
+
+			// Inputs without successful gather are measured from startup
+			last := input.LastGather.LastSuccess
+			if last.IsZero() {
+				last = h.startTime
+			}
+			missed := int64(now.Sub(last) / input.Interval)
+			if missed < h.MaxMissedGathers {
+				continue
+			}
+
+			msg := fmt.Sprintf("no successful gather for %d intervals", missed)
+			if input.LastGather.Err != nil {
+				msg += ", last error: " + input.LastGather.Err.Error()
+			}
+			failures = append(failures, PluginFailure{
+				Plugin:  pluginName("inputs", input.Name, input.Alias),
+				ID:      input.ID,
+				Check:   "missed_gathers",
+				Message: msg,
+			})
+		}
+	}
+
+	for _, output := range h.status.OutputStatus() {
+		if h.MaxBufferFill > 0 && output.BufferLimited && output.BufferFill >= h.MaxBufferFill {
+			msg := fmt.Sprintf("buffer %.1f%% full (%d metrics)", output.BufferFill, output.BufferSize)
+			if output.BufferLimit > 0 {
+				msg = fmt.Sprintf("buffer %.1f%% full (%d of %d metrics)", output.BufferFill, output.BufferSize, output.BufferLimit)
+			}
+			failures = append(failures, PluginFailure{
+				Plugin:  pluginName("outputs", output.Name, output.Alias),
+				ID:      output.ID,
+				Check:   "buffer_fill",
+				Message: msg,
+			})
+		}
+		if h.MaxConsecutiveWriteFailures > 0 && output.ConsecutiveFailures >= h.MaxConsecutiveWriteFailures {
+			failures = append(failures, PluginFailure{
+				Plugin:  pluginName("outputs", output.Name, output.Alias),
+				ID:      output.ID,
+				Check:   "write_failures",
+				Message: fmt.Sprintf("%d consecutive failed writes", output.ConsecutiveFailures),
+			})
+		}
+	}
+
+	return failures
+}
+
+func pluginName(category, name, alias string) string {
+	if alias == "" {
+		return category + "." + name
+	}
+	return category + "." + name + "::" + alias
+}
@@ -0,0 +1,248 @@
+package arrow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// fileMagic marks the beginning of data in Arrow IPC file format
+var fileMagic = []byte("ARROW1")
+
+// measurementMetadataKey is the key of the schema metadata entry holding the
+// name of the measurement as written by the arrow serializer
+const measurementMetadataKey = "measurement"
+
+type Parser struct {
+	MeasurementColumn string   `toml:"arrow_measurement_column"`
+	TagColumns        []string `toml:"arrow_tag_columns"`
+	TimestampColumn   string   `toml:"arrow_timestamp_column"`
+	TimestampFormat   string   `toml:"arrow_timestamp_format"`
+	TimestampTimezone string   `toml:"arrow_timestamp_timezone"`
+
+	defaultTags map[string]string
+	location    *time.Location
+	metricName  string
+}
+
+func (p *Parser) Init() error {
+	if p.TimestampColumn == "" {
+		p.TimestampColumn = "time"
+	}
+	if p.TimestampFormat == "" {
+		p.TimestampFormat = "unix"
+	}
+	if p.TimestampTimezone == "" {
+		p.location = time.UTC
+	} else {
+		loc, err := time.LoadLocation(p.TimestampTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid location %s: %w", p.TimestampTimezone, err)
+		}
+		p.location = loc
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if bytes.HasPrefix(buf, fileMagic) {
+		return p.parseFile(buf)
+	}
+	return p.parseStreams(buf)
+}
+
+func (p *Parser) parseFile(buf []byte) ([]telegraf.Metric, error) {
+	reader, err := ipc.NewFileReader(bytes.NewReader(buf), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create arrow file reader: %w", err)
+	}
+	defer reader.Close()
+
+	now := time.Now()
+	var metrics []telegraf.Metric
+	for i := 0; i < reader.NumRecords(); i++ {
+		record, err := reader.Record(i)
+		if err != nil {
+			return nil, fmt.Errorf("reading record batch %d failed: %w", i, err)
+		}
+		m, err := p.parseRecord(record, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+// parseStreams reads all consecutive streams contained in the buffer as the
+// serializer writes one stream per measurement
+func (p *Parser) parseStreams(buf []byte) ([]telegraf.Metric, error) {
+	r := bytes.NewReader(buf)
+
+	now := time.Now()
+	var metrics []telegraf.Metric
+	for r.Len() > 0 {
+		reader, err := ipc.NewReader(r, ipc.WithAllocator(memory.DefaultAllocator))
+		if err != nil {
+			return nil, fmt.Errorf("unable to create arrow stream reader: %w", err)
+		}
+		for reader.Next() {
+			m, err := p.parseRecord(reader.Record(), now)
+			if err != nil {
+				reader.Release()
+				return nil, err
+			}
+			metrics = append(metrics, m...)
+		}
+		err = reader.Err()
+		reader.Release()
+		if err != nil {
+			return nil, fmt.Errorf("reading record batch failed: %w", err)
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) parseRecord(record arrow.Record, now time.Time) ([]telegraf.Metric, error) {
+	schema := record.Schema()
+
+	name := p.metricName
+	if idx := schema.Metadata().FindKey(measurementMetadataKey); idx >= 0 {
+		name = schema.Metadata().Values()[idx]
+	}
+
+	metrics := make([]telegraf.Metric, 0, record.NumRows())
+	for row := 0; row < int(record.NumRows()); row++ {
+		m := metric.New(name, p.defaultTags, nil, now)
+		for col, field := range schema.Fields() {
+			column := record.Column(col)
+			if column.IsNull(row) {
+				continue
+			}
+			value, err := columnValue(column, row)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", field.Name, err)
+			}
+
+			switch {
+			case p.MeasurementColumn != "" && field.Name == p.MeasurementColumn:
+				valStr, err := internal.ToString(value)
+				if err != nil {
+					return nil, fmt.Errorf("could not convert value to string: %w", err)
+				}
+				m.SetName(valStr)
+			case field.Name == p.TimestampColumn:
+				if ts, ok := value.(time.Time); ok {
+					m.SetTime(ts)
+					continue
+				}
+				valStr, err := internal.ToString(value)
+				if err != nil {
+					return nil, fmt.Errorf("could not convert value to string: %w", err)
+				}
+				timestamp, err := internal.ParseTimestamp(p.TimestampFormat, valStr, p.location)
+				if err != nil {
+					return nil, fmt.Errorf("could not parse '%s' to '%s'", valStr, p.TimestampFormat)
+				}
+				m.SetTime(timestamp)
+			case field.Type.ID() == arrow.DICTIONARY || slices.Contains(p.TagColumns, field.Name):
+				valStr, err := internal.ToString(value)
+				if err != nil {
+					return nil, fmt.Errorf("could not convert value to string: %w", err)
+				}
+				m.AddTag(field.Name, valStr)
+			default:
+				m.AddField(field.Name, value)
+			}
+		}
+
+		// Skip rows without any field as those cannot form a metric
+		if len(m.FieldList()) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+// columnValue returns the value of the column at the given row
+func columnValue(column arrow.Array, row int) (interface{}, error) {
+	switch c := column.(type) {
+	case *array.Boolean:
+		return c.Value(row), nil
+	case *array.Int8:
+		return int64(c.Value(row)), nil
+	case *array.Int16:
+		return int64(c.Value(row)), nil
+	case *array.Int32:
+		return int64(c.Value(row)), nil
+	case *array.Int64:
+		return c.Value(row), nil
+	case *array.Uint8:
+		return uint64(c.Value(row)), nil
+	case *array.Uint16:
+		return uint64(c.Value(row)), nil
+	case *array.Uint32:
+		return uint64(c.Value(row)), nil
+	case *array.Uint64:
+		return c.Value(row), nil
+	case *array.Float16:
+		return float64(c.Value(row).Float32()), nil
+	case *array.Float32:
+		return float64(c.Value(row)), nil
+	case *array.Float64:
+		return c.Value(row), nil
+	case *array.String:
+		return c.Value(row), nil
+	case *array.LargeString:
+		return c.Value(row), nil
+	case *array.Binary:
+		return string(c.Value(row)), nil
+	case *array.Timestamp:
+		unit := c.DataType().(*arrow.TimestampType).Unit
+		return c.Value(row).ToTime(unit), nil
+	case *array.Dictionary:
+		return columnValue(c.Dictionary(), c.GetValueIndex(row))
+	}
+	return nil, errors.New("unsupported column type " + column.DataType().String())
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, nil
+	}
+	if len(metrics) > 1 {
+		return nil, errors.New("line contains multiple metrics")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+}
+
+func init() {
+	parsers.Add("arrow",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
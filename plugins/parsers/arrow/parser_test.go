@@ -0,0 +1,125 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// createRecord builds a record with columns of various types not produced by
+// the arrow serializer
+func createRecord(t *testing.T) arrow.Record {
+	t.Helper()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "ts", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "host", Type: arrow.BinaryTypes.LargeString},
+		{Name: "small", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "ratio", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "counter", Type: arrow.PrimitiveTypes.Uint16, Nullable: true},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	t.Cleanup(builder.Release)
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"disk", "disk", "net"}, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues([]int64{1700000000, 1700000010, 1700000020}, nil)
+	builder.Field(2).(*array.LargeStringBuilder).AppendValues([]string{"a", "b", "c"}, nil)
+	builder.Field(3).(*array.Int32Builder).AppendValues([]int32{1, 0, 3}, []bool{true, false, false})
+	builder.Field(4).(*array.Float32Builder).AppendValues([]float32{0.5, 0, 0}, []bool{true, false, false})
+	builder.Field(5).(*array.Uint16Builder).AppendValues([]uint16{7, 8, 0}, []bool{true, true, false})
+
+	record := builder.NewRecord()
+	t.Cleanup(record.Release)
+	return record
+}
+
+func TestParseColumns(t *testing.T) {
+	record := createRecord(t)
+
+	var stream bytes.Buffer
+	writer := ipc.NewWriter(&stream, ipc.WithSchema(record.Schema()))
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.Close())
+
+	var file bytes.Buffer
+	fileWriter, err := ipc.NewFileWriter(&file, ipc.WithSchema(record.Schema()))
+	require.NoError(t, err)
+	require.NoError(t, fileWriter.Write(record))
+	require.NoError(t, fileWriter.Close())
+
+	// The last row does not contain any field and is skipped
+	expected := []telegraf.Metric{
+		metric.New(
+			"disk",
+			map[string]string{"host": "a", "source": "test"},
+			map[string]interface{}{"small": int64(1), "ratio": 0.5, "counter": uint64(7)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "b", "source": "test"},
+			map[string]interface{}{"counter": uint64(8)},
+			time.Unix(1700000010, 0),
+		),
+	}
+
+	for name, buf := range map[string][]byte{"stream": stream.Bytes(), "file": file.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			p := &Parser{
+				MeasurementColumn: "name",
+				TagColumns:        []string{"host"},
+				TimestampColumn:   "ts",
+			}
+			require.NoError(t, p.Init())
+			p.SetDefaultTags(map[string]string{"source": "test"})
+
+			actual, err := p.Parse(buf)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestParseDefaultName(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "value", Type: arrow.PrimitiveTypes.Float64}}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Float64Builder).Append(42)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.Close())
+
+	p := &Parser{metricName: "arrow"}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(buf.String())
+	require.NoError(t, err)
+	require.Equal(t, "arrow", m.Name())
+	require.Equal(t, map[string]interface{}{"value": 42.0}, m.Fields())
+}
+
+func TestParseInvalid(t *testing.T) {
+	p := &Parser{}
+	require.NoError(t, p.Init())
+
+	_, err := p.Parse([]byte("not arrow data"))
+	require.ErrorContains(t, err, "unable to create arrow stream reader")
+
+	_, err = p.Parse([]byte("ARROW1 but truncated"))
+	require.ErrorContains(t, err, "unable to create arrow file reader")
+}
@@ -236,6 +236,25 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return metrics, err
 }
 
+// ParseStream parses the CSV data read from the reader and passes the metrics
+// to the given function record by record.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	// Reset the parser according to the specified mode
+	if p.ResetMode == "always" {
+		p.Reset()
+	}
+	// If using an invalid delimiter, replace commas with replacement and
+	// invalid delimiter with commas
+	if p.invalidDelimiter {
+		r = &delimiterReplacer{reader: bufio.NewReader(r), delimiter: []byte(p.Delimiter)}
+	}
+	err := p.parseCSVStream(r, fn)
+	if err != nil && errors.Is(err, io.EOF) {
+		return parsers.ErrEOF
+	}
+	return err
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	if len(line) == 0 {
 		if p.remainingSkipRows > 0 {
@@ -265,12 +284,24 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 }
 
 func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+	err := p.parseCSVStream(r, func(m telegraf.Metric) error {
+		metrics = append(metrics, m)
+		return nil
+	})
+	if err != nil && len(metrics) == 0 {
+		return nil, err
+	}
+	return metrics, err
+}
+
+func (p *Parser) parseCSVStream(r io.Reader, fn func(telegraf.Metric) error) error {
 	lineReader := bufio.NewReader(r)
 	// skip first rows
 	for p.remainingSkipRows > 0 {
 		line, err := lineReader.ReadString('\n')
 		if err != nil && len(line) == 0 {
-			return nil, err
+			return err
 		}
 		p.remainingSkipRows--
 	}
@@ -278,7 +309,7 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 	for p.remainingMetadataRows > 0 {
 		line, err := lineReader.ReadString('\n')
 		if err != nil && len(line) == 0 {
-			return nil, err
+			return err
 		}
 		p.remainingMetadataRows--
 		m := p.parseMetadataRow(line)
@@ -295,7 +326,7 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 	for p.remainingHeaderRows > 0 {
 		header, err := csvReader.Read()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		p.remainingHeaderRows--
 		if p.gotColumnNames {
@@ -320,24 +351,54 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 		p.gotColumnNames = true
 	}
 
-	table, err := csvReader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-	metrics := make([]telegraf.Metric, 0)
-	for _, record := range table {
 		m, err := p.parseRecord(record)
 		if err != nil {
 			if p.SkipErrors {
 				p.Log.Debugf("Parsing error: %v", err)
 				continue
 			}
-			return metrics, err
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+}
+
+// delimiterReplacer replaces commas with the replacement character and the
+// invalid delimiter with commas line by line while reading
+type delimiterReplacer struct {
+	reader    *bufio.Reader
+	delimiter []byte
+	buf       []byte
+}
+
+func (d *delimiterReplacer) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		line, err := d.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.ReplaceAll(line, []byte(commaByte), []byte(replacementByte))
+			d.buf = bytes.ReplaceAll(line, d.delimiter, []byte(commaByte))
+		}
+		if err != nil {
+			if len(d.buf) == 0 {
+				return 0, err
+			}
+			break
 		}
-		metrics = append(metrics, m)
 	}
-	return metrics, nil
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
 }
 
 func (p *Parser) parseRecord(record []string) (telegraf.Metric, error) {
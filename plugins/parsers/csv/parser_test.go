@@ -525,6 +525,79 @@ func TestParseStream(t *testing.T) {
 		), m)
 }
 
+func TestParseStreamReader(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		SkipRows:       1,
+		TagColumns:     []string{"host"},
+		TimeFunc:       DefaultTime,
+	}
+	require.NoError(t, p.Init())
+
+	input := "skipped line\nhost,value\na,1\nb,2\n"
+	var actual []telegraf.Metric
+	require.NoError(t, p.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+
+	expected := []telegraf.Metric{
+		metric.New("csv", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, DefaultTime()),
+		metric.New("csv", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(2)}, DefaultTime()),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The parser state is kept for subsequent streams
+	actual = nil
+	require.NoError(t, p.ParseStream(strings.NewReader("c,3\n"), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+	expected = []telegraf.Metric{
+		metric.New("csv", map[string]string{"host": "c"}, map[string]interface{}{"value": int64(3)}, DefaultTime()),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseStreamInvalidDelimiter(t *testing.T) {
+	p := &Parser{
+		Delimiter:   "\u0000",
+		ColumnNames: []string{"first", "second"},
+		TimeFunc:    DefaultTime,
+	}
+	require.NoError(t, p.Init())
+
+	input := "3,4\u000070\n5\u0000test,name\n"
+	var actual []telegraf.Metric
+	require.NoError(t, p.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+
+	expected, err := p.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseStreamStopsOnError(t *testing.T) {
+	p := &Parser{
+		ColumnNames: []string{"value"},
+		ColumnTypes: []string{"int"},
+		TimeFunc:    DefaultTime,
+	}
+	require.NoError(t, p.Init())
+
+	var count int
+	err := p.ParseStream(strings.NewReader("1\nfoo\n3\n"), func(telegraf.Metric) error {
+		count++
+		return nil
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, count)
+}
+
 func TestParseLineMultiMetricErrorMessage(t *testing.T) {
 	p := &Parser{
 		MetricName:     "csv",
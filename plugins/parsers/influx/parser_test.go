@@ -752,6 +752,76 @@ func TestStreamParser(t *testing.T) {
 	}
 }
 
+func TestParseStream(t *testing.T) {
+	for _, tt := range ptests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := Parser{}
+			require.NoError(t, parser.Init())
+			parser.SetTimeFunc(DefaultTime)
+			if tt.timeFunc != nil {
+				parser.SetTimeFunc(tt.timeFunc)
+			}
+
+			var metrics []telegraf.Metric
+			err := parser.ParseStream(bytes.NewBuffer(tt.input), func(m telegraf.Metric) error {
+				metrics = append(metrics, m)
+				return nil
+			})
+			if tt.err != nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.metrics, metrics)
+		})
+	}
+}
+
+func TestParseStreamDefaultTags(t *testing.T) {
+	parser := Parser{}
+	require.NoError(t, parser.Init())
+	parser.SetTimeFunc(DefaultTime)
+	parser.SetDefaultTags(map[string]string{"host": "localhost", "region": "us-east"})
+
+	input := "cpu,host=a value=42\nmem value=1i\n"
+	var metrics []telegraf.Metric
+	require.NoError(t, parser.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		metrics = append(metrics, m)
+		return nil
+	}))
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "region": "us-east"}, map[string]interface{}{"value": 42.0}, DefaultTime()),
+		metric.New("mem", map[string]string{"host": "localhost", "region": "us-east"}, map[string]interface{}{"value": int64(1)}, DefaultTime()),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseStreamStopsOnError(t *testing.T) {
+	parser := Parser{}
+	require.NoError(t, parser.Init())
+
+	// Metrics before a parse error are passed on
+	var count int
+	err := parser.ParseStream(strings.NewReader("cpu value=42\ncpu value=\ncpu value=43\n"), func(telegraf.Metric) error {
+		count++
+		return nil
+	})
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, 1, count)
+
+	// Errors of the function stop parsing
+	count = 0
+	stop := errors.New("stop")
+	err = parser.ParseStream(strings.NewReader("cpu value=42\ncpu value=43\n"), func(telegraf.Metric) error {
+		count++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, count)
+}
+
 func TestSeriesParser(t *testing.T) {
 	var tests = []struct {
 		name     string
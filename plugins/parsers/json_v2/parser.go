@@ -1,6 +1,7 @@
 package json_v2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -131,6 +132,34 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	return p.parseCriticalPath(input)
 }
 
+// ParseStream parses a stream of consecutive JSON documents, e.g. newline
+// delimited JSON, read from the reader. Each document is parsed like in Parse
+// and its metrics are passed to the given function, so only a single document
+// is kept in memory at a time.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	body, _ := utfbom.Skip(r)
+	decoder := json.NewDecoder(body)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid JSON provided, unable to parse: %w", err)
+		}
+
+		metrics, err := p.parseCriticalPath(document)
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 	p.parseMutex.Lock()
 	defer p.parseMutex.Unlock()
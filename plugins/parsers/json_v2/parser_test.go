@@ -105,6 +105,36 @@ func TestParserEmptyConfig(t *testing.T) {
 	require.ErrorContains(t, plugin.Init(), "no configuration provided")
 }
 
+func TestParseStream(t *testing.T) {
+	plugin := &json_v2.Parser{
+		Configs: []json_v2.Config{
+			{
+				MeasurementName: "stream",
+				Fields:          []json_v2.DataSet{{Path: "value", Type: "int"}},
+				Tags:            []json_v2.DataSet{{Path: "host"}},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := "\xef\xbb\xbf{\"host\": \"a\", \"value\": 1}\n{\"host\": \"b\", \"value\": 2}\n"
+	var actual []telegraf.Metric
+	require.NoError(t, plugin.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("stream", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 0)),
+		testutil.MustMetric("stream", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+
+	// Invalid documents stop parsing
+	err := plugin.ParseStream(strings.NewReader(`{"host": "a", "value": 1} {"host": `), func(telegraf.Metric) error { return nil })
+	require.ErrorContains(t, err, "invalid JSON provided")
+}
+
 func BenchmarkParsingSequential(b *testing.B) {
 	inputFilename := filepath.Join("testdata", "benchmark", "input.json")
 
@@ -1,9 +1,11 @@
 package value
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -95,6 +97,41 @@ func (v *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return []telegraf.Metric{m}, nil
 }
 
+// ParseStream parses the data read from the reader into a single metric like
+// Parse. Except for string and base64 data types, only the last value of the
+// data is kept in memory while reading.
+func (v *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	var buf []byte
+	if v.DataType == "string" || v.DataType == "base64" {
+		var err error
+		if buf, err = io.ReadAll(r); err != nil {
+			return err
+		}
+	} else {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(bufio.ScanWords)
+		for scanner.Scan() {
+			if len(bytes.Trim(scanner.Bytes(), "\x00")) > 0 {
+				buf = append(buf[:0], scanner.Bytes()...)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	metrics, err := v.Parse(buf)
+	if err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	metrics, err := v.Parse([]byte(line))
 
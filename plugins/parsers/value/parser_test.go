@@ -1,6 +1,7 @@
 package value
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -110,6 +111,14 @@ func TestParseValidValues(t *testing.T) {
 			require.NoError(t, err)
 			require.Len(t, actual, 1)
 			testutil.RequireMetricEqual(t, expected, actual[0], testutil.IgnoreTime())
+
+			// Streaming must produce the same result
+			var streamed []telegraf.Metric
+			require.NoError(t, plugin.ParseStream(bytes.NewReader(tt.input), func(m telegraf.Metric) error {
+				streamed = append(streamed, m)
+				return nil
+			}))
+			testutil.RequireMetricsEqual(t, actual, streamed, testutil.IgnoreTime())
 		})
 	}
 }
@@ -344,3 +353,19 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParseStreamTrailingNull(t *testing.T) {
+	plugin := Parser{
+		MetricName: "value_test",
+		DataType:   "integer",
+	}
+	require.NoError(t, plugin.Init())
+
+	var actual []telegraf.Metric
+	require.NoError(t, plugin.ParseStream(bytes.NewReader([]byte("23 42 \x00")), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, actual[0].Fields())
+}
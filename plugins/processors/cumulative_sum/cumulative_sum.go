@@ -31,6 +31,12 @@ type entry struct {
 	seen time.Time
 }
 
+// entryState is the serializable form of a cache entry
+type entryState struct {
+	Sums map[string]float64 `json:"sums"`
+	Seen time.Time          `json:"seen"`
+}
+
 func (*CumulativeSum) SampleConfig() string {
 	return sampleConfig
 }
@@ -99,6 +105,29 @@ func (c *CumulativeSum) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return out
 }
 
+func (c *CumulativeSum) GetState() interface{} {
+	state := make(map[uint64]entryState, len(c.cache))
+	for id, e := range c.cache {
+		// Copy the sums as those are modified when applying metrics
+		state[id] = entryState{Sums: maps.Clone(e.sums), Seen: e.seen}
+	}
+	return state
+}
+
+func (c *CumulativeSum) SetState(state interface{}) error {
+	entries, ok := state.(map[uint64]entryState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	for id, e := range entries {
+		if e.Sums == nil {
+			e.Sums = make(map[string]float64)
+		}
+		c.cache[id] = &entry{sums: e.Sums, seen: e.Seen}
+	}
+	return nil
+}
+
 func init() {
 	processors.Add("cumulative_sum", func() telegraf.Processor {
 		return &CumulativeSum{}
@@ -1,6 +1,7 @@
 package cumulative_sum
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -313,3 +314,45 @@ func TestCacheExpiry(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected3, actual, cmpopts.EquateApprox(0.0, 1e-9))
 	require.Len(t, plugin.cache, 2, "wrong number of cache entries")
 }
+
+func TestStatePersistence(t *testing.T) {
+	now := time.Now()
+	input := metric.New(
+		"foo",
+		map[string]string{"tag": "some tag"},
+		map[string]interface{}{"value": float64(1.5)},
+		now,
+	)
+
+	// Process a metric and persist the state
+	previous := &CumulativeSum{Log: testutil.Logger{}}
+	require.NoError(t, previous.Init())
+	previous.Apply(input.Copy())
+
+	var pi telegraf.StatefulPlugin = previous
+	serialized, err := json.Marshal(pi.GetState())
+	require.NoError(t, err)
+
+	// Restore the state and continue summing
+	var state map[uint64]entryState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	plugin := &CumulativeSum{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	pi = plugin
+	require.NoError(t, pi.SetState(state))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"foo",
+			map[string]string{"tag": "some tag"},
+			map[string]interface{}{
+				"value":     float64(1.5),
+				"value_sum": float64(3),
+			},
+			now,
+		),
+	}
+	actual := plugin.Apply(input.Copy())
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
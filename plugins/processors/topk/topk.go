@@ -12,7 +12,9 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
+	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
 //go:embed sample.conf
@@ -37,6 +39,12 @@ type TopK struct {
 	lastAggregation time.Time
 }
 
+// state is the serializable form of the cached metrics of the current period
+type state struct {
+	Cache           map[string][]byte `json:"cache"`
+	LastAggregation time.Time         `json:"last_aggregation"`
+}
+
 type metricAggregation struct {
 	groupByKey string
 	values     map[string]float64
@@ -101,6 +109,48 @@ func (t *TopK) Reset() {
 	t.lastAggregation = time.Now()
 }
 
+func (t *TopK) GetState() interface{} {
+	s := &serializers_influx.Serializer{UintSupport: true}
+	if err := s.Init(); err != nil {
+		t.Log.Errorf("Initializing serializer failed: %v", err)
+		return state{}
+	}
+
+	cache := make(map[string][]byte, len(t.cache))
+	for groupkey, metrics := range t.cache {
+		serialized, err := s.SerializeBatch(metrics)
+		if err != nil {
+			t.Log.Errorf("Serializing metrics of group %q failed: %v", groupkey, err)
+			continue
+		}
+		cache[groupkey] = serialized
+	}
+
+	return state{Cache: cache, LastAggregation: t.lastAggregation}
+}
+
+func (t *TopK) SetState(s interface{}) error {
+	st, ok := s.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", s)
+	}
+
+	p := &influx.Parser{}
+	if err := p.Init(); err != nil {
+		return err
+	}
+	for groupkey, serialized := range st.Cache {
+		metrics, err := p.Parse(serialized)
+		if err != nil {
+			return fmt.Errorf("parsing metrics of group %q failed: %w", groupkey, err)
+		}
+		t.cache[groupkey] = append(t.cache[groupkey], metrics...)
+	}
+	t.lastAggregation = st.LastAggregation
+
+	return nil
+}
+
 func sortMetrics(metrics []metricAggregation, field string, reverse bool) {
 	less := func(i, j int) bool {
 		iv := metrics[i].values[field]
@@ -1,6 +1,7 @@
 package topk
 
 import (
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -735,3 +736,39 @@ var metric26 = metric.New(
 )
 
 var metricsSet2 = []telegraf.Metric{metric21, metric22, metric23, metric24, metric25, metric26}
+
+func TestStatePersistence(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, now),
+		metric.New("m", map[string]string{"host": "b"}, map[string]interface{}{"value": uint64(5)}, now),
+		metric.New("m", map[string]string{"host": "c"}, map[string]interface{}{"value": 3.5}, now),
+	}
+
+	// Cache the metrics of the current period and persist the state
+	previous := newTopK()
+	previous.Period = config.Duration(time.Hour)
+	previous.K = 2
+	previous.Log = testutil.Logger{}
+	require.Empty(t, previous.Apply(input...))
+
+	var pi telegraf.StatefulPlugin = previous
+	serialized, err := json.Marshal(pi.GetState())
+	require.NoError(t, err)
+
+	// Restore the state and finish the period
+	var s state
+	require.NoError(t, json.Unmarshal(serialized, &s))
+
+	plugin := newTopK()
+	plugin.Period = config.Duration(time.Hour)
+	plugin.K = 2
+	plugin.Log = testutil.Logger{}
+	pi = plugin
+	require.NoError(t, pi.SetState(s))
+	require.WithinDuration(t, previous.lastAggregation, plugin.lastAggregation, 0)
+
+	plugin.Period = 0
+	expected := []telegraf.Metric{input[1], input[2]}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(), testutil.SortMetrics())
+}
@@ -74,6 +74,10 @@ func (*Docker) Set(_, _ string) error {
 	return errors.New("secret-store does not support creating secrets")
 }
 
+func (*Docker) Delete(string) error {
+	return errors.New("secret-store does not support deleting secrets")
+}
+
 func (d *Docker) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	resolver := func() ([]byte, bool, error) {
 		s, err := d.Get(key)
@@ -108,6 +108,10 @@ func (*HTTP) Set(_, _ string) error {
 	return errors.New("setting secrets not supported")
 }
 
+func (*HTTP) Delete(string) error {
+	return errors.New("deleting secrets not supported")
+}
+
 func (h *HTTP) List() ([]string, error) {
 	keys := make([]string, 0, len(h.cache))
 	for k := range h.cache {
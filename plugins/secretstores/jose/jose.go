@@ -90,6 +90,10 @@ func (j *Jose) Set(key, value string) error {
 	return j.ring.Set(item)
 }
 
+func (j *Jose) Delete(key string) error {
+	return j.ring.Remove(key)
+}
+
 func (j *Jose) List() ([]string, error) {
 	return j.ring.Keys()
 }
@@ -103,6 +103,27 @@ func TestSetListGet(t *testing.T) {
 	}
 }
 
+func TestDelete(t *testing.T) {
+	plugin := &Jose{
+		ID:       "test",
+		Password: config.NewSecret([]byte("test")),
+		Path:     t.TempDir(),
+	}
+	require.NoError(t, plugin.Init())
+
+	require.NoError(t, plugin.Set("foo", "bar"))
+	require.NoError(t, plugin.Set("bar", "baz"))
+	require.NoError(t, plugin.Delete("foo"))
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar"}, keys)
+
+	_, err = plugin.Get("foo")
+	require.Error(t, err)
+	require.Error(t, plugin.Delete("foo"))
+}
+
 func TestResolver(t *testing.T) {
 	secretKey := "a secret"
 	secretVal := "I won't tell"
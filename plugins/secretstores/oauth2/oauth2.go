@@ -171,6 +171,10 @@ func (*OAuth2) Set(_, _ string) error {
 	return errors.New("not supported")
 }
 
+func (*OAuth2) Delete(string) error {
+	return errors.New("not supported")
+}
+
 func (o *OAuth2) List() ([]string, error) {
 	keys := make([]string, 0, len(o.sources))
 	for k := range o.sources {
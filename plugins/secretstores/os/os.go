@@ -76,6 +76,10 @@ func (o *OS) Set(key, value string) error {
 	return o.ring.Set(item)
 }
 
+func (o *OS) Delete(key string) error {
+	return o.ring.Remove(key)
+}
+
 func (o *OS) List() ([]string, error) {
 	return o.ring.Keys()
 }
@@ -105,6 +105,10 @@ func (*Systemd) Set(_, _ string) error {
 	return errors.New("secret-store does not support creating secrets")
 }
 
+func (*Systemd) Delete(string) error {
+	return errors.New("secret-store does not support deleting secrets")
+}
+
 func (s *Systemd) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	resolver := func() ([]byte, bool, error) {
 		s, err := s.Get(key)
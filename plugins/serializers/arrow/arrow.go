@@ -0,0 +1,379 @@
+package arrow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// measurementMetadataKey is the key of the schema metadata entry holding the
+// name of the measurement contained in the record batches
+const measurementMetadataKey = "measurement"
+
+type Serializer struct {
+	Format            string          `toml:"arrow_format"`
+	TimestampColumn   string          `toml:"arrow_timestamp_column"`
+	MeasurementColumn string          `toml:"arrow_measurement_column"`
+	Compression       string          `toml:"arrow_compression"`
+	Log               telegraf.Logger `toml:"-"`
+
+	options []ipc.Option
+}
+
+func (s *Serializer) Init() error {
+	switch s.Format {
+	case "":
+		s.Format = "stream"
+	case "stream", "file":
+		// Do nothing as those are valid settings
+	default:
+		return fmt.Errorf("unknown 'arrow_format' %q", s.Format)
+	}
+
+	if s.TimestampColumn == "" {
+		s.TimestampColumn = "time"
+	}
+	if s.MeasurementColumn == "" {
+		s.MeasurementColumn = "measurement"
+	}
+	if s.Format == "file" && s.MeasurementColumn == s.TimestampColumn {
+		return fmt.Errorf("'arrow_measurement_column' %q collides with the timestamp column", s.MeasurementColumn)
+	}
+
+	s.options = []ipc.Option{ipc.WithAllocator(memory.DefaultAllocator)}
+	switch s.Compression {
+	case "", "none":
+	case "lz4":
+		s.options = append(s.options, ipc.WithLZ4())
+	case "zstd":
+		s.options = append(s.options, ipc.WithZstd())
+	default:
+		return fmt.Errorf("unknown 'arrow_compression' %q", s.Compression)
+	}
+
+	return nil
+}
+
+// columns contains the tag and field keys of the metrics of one measurement
+// to detect collisions between the columns of different metrics
+type columns struct {
+	tags   map[string]bool
+	fields map[string]bool
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	c := &columns{tags: make(map[string]bool), fields: make(map[string]bool)}
+	if err := s.check(c, m); err != nil {
+		return nil, err
+	}
+	return s.serialize(map[string][]telegraf.Metric{s.group(m): {m}})
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	// Group the metrics by the schema they are written with and skip the
+	// metrics not fitting into the schema of their group
+	byName := make(map[string][]telegraf.Metric)
+	known := make(map[string]*columns)
+	for _, m := range metrics {
+		group := s.group(m)
+		c, found := known[group]
+		if !found {
+			c = &columns{tags: make(map[string]bool), fields: make(map[string]bool)}
+			known[group] = c
+		}
+		if err := s.check(c, m); err != nil {
+			s.Log.Warnf("Skipping metric %q: %v", m.Name(), err)
+			continue
+		}
+		byName[group] = append(byName[group], m)
+	}
+	return s.serialize(byName)
+}
+
+// group returns the name of the schema the metric is written with. The
+// stream format uses one schema per measurement while the file format can
+// only hold a single schema, so all measurements share one schema with the
+// measurement name stored in the measurement column.
+func (s *Serializer) group(m telegraf.Metric) string {
+	if s.Format == "file" {
+		return ""
+	}
+	return m.Name()
+}
+
+// check verifies that the tags and fields of the metric do not collide with
+// the timestamp or measurement column or the columns of the previous metrics
+// with the same schema and adds the metric's columns
+func (s *Serializer) check(c *columns, m telegraf.Metric) error {
+	for _, tag := range m.TagList() {
+		if tag.Key == s.TimestampColumn {
+			return fmt.Errorf("tag %q collides with the timestamp column", tag.Key)
+		}
+		if s.Format == "file" && tag.Key == s.MeasurementColumn {
+			return fmt.Errorf("tag %q collides with the measurement column", tag.Key)
+		}
+		if c.fields[tag.Key] {
+			return fmt.Errorf("tag %q collides with the field of the same name", tag.Key)
+		}
+	}
+	for _, field := range m.FieldList() {
+		if field.Key == s.TimestampColumn {
+			return fmt.Errorf("field %q collides with the timestamp column", field.Key)
+		}
+		if s.Format == "file" && field.Key == s.MeasurementColumn {
+			return fmt.Errorf("field %q collides with the measurement column", field.Key)
+		}
+		if c.tags[field.Key] || m.HasTag(field.Key) {
+			return fmt.Errorf("field %q collides with the tag of the same name", field.Key)
+		}
+		if _, err := dataType(field.Value); err != nil {
+			return fmt.Errorf("field %q: %w", field.Key, err)
+		}
+	}
+
+	for _, tag := range m.TagList() {
+		c.tags[tag.Key] = true
+	}
+	for _, field := range m.FieldList() {
+		c.fields[field.Key] = true
+	}
+	return nil
+}
+
+// serialize writes the metrics of each schema group in the order of the
+// group names
+func (s *Serializer) serialize(byName map[string][]telegraf.Metric) ([]byte, error) {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		if err := s.write(&buf, name, byName[name]); err != nil {
+			if name == "" {
+				return nil, fmt.Errorf("serializing metrics failed: %w", err)
+			}
+			return nil, fmt.Errorf("serializing measurement %q failed: %w", name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// write creates a record batch containing the given metrics of one schema
+// group and writes it to the buffer as a complete stream or file
+func (s *Serializer) write(buf *bytes.Buffer, name string, metrics []telegraf.Metric) error {
+	schema, err := s.schema(name, metrics)
+	if err != nil {
+		return err
+	}
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	// Column 0 is the timestamp followed by the measurement for the file
+	// format and the tags and fields
+	columns := make(map[string]int, len(schema.Fields()))
+	for i, f := range schema.Fields() {
+		columns[f.Name] = i
+	}
+	for _, m := range metrics {
+		builder.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(m.Time().UnixNano()))
+
+		present := make([]bool, len(schema.Fields()))
+		present[0] = true
+		if s.Format == "file" {
+			if err := builder.Field(1).(*array.BinaryDictionaryBuilder).AppendString(m.Name()); err != nil {
+				return err
+			}
+			present[1] = true
+		}
+		for _, tag := range m.TagList() {
+			idx := columns[tag.Key]
+			if err := builder.Field(idx).(*array.BinaryDictionaryBuilder).AppendString(tag.Value); err != nil {
+				return err
+			}
+			present[idx] = true
+		}
+		for _, field := range m.FieldList() {
+			idx := columns[field.Key]
+			if err := appendValue(builder.Field(idx), field.Value); err != nil {
+				return fmt.Errorf("field %q: %w", field.Key, err)
+			}
+			present[idx] = true
+		}
+		for idx, ok := range present {
+			if !ok {
+				builder.Field(idx).AppendNull()
+			}
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	options := append([]ipc.Option{ipc.WithSchema(schema)}, s.options...)
+	switch s.Format {
+	case "stream":
+		writer := ipc.NewWriter(buf, options...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		return writer.Close()
+	case "file":
+		writer, err := ipc.NewFileWriter(buf, options...)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		return writer.Close()
+	}
+	return fmt.Errorf("unknown format %q", s.Format)
+}
+
+// schema determines the schema for the metrics of one schema group. Tags are
+// dictionary-encoded string columns and fields use the type of their values.
+// Fields with conflicting types are stored as float if all values are numeric
+// and as strings otherwise. The file format holds the measurement in a
+// dictionary-encoded column while the stream format stores the measurement in
+// the schema metadata.
+func (s *Serializer) schema(name string, metrics []telegraf.Metric) (*arrow.Schema, error) {
+	tags := make(map[string]bool)
+	fields := make(map[string]arrow.DataType)
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			tags[tag.Key] = true
+		}
+		for _, field := range m.FieldList() {
+			dt, err := dataType(field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", field.Key, err)
+			}
+			if existing, found := fields[field.Key]; found && !arrow.TypeEqual(existing, dt) {
+				dt = commonType(existing, dt)
+			}
+			fields[field.Key] = dt
+		}
+	}
+
+	columns := make([]arrow.Field, 0, 2+len(tags)+len(fields))
+	columns = append(columns, arrow.Field{Name: s.TimestampColumn, Type: arrow.FixedWidthTypes.Timestamp_ns})
+	if s.Format == "file" {
+		columns = append(columns, arrow.Field{
+			Name: s.MeasurementColumn,
+			Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String},
+		})
+	}
+
+	tagKeys := make([]string, 0, len(tags))
+	for k := range tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		columns = append(columns, arrow.Field{
+			Name:     k,
+			Type:     &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String},
+			Nullable: true,
+		})
+	}
+
+	fieldKeys := make([]string, 0, len(fields))
+	for k := range fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+	for _, k := range fieldKeys {
+		columns = append(columns, arrow.Field{Name: k, Type: fields[k], Nullable: true})
+	}
+
+	if s.Format == "file" {
+		return arrow.NewSchema(columns, nil), nil
+	}
+	metadata := arrow.NewMetadata([]string{measurementMetadataKey}, []string{name})
+	return arrow.NewSchema(columns, &metadata), nil
+}
+
+func dataType(value interface{}) (arrow.DataType, error) {
+	switch value.(type) {
+	case int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case string:
+		return arrow.BinaryTypes.String, nil
+	case bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+func commonType(a, b arrow.DataType) arrow.DataType {
+	if arrow.IsInteger(a.ID()) || arrow.IsFloating(a.ID()) {
+		if arrow.IsInteger(b.ID()) || arrow.IsFloating(b.ID()) {
+			return arrow.PrimitiveTypes.Float64
+		}
+	}
+	return arrow.BinaryTypes.String
+}
+
+func appendValue(builder array.Builder, value interface{}) error {
+	switch b := builder.(type) {
+	case *array.Int64Builder:
+		v, err := internal.ToInt64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.Uint64Builder:
+		v, err := internal.ToUint64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.Float64Builder:
+		v, err := internal.ToFloat64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.StringBuilder:
+		v, err := internal.ToString(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.BooleanBuilder:
+		v, err := internal.ToBool(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	default:
+		return errors.New("unexpected column type")
+	}
+	return nil
+}
+
+func init() {
+	serializers.Add("arrow",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
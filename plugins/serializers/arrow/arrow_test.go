@@ -0,0 +1,212 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	parser "github.com/influxdata/telegraf/plugins/parsers/arrow"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRoundtrip(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "count": int64(4), "throttled": false},
+			time.Unix(1700000000, 1),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"free": uint64(1024), "state": "ok"},
+			time.Unix(1700000000, 2),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"usage_idle": 12.0},
+			time.Unix(1700000010, 3),
+		),
+	}
+
+	tests := []struct {
+		name              string
+		serializer        *Serializer
+		measurementColumn string
+	}{
+		{
+			name:       "stream",
+			serializer: &Serializer{},
+		},
+		{
+			name:       "stream zstd",
+			serializer: &Serializer{Compression: "zstd"},
+		},
+		{
+			name:       "stream lz4",
+			serializer: &Serializer{Compression: "lz4"},
+		},
+		{
+			name:              "file",
+			serializer:        &Serializer{Format: "file"},
+			measurementColumn: "measurement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.serializer.Init())
+			buf, err := tt.serializer.SerializeBatch(input)
+			require.NoError(t, err)
+
+			p := &parser.Parser{MeasurementColumn: tt.measurementColumn}
+			require.NoError(t, p.Init())
+			actual, err := p.Parse(buf)
+			require.NoError(t, err)
+
+			// The stream format reorders the metrics by measurement
+			testutil.RequireMetricsEqual(t, input, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	serializer := &Serializer{}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{"b": "x", "a": "y"}, map[string]interface{}{"value": int64(1), "mixed": int64(1)}, time.Unix(0, 0)),
+		metric.New("test", nil, map[string]interface{}{"value": 1.5, "mixed": "text"}, time.Unix(0, 0)),
+	}
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	reader, err := ipc.NewReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	schema := reader.Schema()
+	dict := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: arrow.FixedWidthTypes.Timestamp_ns},
+		{Name: "a", Type: dict, Nullable: true},
+		{Name: "b", Type: dict, Nullable: true},
+		{Name: "mixed", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	require.True(t, expected.Equal(schema), "unexpected schema %v", schema)
+	require.Equal(t, []string{"test"}, schema.Metadata().Values())
+
+	require.True(t, reader.Next())
+	record := reader.Record()
+	require.Equal(t, int64(2), record.NumRows())
+	require.True(t, record.Column(1).IsNull(1))
+	require.False(t, reader.Next())
+}
+
+func TestSerializeErrors(t *testing.T) {
+	serializer := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	_, err := serializer.Serialize(metric.New("cpu", map[string]string{"value": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `field "value" collides with the tag of the same name`)
+
+	_, err = serializer.Serialize(metric.New("cpu", nil, map[string]interface{}{"time": 1}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `field "time" collides with the timestamp column`)
+
+	_, err = serializer.Serialize(metric.New("cpu", map[string]string{"time": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `tag "time" collides with the timestamp column`)
+}
+
+func TestSerializeBatchSkipInvalid(t *testing.T) {
+	serializer := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	// Metrics colliding with the timestamp column or with the columns of
+	// previous metrics of the same measurement are skipped
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+		metric.New("cpu", map[string]string{"value": "a"}, map[string]interface{}{"other": 2.0}, time.Unix(0, 2)),
+		metric.New("cpu", nil, map[string]interface{}{"host": "b", "value": 3.0}, time.Unix(0, 3)),
+		metric.New("cpu", nil, map[string]interface{}{"time": 4.0}, time.Unix(0, 4)),
+		metric.New("mem", nil, map[string]interface{}{"host": "c"}, time.Unix(0, 5)),
+		metric.New("cpu", map[string]string{"host": "d"}, map[string]interface{}{"value": 6.0}, time.Unix(0, 6)),
+	}
+	buf, err := serializer.SerializeBatch(input)
+	require.NoError(t, err)
+
+	p := &parser.Parser{}
+	require.NoError(t, p.Init())
+	actual, err := p.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{input[0], input[5], input[4]}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSerializeFileMultipleMeasurements(t *testing.T) {
+	serializer := &Serializer{Format: "file", Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	// The file format holds all measurements in a single schema with the
+	// measurement name in a separate column
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+		metric.New("mem", nil, map[string]interface{}{"value": 2.0, "used": int64(3)}, time.Unix(0, 2)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 3)),
+		metric.New("disk", map[string]string{"measurement": "x"}, map[string]interface{}{"value": 4.0}, time.Unix(0, 4)),
+	}
+	buf, err := serializer.SerializeBatch(input)
+	require.NoError(t, err)
+
+	reader, err := ipc.NewFileReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, 1, reader.NumRecords())
+
+	p := &parser.Parser{MeasurementColumn: "measurement"}
+	require.NoError(t, p.Init())
+	actual, err := p.Parse(buf)
+	require.NoError(t, err)
+
+	// Metrics colliding with the measurement column are skipped
+	expected := input[:3]
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
+
+func TestInitErrors(t *testing.T) {
+	require.ErrorContains(t, (&Serializer{Format: "feather"}).Init(), `unknown 'arrow_format' "feather"`)
+	require.ErrorContains(t, (&Serializer{Compression: "gzip"}).Init(), `unknown 'arrow_compression' "gzip"`)
+	require.ErrorContains(t,
+		(&Serializer{Format: "file", MeasurementColumn: "time"}).Init(),
+		`'arrow_measurement_column' "time" collides with the timestamp column`,
+	)
+}
+
+func BenchmarkSerializeBatch(b *testing.B) {
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := range 1000 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": float64(i), "count": int64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	serializer := &Serializer{}
+	require.NoError(b, serializer.Init())
+
+	for b.Loop() {
+		_, err := serializer.SerializeBatch(metrics)
+		require.NoError(b, err)
+	}
+}
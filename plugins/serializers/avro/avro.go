@@ -0,0 +1,486 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// If SchemaRegistry is set, the schema is registered with the registry and
+// the output will be in Confluent Wire Format
+// (https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format).
+
+// If Schema is set, the metrics are encoded using the given record schema,
+// otherwise a schema is derived from each metric.
+
+type Serializer struct {
+	SchemaRegistry   string          `toml:"avro_schema_registry"`
+	CaCertPath       string          `toml:"avro_schema_registry_cert"`
+	Subject          string          `toml:"avro_schema_subject"`
+	Schema           string          `toml:"avro_schema"`
+	Format           string          `toml:"avro_format"`
+	MeasurementField string          `toml:"avro_measurement_field"`
+	Tags             []string        `toml:"avro_tags"`
+	Fields           []string        `toml:"avro_fields"`
+	Timestamp        string          `toml:"avro_timestamp"`
+	TimestampFormat  string          `toml:"avro_timestamp_format"`
+	Log              telegraf.Logger `toml:"-"`
+
+	registry *schemaRegistry
+	static   *schemaAndCodec
+
+	// Codecs of derived schemas indexed by the schema
+	cache map[string]*schemaAndCodec
+	mu    sync.Mutex
+}
+
+// recordSchema contains the parts of an Avro record schema required for
+// encoding metrics
+type recordSchema struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Fields    []recordField `json:"fields"`
+}
+
+type recordField struct {
+	Name string      `json:"name"`
+	Type interface{} `json:"type"`
+}
+
+// registryError is an error of the schema registry affecting all metrics
+// independent of their content
+type registryError struct {
+	err error
+}
+
+func (e *registryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *registryError) Unwrap() error {
+	return e.err
+}
+
+type schemaAndCodec struct {
+	Schema  string
+	Subject string
+	Fields  []recordField
+	Codec   *goavro.Codec
+}
+
+func (s *Serializer) Init() error {
+	switch s.Format {
+	case "":
+		s.Format = "binary"
+	case "binary", "json":
+		// Do nothing as those are valid settings
+	default:
+		return fmt.Errorf("unknown 'avro_format' %q", s.Format)
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix"
+	case "unix", "unix_ns", "unix_us", "unix_ms":
+		// Valid values
+	default:
+		return fmt.Errorf("invalid timestamp format '%v'", s.TimestampFormat)
+	}
+
+	if s.Schema != "" {
+		static, err := s.newSchemaAndCodec(s.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid 'avro_schema': %w", err)
+		}
+		s.static = static
+	}
+
+	if s.SchemaRegistry != "" {
+		registry, err := newSchemaRegistry(s.SchemaRegistry, s.CaCertPath)
+		if err != nil {
+			return fmt.Errorf("error connecting to the schema registry %q: %w", s.SchemaRegistry, err)
+		}
+		s.registry = registry
+	}
+
+	s.cache = make(map[string]*schemaAndCodec)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	return s.serialize(nil, m)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, m := range metrics {
+		out, err := s.serialize(buf, m)
+		if err != nil {
+			// Fail the whole batch on registry errors to retry it later
+			var rErr *registryError
+			if errors.As(err, &rErr) {
+				return nil, err
+			}
+			s.Log.Warnf("Skipping metric %q: %v", m.Name(), err)
+			continue
+		}
+		buf = out
+	}
+	return buf, nil
+}
+
+func (s *Serializer) serialize(buf []byte, m telegraf.Metric) ([]byte, error) {
+	data := s.collect(m)
+
+	sc := s.static
+	if sc == nil {
+		var err error
+		if sc, err = s.derive(m.Name(), data); err != nil {
+			return nil, fmt.Errorf("deriving schema for metric %q failed: %w", m.Name(), err)
+		}
+	}
+
+	native := make(map[string]interface{}, len(sc.Fields))
+	for _, field := range sc.Fields {
+		value, found := data[field.Name]
+		if !found && !nullable(field.Type) {
+			// Leave it to the codec to use the default value or to fail
+			continue
+		}
+		v, err := s.nativeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("converting field %q of metric %q failed: %w", field.Name, m.Name(), err)
+		}
+		native[field.Name] = v
+	}
+
+	if s.registry != nil {
+		id, err := s.registry.register(sc.Subject, sc.Schema)
+		if err != nil {
+			return nil, &registryError{err}
+		}
+		buf = append(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(id))
+	}
+
+	var err error
+	switch s.Format {
+	case "binary":
+		buf, err = sc.Codec.BinaryFromNative(buf, native)
+	case "json":
+		buf, err = sc.Codec.TextualFromNative(buf, native)
+		buf = append(buf, '\n')
+	default:
+		return nil, fmt.Errorf("unknown format %q", s.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding metric %q failed: %w", m.Name(), err)
+	}
+	return buf, nil
+}
+
+// collect returns the selected tags and fields of the metric together with
+// the timestamp and measurement name, indexed by their sanitized record field
+// name. Fields take precedence over tags with the same name.
+func (s *Serializer) collect(m telegraf.Metric) map[string]interface{} {
+	data := make(map[string]interface{}, len(m.TagList())+len(m.FieldList())+2)
+	for _, tag := range m.TagList() {
+		if len(s.Tags) > 0 && !choice.Contains(tag.Key, s.Tags) {
+			continue
+		}
+		data[sanitize(tag.Key)] = tag.Value
+	}
+	for _, field := range m.FieldList() {
+		if len(s.Fields) > 0 && !choice.Contains(field.Key, s.Fields) {
+			continue
+		}
+		data[sanitize(field.Key)] = field.Value
+	}
+	if s.MeasurementField != "" {
+		data[s.MeasurementField] = m.Name()
+	}
+	if s.Timestamp != "" {
+		data[s.Timestamp] = m.Time()
+	}
+	return data
+}
+
+// derive returns the codec for a record schema containing the collected
+// metric data. Tags and fields are nullable to allow for schema evolution in
+// the registry when metrics of the same series have different fields.
+func (s *Serializer) derive(name string, data map[string]interface{}) (*schemaAndCodec, error) {
+	record := recordSchema{Type: "record"}
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		namespace := strings.Split(name[:idx], ".")
+		for i, part := range namespace {
+			namespace[i] = sanitize(part)
+		}
+		record.Namespace = strings.Join(namespace, ".")
+		name = name[idx+1:]
+	}
+	record.Name = sanitize(name)
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if k != s.Timestamp && k != s.MeasurementField {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	fields := make([]map[string]interface{}, 0, len(data))
+	if s.Timestamp != "" {
+		fields = append(fields, map[string]interface{}{"name": s.Timestamp, "type": "long"})
+	}
+	if s.MeasurementField != "" {
+		fields = append(fields, map[string]interface{}{"name": s.MeasurementField, "type": "string"})
+	}
+	for _, k := range keys {
+		var typ string
+		switch data[k].(type) {
+		case string:
+			typ = "string"
+		case bool:
+			typ = "boolean"
+		case int64, uint64:
+			typ = "long"
+		case float64:
+			typ = "double"
+		default:
+			return nil, fmt.Errorf("unsupported type %T of %q", data[k], k)
+		}
+		fields = append(fields, map[string]interface{}{"name": k, "type": []string{"null", typ}, "default": nil})
+	}
+
+	schemaObj := map[string]interface{}{
+		"type":   record.Type,
+		"name":   record.Name,
+		"fields": fields,
+	}
+	if record.Namespace != "" {
+		schemaObj["namespace"] = record.Namespace
+	}
+	buf, err := json.Marshal(schemaObj)
+	if err != nil {
+		return nil, err
+	}
+	schema := string(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sc, found := s.cache[schema]; found {
+		return sc, nil
+	}
+	sc, err := s.newSchemaAndCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[schema] = sc
+	return sc, nil
+}
+
+func (s *Serializer) newSchemaAndCodec(schema string) (*schemaAndCodec, error) {
+	var record recordSchema
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, fmt.Errorf("unmarshalling schema failed: %w", err)
+	}
+	if record.Type != "record" {
+		return nil, fmt.Errorf("schema type is %q instead of a record", record.Type)
+	}
+
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := s.Subject
+	if subject == "" {
+		// Use the fully-qualified record name as subject
+		subject = record.Name
+		if record.Namespace != "" && !strings.Contains(record.Name, ".") {
+			subject = record.Namespace + "." + record.Name
+		}
+	}
+
+	return &schemaAndCodec{
+		Schema:  schema,
+		Subject: subject,
+		Fields:  record.Fields,
+		Codec:   codec,
+	}, nil
+}
+
+// nativeValue converts the metric value to the representation expected by
+// the codec for the given type
+func (s *Serializer) nativeValue(typ, value interface{}) (interface{}, error) {
+	switch t := typ.(type) {
+	case []interface{}:
+		if value == nil {
+			return nil, nil
+		}
+		member := unionMember(t, value)
+		if member == nil {
+			return nil, fmt.Errorf("no non-null member in union %v", t)
+		}
+		v, err := s.nativeValue(member, value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{typeName(member): v}, nil
+	case map[string]interface{}:
+		if logical, ok := t["logicalType"].(string); ok && strings.HasPrefix(logical, "timestamp-") {
+			if _, ok := value.(time.Time); ok {
+				return value, nil
+			}
+		}
+		return s.nativeValue(t["type"], value)
+	case string:
+		return s.primitiveValue(t, value)
+	}
+	return value, nil
+}
+
+func (s *Serializer) primitiveValue(typ string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		value = s.timestamp(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d exceeds the range of an Avro long", v)
+		}
+		value = int64(v)
+	}
+
+	switch typ {
+	case "string":
+		return internal.ToString(value)
+	case "boolean":
+		return internal.ToBool(value)
+	case "int", "long":
+		switch value.(type) {
+		case string, bool:
+			return internal.ToInt64(value)
+		}
+	case "float", "double":
+		switch value.(type) {
+		case string, bool:
+			return internal.ToFloat64(value)
+		}
+	}
+	return value, nil
+}
+
+func (s *Serializer) timestamp(t time.Time) int64 {
+	switch s.TimestampFormat {
+	case "unix_ms":
+		return t.UnixMilli()
+	case "unix_us":
+		return t.UnixMicro()
+	case "unix_ns":
+		return t.UnixNano()
+	}
+	return t.Unix()
+}
+
+// unionMember returns the union member best suited for the value falling back
+// to the first non-null member
+func unionMember(members []interface{}, value interface{}) interface{} {
+	var candidates []string
+	switch value.(type) {
+	case string:
+		candidates = []string{"string"}
+	case bool:
+		candidates = []string{"boolean"}
+	case int64, uint64:
+		candidates = []string{"long", "int", "double", "float"}
+	case float64:
+		candidates = []string{"double", "float"}
+	case time.Time:
+		candidates = []string{"long.timestamp-micros", "long.timestamp-millis", "long", "double"}
+	}
+
+	for _, c := range candidates {
+		for _, m := range members {
+			if typeName(m) == c {
+				return m
+			}
+		}
+	}
+	for _, m := range members {
+		if typeName(m) != "null" {
+			return m
+		}
+	}
+	return nil
+}
+
+// typeName returns the name used by the codec to identify union members
+func typeName(typ interface{}) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		name, _ := t["type"].(string)
+		if logical, ok := t["logicalType"].(string); ok {
+			return name + "." + logical
+		}
+		return name
+	}
+	return ""
+}
+
+func nullable(typ interface{}) bool {
+	switch t := typ.(type) {
+	case []interface{}:
+		for _, m := range t {
+			if typeName(m) == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sanitize replaces all characters not allowed in Avro names
+func sanitize(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+func init() {
+	serializers.Add("avro",
+		func() telegraf.Serializer {
+			return &Serializer{Timestamp: "timestamp"}
+		},
+	)
+}
@@ -0,0 +1,315 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/avro"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockRegistry implements the parts of the Confluent schema registry API
+// used by the serializer and the parser
+type mockRegistry struct {
+	subjects map[string]int
+	schemas  []string
+	sync.Mutex
+}
+
+func (r *mockRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	if req.Method == http.MethodPost {
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		subject := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/subjects/"), "/versions")
+		r.subjects[subject]++
+		id := len(r.schemas) + 1
+		for i, schema := range r.schemas {
+			if schema == body["schema"] {
+				id = i + 1
+			}
+		}
+		if id > len(r.schemas) {
+			r.schemas = append(r.schemas, body["schema"])
+		}
+		fmt.Fprintf(w, `{"id":%d}`, id)
+		return
+	}
+
+	var id int
+	if _, err := fmt.Sscanf(req.URL.Path, "/schemas/ids/%d", &id); err != nil || id < 1 || id > len(r.schemas) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]string{"schema": r.schemas[id-1]}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestRoundtripSchemaRegistry(t *testing.T) {
+	registry := &mockRegistry{subjects: make(map[string]int)}
+	ts := httptest.NewServer(registry)
+	defer ts.Close()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"system.cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "count": int64(4), "throttled": false},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"system.cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu1"},
+			map[string]interface{}{"usage_idle": 12.0, "count": uint64(2), "throttled": true},
+			time.Unix(1700000010, 0),
+		),
+	}
+
+	serializer := &Serializer{SchemaRegistry: ts.URL, Timestamp: "timestamp"}
+	require.NoError(t, serializer.Init())
+
+	parser := &avro.Parser{
+		SchemaRegistry: ts.URL,
+		Tags:           []string{"host", "cpu"},
+		Fields:         []string{"usage_idle", "count", "throttled"},
+		Timestamp:      "timestamp",
+		UnionMode:      "nullable",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"system.cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "count": int64(4), "throttled": false},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"system.cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu1"},
+			map[string]interface{}{"usage_idle": 12.0, "count": int64(2), "throttled": true},
+			time.Unix(1700000010, 0),
+		),
+	}
+
+	actual := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		buf, err := serializer.Serialize(m)
+		require.NoError(t, err)
+		require.Equal(t, byte(0), buf[0])
+
+		metrics, err := parser.Parse(buf)
+		require.NoError(t, err)
+		actual = append(actual, metrics...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Both metrics share the same schema which must only be registered once
+	require.Len(t, registry.schemas, 1)
+	require.Equal(t, map[string]int{"system.cpu": 1}, registry.subjects)
+}
+
+func TestSchemaRegistryError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error_code":409,"message":"Schema being registered is incompatible"}`))
+	}))
+	defer ts.Close()
+
+	serializer := &Serializer{SchemaRegistry: ts.URL, Subject: "metrics-value", Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+	require.NotZero(t, serializer.registry.client.Timeout)
+
+	m := metric.New("cpu", nil, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	_, err := serializer.Serialize(m)
+	require.ErrorContains(t, err, `registering schema for subject "metrics-value" failed with status 409`)
+
+	// Registry errors are not specific to a metric and fail the whole batch
+	_, err = serializer.SerializeBatch([]telegraf.Metric{m})
+	require.ErrorContains(t, err, `registering schema for subject "metrics-value" failed with status 409`)
+}
+
+func TestSerializeSchemaJSON(t *testing.T) {
+	schema := `
+{
+  "type": "record",
+  "name": "Measurement",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "name", "type": "string"},
+    {"name": "host", "type": "string"},
+    {"name": "value", "type": ["null", "double"], "default": null},
+    {"name": "status", "type": ["null", "int", "string"], "default": null},
+    {"name": "unit", "type": "string", "default": "percent"}
+  ]
+}`
+
+	serializer := &Serializer{
+		Schema:           schema,
+		Format:           "json",
+		MeasurementField: "name",
+		Timestamp:        "time",
+	}
+	require.NoError(t, serializer.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "localhost", "ignored": "tag"},
+		map[string]interface{}{"value": int64(42), "status": "ok"},
+		time.UnixMilli(1700000000123),
+	)
+	buf, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"time": 1700000000123,
+		"name": "cpu",
+		"host": "localhost",
+		"value": {"double": 42},
+		"status": {"string": "ok"},
+		"unit": "percent"
+	}`, string(buf))
+	require.True(t, strings.HasSuffix(string(buf), "\n"))
+
+	// Missing nullable fields are encoded as null while missing required ones
+	// cause an error
+	m = metric.New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"other": 1.0}, time.UnixMilli(0))
+	buf, err = serializer.Serialize(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"time": 0, "name": "cpu", "host": "localhost", "value": null, "status": null, "unit": "percent"}`, string(buf))
+
+	m = metric.New("cpu", nil, map[string]interface{}{"value": 1.0}, time.UnixMilli(0))
+	_, err = serializer.Serialize(m)
+	require.ErrorContains(t, err, `encoding metric "cpu" failed`)
+}
+
+func TestSerializeBatch(t *testing.T) {
+	serializer := &Serializer{
+		Fields:          []string{"value"},
+		Timestamp:       "timestamp",
+		TimestampFormat: "unix_ns",
+	}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("disk-io", map[string]string{"1dev": "sda"}, map[string]interface{}{"value": 1.5, "other": 2}, time.Unix(0, 42)),
+		metric.New("disk-io", map[string]string{"1dev": "sdb"}, map[string]interface{}{"value": 2.5}, time.Unix(0, 43)),
+	}
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	codec, err := goavro.NewCodec(`{
+		"type": "record",
+		"name": "disk_io",
+		"fields": [
+			{"name": "timestamp", "type": "long"},
+			{"name": "_1dev", "type": ["null", "string"], "default": null},
+			{"name": "value", "type": ["null", "double"], "default": null}
+		]
+	}`)
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{"timestamp": int64(42), "_1dev": map[string]interface{}{"string": "sda"}, "value": map[string]interface{}{"double": 1.5}},
+		{"timestamp": int64(43), "_1dev": map[string]interface{}{"string": "sdb"}, "value": map[string]interface{}{"double": 2.5}},
+	}
+	for _, e := range expected {
+		var native interface{}
+		native, buf, err = codec.NativeFromBinary(buf)
+		require.NoError(t, err)
+		require.Equal(t, e, native)
+	}
+	require.Empty(t, buf)
+}
+
+func TestSerializeBatchInvalidMetric(t *testing.T) {
+	schema := `
+{
+  "type": "record",
+  "name": "cpu",
+  "fields": [
+    {"name": "host", "type": "string"},
+    {"name": "value", "type": "double"}
+  ]
+}`
+
+	serializer := &Serializer{Schema: schema, Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	// Metrics that cannot be encoded are skipped in batches
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.5}, time.Unix(0, 0)),
+		metric.New("cpu", nil, map[string]interface{}{"value": 2.5}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": 3.5}, time.Unix(0, 0)),
+	}
+	_, err := serializer.Serialize(metrics[1])
+	require.ErrorContains(t, err, `encoding metric "cpu" failed`)
+
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{"host": "a", "value": 1.5},
+		{"host": "c", "value": 3.5},
+	}
+	for _, e := range expected {
+		var native interface{}
+		native, buf, err = serializer.static.Codec.NativeFromBinary(buf)
+		require.NoError(t, err)
+		require.Equal(t, e, native)
+	}
+	require.Empty(t, buf)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Serializer
+		expected string
+	}{
+		{
+			name:     "invalid format",
+			plugin:   &Serializer{Format: "xml"},
+			expected: `unknown 'avro_format' "xml"`,
+		},
+		{
+			name:     "invalid timestamp format",
+			plugin:   &Serializer{TimestampFormat: "rfc3339"},
+			expected: "invalid timestamp format 'rfc3339'",
+		},
+		{
+			name:     "invalid schema",
+			plugin:   &Serializer{Schema: `{"type": "record", "name": "cpu", "fields": [{"name": "value"}]}`},
+			expected: "invalid 'avro_schema'",
+		},
+		{
+			name:     "non-record schema",
+			plugin:   &Serializer{Schema: `{"type": "string"}`},
+			expected: `schema type is "string" instead of a record`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
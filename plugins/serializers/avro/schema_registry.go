@@ -0,0 +1,128 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	cache    map[string]int
+	client   *http.Client
+	mu       sync.RWMutex
+}
+
+const subjectVersions = "%s/subjects/%s/versions"
+
+func newSchemaRegistry(addr, caCertPath string) (*schemaRegistry, error) {
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		},
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	registry := &schemaRegistry{
+		url:      u.String(),
+		username: username,
+		password: password,
+		cache:    make(map[string]int),
+		client:   client,
+	}
+
+	return registry, nil
+}
+
+// register registers the schema under the given subject and returns the
+// schema ID assigned by the registry. Registering an already known schema is
+// idempotent on the registry side, so the IDs are cached locally to avoid a
+// request per metric.
+func (sr *schemaRegistry) register(subject, schema string) (int, error) {
+	key := subject + "\x00" + schema
+
+	sr.mu.RLock()
+	id, found := sr.cache[key]
+	sr.mu.RUnlock()
+	if found {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	addr := fmt.Sprintf(subjectVersions, sr.url, url.PathEscape(subject))
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("registering schema for subject %q failed with status %d: %s", subject, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var jsonResponse struct {
+		ID *int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return 0, fmt.Errorf("decoding response from schema registry failed: %w", err)
+	}
+	if jsonResponse.ID == nil {
+		return 0, errors.New("malformed response from schema registry: no 'id' key")
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.cache[key] = *jsonResponse.ID
+	return *jsonResponse.ID, nil
+}
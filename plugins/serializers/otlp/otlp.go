@@ -0,0 +1,178 @@
+package otlp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	common_opentelemetry "github.com/influxdata/telegraf/plugins/common/opentelemetry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+type Serializer struct {
+	Encoding           string            `toml:"otlp_encoding"`
+	ResourceTags       []string          `toml:"otlp_resource_tags"`
+	ScopeTags          []string          `toml:"otlp_scope_tags"`
+	ResourceAttributes map[string]string `toml:"otlp_resource_attributes"`
+	Log                telegraf.Logger   `toml:"-"`
+
+	converter *influx2otel.LineProtocolToOtelMetrics
+}
+
+// group collects the metrics sharing the same values of the resource and
+// scope tags as those tags need to be applied after conversion
+type group struct {
+	resource map[string]string
+	scope    map[string]string
+	batch    *influx2otel.MetricsBatch
+}
+
+func (s *Serializer) Init() error {
+	switch s.Encoding {
+	case "":
+		s.Encoding = "protobuf"
+	case "protobuf", "json":
+		// Do nothing as those are valid settings
+	default:
+		return fmt.Errorf("unknown 'otlp_encoding' %q", s.Encoding)
+	}
+
+	for _, tag := range s.ScopeTags {
+		if choice.Contains(tag, s.ResourceTags) {
+			return fmt.Errorf("tag %q cannot be both a resource and a scope tag", tag)
+		}
+	}
+
+	converter, err := influx2otel.NewLineProtocolToOtelMetrics(&common_opentelemetry.Logger{Logger: s.Log})
+	if err != nil {
+		return err
+	}
+	s.converter = converter
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	groups := make(map[string]*group)
+	if err := s.add(groups, m); err != nil {
+		return nil, err
+	}
+	return s.encode(groups)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	groups := make(map[string]*group)
+	for _, m := range metrics {
+		if err := s.add(groups, m); err != nil {
+			s.Log.Warnf("Skipping metric %q: %v", m.Name(), err)
+		}
+	}
+	return s.encode(groups)
+}
+
+func (s *Serializer) add(groups map[string]*group, m telegraf.Metric) error {
+	var vType common.InfluxMetricValueType
+	switch m.Type() {
+	case telegraf.Gauge:
+		vType = common.InfluxMetricValueTypeGauge
+	case telegraf.Untyped:
+		vType = common.InfluxMetricValueTypeUntyped
+	case telegraf.Counter:
+		vType = common.InfluxMetricValueTypeSum
+	case telegraf.Histogram:
+		vType = common.InfluxMetricValueTypeHistogram
+	case telegraf.Summary:
+		vType = common.InfluxMetricValueTypeSummary
+	default:
+		return fmt.Errorf("unrecognized metric type %v", m.Type())
+	}
+
+	// Split off the resource and scope tags and determine the group of the
+	// metric from their values
+	var key strings.Builder
+	resource := make(map[string]string)
+	scope := make(map[string]string)
+	tags := make(map[string]string, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		switch {
+		case choice.Contains(tag.Key, s.ResourceTags):
+			resource[tag.Key] = tag.Value
+		case choice.Contains(tag.Key, s.ScopeTags):
+			scope[tag.Key] = tag.Value
+		default:
+			tags[tag.Key] = tag.Value
+			continue
+		}
+		// The tag-list is sorted so the key is stable
+		fmt.Fprintf(&key, "%q=%q;", tag.Key, tag.Value)
+	}
+
+	g, found := groups[key.String()]
+	if !found {
+		g = &group{
+			resource: resource,
+			scope:    scope,
+			batch:    s.converter.NewBatch(),
+		}
+		groups[key.String()] = g
+	}
+
+	return g.batch.AddPoint(m.Name(), tags, m.Fields(), m.Time(), vType)
+}
+
+func (s *Serializer) encode(groups map[string]*group) ([]byte, error) {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	metrics := pmetric.NewMetrics()
+	for _, k := range keys {
+		g := groups[k]
+		converted := g.batch.GetMetrics()
+		rms := converted.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			rm := rms.At(i)
+			attrs := rm.Resource().Attributes()
+			for name, value := range s.ResourceAttributes {
+				attrs.PutStr(name, value)
+			}
+			for name, value := range g.resource {
+				attrs.PutStr(name, value)
+			}
+			sms := rm.ScopeMetrics()
+			for j := 0; j < sms.Len(); j++ {
+				scopeAttrs := sms.At(j).Scope().Attributes()
+				for name, value := range g.scope {
+					scopeAttrs.PutStr(name, value)
+				}
+			}
+		}
+		rms.MoveAndAppendTo(metrics.ResourceMetrics())
+	}
+
+	request := pmetricotlp.NewExportRequestFromMetrics(metrics)
+	switch s.Encoding {
+	case "protobuf":
+		return request.MarshalProto()
+	case "json":
+		return request.MarshalJSON()
+	}
+	return nil, fmt.Errorf("unknown encoding %q", s.Encoding)
+}
+
+func init() {
+	serializers.Add("otlp",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
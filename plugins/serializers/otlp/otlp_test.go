@@ -0,0 +1,144 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerializeBatchAttributes(t *testing.T) {
+	serializer := &Serializer{
+		ResourceTags:       []string{"service"},
+		ScopeTags:          []string{"plugin"},
+		ResourceAttributes: map[string]string{"deployment.environment": "test"},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"service": "db", "plugin": "cpu", "host.name": "node1", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"service": "db", "plugin": "cpu", "host.name": "node1", "cpu": "cpu1"},
+			map[string]interface{}{"usage_idle": 12.0},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"requests",
+			map[string]string{"service": "web", "plugin": "nginx"},
+			map[string]interface{}{"counter": int64(42)},
+			time.Unix(1700000000, 0),
+			telegraf.Counter,
+		),
+	}
+
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	request := pmetricotlp.NewExportRequest()
+	require.NoError(t, request.UnmarshalProto(buf))
+	rms := request.Metrics().ResourceMetrics()
+	require.Equal(t, 2, rms.Len())
+
+	// The resource of the first service contains the configured attributes,
+	// the resource tag and the tags matching the semantic conventions
+	db := rms.At(0)
+	require.Equal(t, map[string]interface{}{
+		"deployment.environment": "test",
+		"service":                "db",
+		"host.name":              "node1",
+	}, db.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, db.ScopeMetrics().Len())
+	require.Equal(t, map[string]interface{}{"plugin": "cpu"}, db.ScopeMetrics().At(0).Scope().Attributes().AsRaw())
+
+	m := db.ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "cpu_usage_idle", m.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+	require.Equal(t, 2, m.Gauge().DataPoints().Len())
+	for i := 0; i < m.Gauge().DataPoints().Len(); i++ {
+		dp := m.Gauge().DataPoints().At(i)
+		require.NotContains(t, dp.Attributes().AsRaw(), "service")
+		require.NotContains(t, dp.Attributes().AsRaw(), "plugin")
+		require.Contains(t, dp.Attributes().AsRaw(), "cpu")
+	}
+
+	web := rms.At(1)
+	require.Equal(t, map[string]interface{}{
+		"deployment.environment": "test",
+		"service":                "web",
+	}, web.Resource().Attributes().AsRaw())
+	m = web.ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "requests", m.Name())
+	require.Equal(t, pmetric.MetricTypeSum, m.Type())
+	require.Equal(t, int64(42), m.Sum().DataPoints().At(0).IntValue())
+}
+
+func TestSerializeJSON(t *testing.T) {
+	serializer := &Serializer{
+		Encoding: "json",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"otel.library.name": "telegraf", "otel.library.version": "1.0.0"},
+		map[string]interface{}{"gauge": 1.5},
+		time.Unix(0, 42),
+		telegraf.Gauge,
+	)
+	buf, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.True(t, json.Valid(buf))
+
+	request := pmetricotlp.NewExportRequest()
+	require.NoError(t, request.UnmarshalJSON(buf))
+	sm := request.Metrics().ResourceMetrics().At(0).ScopeMetrics().At(0)
+	require.Equal(t, "telegraf", sm.Scope().Name())
+	require.Equal(t, "1.0.0", sm.Scope().Version())
+	dp := sm.Metrics().At(0).Gauge().DataPoints().At(0)
+	require.InDelta(t, 1.5, dp.DoubleValue(), 0)
+	require.Equal(t, int64(42), dp.Timestamp().AsTime().UnixNano())
+}
+
+func TestSerializeInvalidMetric(t *testing.T) {
+	serializer := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	// Histograms require bucket information
+	invalid := metric.New("latency", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0), telegraf.Histogram)
+	_, err := serializer.Serialize(invalid)
+	require.Error(t, err)
+
+	// Invalid metrics are skipped in batches
+	valid := metric.New("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0), telegraf.Gauge)
+	buf, err := serializer.SerializeBatch([]telegraf.Metric{invalid, valid})
+	require.NoError(t, err)
+
+	request := pmetricotlp.NewExportRequest()
+	require.NoError(t, request.UnmarshalProto(buf))
+	require.Equal(t, 1, request.Metrics().MetricCount())
+}
+
+func TestInitErrors(t *testing.T) {
+	serializer := &Serializer{Encoding: "xml"}
+	require.ErrorContains(t, serializer.Init(), `unknown 'otlp_encoding' "xml"`)
+
+	serializer = &Serializer{ResourceTags: []string{"host"}, ScopeTags: []string{"host"}}
+	require.ErrorContains(t, serializer.Init(), `tag "host" cannot be both a resource and a scope tag`)
+}
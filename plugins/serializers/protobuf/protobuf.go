@@ -0,0 +1,412 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const timestampMessage = "google.protobuf.Timestamp"
+
+type Serializer struct {
+	MessageFiles    []string          `toml:"protobuf_files"`
+	MessageType     string            `toml:"protobuf_type"`
+	ImportPaths     []string          `toml:"protobuf_import_paths"`
+	MeasurementPath string            `toml:"protobuf_measurement_path"`
+	TimestampPath   string            `toml:"protobuf_timestamp_path"`
+	TimestampFormat string            `toml:"protobuf_timestamp_format"`
+	TagPaths        map[string]string `toml:"protobuf_tag_paths"`
+	FieldPaths      map[string]string `toml:"protobuf_field_paths"`
+	TagsMapPath     string            `toml:"protobuf_tags_map_path"`
+	FieldsMapPath   string            `toml:"protobuf_fields_map_path"`
+	Framing         string            `toml:"protobuf_framing"`
+	UseBatchFormat  bool              `toml:"use_batch_format"`
+	Log             telegraf.Logger   `toml:"-"`
+
+	msgType     protoreflect.MessageType
+	measurement fieldPath
+	timestamp   fieldPath
+	tags        map[string]fieldPath
+	fields      map[string]fieldPath
+	tagsMap     fieldPath
+	fieldsMap   fieldPath
+}
+
+// fieldPath contains the descriptors of the fields traversed when following a
+// dot-separated path from the message root to the target field
+type fieldPath []protoreflect.FieldDescriptor
+
+func (s *Serializer) Init() error {
+	// Concatenated protocol-buffer messages cannot be separated again, so
+	// batches require a framing
+	switch s.Framing {
+	case "":
+		s.Framing = "none"
+		if s.UseBatchFormat {
+			s.Framing = "length-delimited"
+		}
+	case "none":
+		if s.UseBatchFormat {
+			return errors.New("'use_batch_format' requires 'protobuf_framing' to be \"length-delimited\"")
+		}
+	case "length-delimited":
+		// Do nothing as this is a valid setting
+	default:
+		return fmt.Errorf("unknown 'protobuf_framing' %q", s.Framing)
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix_ns"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+		// Valid values
+	default:
+		return fmt.Errorf("invalid timestamp format '%v'", s.TimestampFormat)
+	}
+
+	// Check the message definition and type
+	if len(s.MessageFiles) == 0 {
+		return errors.New("protocol-buffer files not set")
+	}
+	if s.MessageType == "" {
+		return errors.New("protocol-buffer message-type not set")
+	}
+
+	// Load the file descriptors from the given protocol-buffer definition
+	resolver := &protocompile.SourceResolver{ImportPaths: s.ImportPaths}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	files, err := compiler.Compile(context.Background(), s.MessageFiles...)
+	if err != nil {
+		return fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+	}
+
+	var registry protoregistry.Files
+	for _, f := range files {
+		if err := registry.RegisterFile(f); err != nil {
+			return fmt.Errorf("adding file %q to registry failed: %w", f.Path(), err)
+		}
+	}
+
+	// Lookup given type in the loaded file descriptors
+	descriptor, err := registry.FindDescriptorByName(protoreflect.FullName(s.MessageType))
+	if err != nil {
+		return fmt.Errorf("finding message type %q failed: %w", s.MessageType, err)
+	}
+	msgDesc, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return fmt.Errorf("%q is not a message descriptor (%T)", s.MessageType, descriptor)
+	}
+	s.msgType = dynamicpb.NewMessageType(msgDesc)
+
+	// Resolve the paths of the metric data in the message
+	if s.MeasurementPath != "" {
+		if s.measurement, err = resolvePath(msgDesc, s.MeasurementPath); err != nil {
+			return fmt.Errorf("invalid 'protobuf_measurement_path': %w", err)
+		}
+		if err := checkScalar(s.measurement); err != nil {
+			return fmt.Errorf("invalid 'protobuf_measurement_path': %w", err)
+		}
+	}
+
+	if s.TimestampPath != "" {
+		if s.timestamp, err = resolvePath(msgDesc, s.TimestampPath); err != nil {
+			return fmt.Errorf("invalid 'protobuf_timestamp_path': %w", err)
+		}
+		if leaf := s.timestamp.leaf(); leaf.Message() == nil || leaf.Message().FullName() != timestampMessage {
+			if err := checkScalar(s.timestamp); err != nil {
+				return fmt.Errorf("invalid 'protobuf_timestamp_path': %w", err)
+			}
+		}
+	}
+
+	s.tags = make(map[string]fieldPath, len(s.TagPaths))
+	for key, p := range s.TagPaths {
+		fp, err := resolvePath(msgDesc, p)
+		if err == nil {
+			err = checkScalar(fp)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid path for tag %q: %w", key, err)
+		}
+		s.tags[key] = fp
+	}
+
+	s.fields = make(map[string]fieldPath, len(s.FieldPaths))
+	for key, p := range s.FieldPaths {
+		fp, err := resolvePath(msgDesc, p)
+		if err == nil {
+			err = checkScalar(fp)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid path for field %q: %w", key, err)
+		}
+		s.fields[key] = fp
+	}
+
+	if s.TagsMapPath != "" {
+		if s.tagsMap, err = resolvePath(msgDesc, s.TagsMapPath); err != nil {
+			return fmt.Errorf("invalid 'protobuf_tags_map_path': %w", err)
+		}
+		if err := checkMap(s.tagsMap); err != nil {
+			return fmt.Errorf("invalid 'protobuf_tags_map_path': %w", err)
+		}
+	}
+
+	if s.FieldsMapPath != "" {
+		if s.fieldsMap, err = resolvePath(msgDesc, s.FieldsMapPath); err != nil {
+			return fmt.Errorf("invalid 'protobuf_fields_map_path': %w", err)
+		}
+		if err := checkMap(s.fieldsMap); err != nil {
+			return fmt.Errorf("invalid 'protobuf_fields_map_path': %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	return s.serialize(nil, m, s.Framing == "length-delimited")
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	// Batches are always length-delimited as the messages cannot be separated
+	// otherwise, even for outputs batching without 'use_batch_format'
+	var buf []byte
+	for _, m := range metrics {
+		out, err := s.serialize(buf, m, true)
+		if err != nil {
+			s.Log.Warnf("Skipping metric %q: %v", m.Name(), err)
+			continue
+		}
+		buf = out
+	}
+	return buf, nil
+}
+
+func (s *Serializer) serialize(buf []byte, m telegraf.Metric, delimited bool) ([]byte, error) {
+	msg := s.msgType.New()
+
+	if s.measurement != nil {
+		if err := s.measurement.set(msg, m.Name()); err != nil {
+			return nil, fmt.Errorf("setting measurement failed: %w", err)
+		}
+	}
+
+	if s.timestamp != nil {
+		if err := s.setTimestamp(msg, m.Time()); err != nil {
+			return nil, fmt.Errorf("setting timestamp failed: %w", err)
+		}
+	}
+
+	for _, tag := range m.TagList() {
+		if fp, found := s.tags[tag.Key]; found {
+			if err := fp.set(msg, tag.Value); err != nil {
+				return nil, fmt.Errorf("setting tag %q failed: %w", tag.Key, err)
+			}
+		} else if s.tagsMap != nil {
+			if err := s.tagsMap.setMapEntry(msg, tag.Key, tag.Value); err != nil {
+				return nil, fmt.Errorf("setting tag %q failed: %w", tag.Key, err)
+			}
+		}
+	}
+
+	for _, field := range m.FieldList() {
+		if fp, found := s.fields[field.Key]; found {
+			if err := fp.set(msg, field.Value); err != nil {
+				return nil, fmt.Errorf("setting field %q failed: %w", field.Key, err)
+			}
+		} else if s.fieldsMap != nil {
+			if err := s.fieldsMap.setMapEntry(msg, field.Key, field.Value); err != nil {
+				return nil, fmt.Errorf("setting field %q failed: %w", field.Key, err)
+			}
+		}
+	}
+
+	data, err := proto.Marshal(msg.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("marshalling message failed: %w", err)
+	}
+
+	if delimited {
+		buf = protowire.AppendVarint(buf, uint64(len(data)))
+	}
+	return append(buf, data...), nil
+}
+
+func (s *Serializer) setTimestamp(msg protoreflect.Message, t time.Time) error {
+	leaf := s.timestamp.leaf()
+	if leaf.Message() != nil && leaf.Message().FullName() == timestampMessage {
+		parent := s.timestamp.parent(msg)
+		ts := parent.Mutable(leaf).Message()
+		fields := ts.Descriptor().Fields()
+		ts.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		ts.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return nil
+	}
+
+	var value int64
+	switch s.TimestampFormat {
+	case "unix":
+		value = t.Unix()
+	case "unix_ms":
+		value = t.UnixMilli()
+	case "unix_us":
+		value = t.UnixMicro()
+	case "unix_ns":
+		value = t.UnixNano()
+	}
+	return s.timestamp.set(msg, value)
+}
+
+// resolvePath follows the dot-separated path of field names starting at the
+// given message and returns the traversed fields
+func resolvePath(desc protoreflect.MessageDescriptor, p string) (fieldPath, error) {
+	parts := strings.Split(p, ".")
+	fp := make(fieldPath, 0, len(parts))
+	for i, name := range parts {
+		if desc == nil {
+			return nil, fmt.Errorf("%q is not a message", strings.Join(parts[:i], "."))
+		}
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("message %q has no field %q", desc.FullName(), name)
+		}
+		if i < len(parts)-1 && (fd.IsList() || fd.IsMap()) {
+			return nil, fmt.Errorf("repeated field %q cannot be traversed", strings.Join(parts[:i+1], "."))
+		}
+		fp = append(fp, fd)
+		desc = fd.Message()
+	}
+	return fp, nil
+}
+
+func checkScalar(fp fieldPath) error {
+	leaf := fp.leaf()
+	if leaf.IsList() || leaf.IsMap() {
+		return fmt.Errorf("field %q is repeated", leaf.FullName())
+	}
+	if leaf.Message() != nil {
+		return fmt.Errorf("field %q is a message", leaf.FullName())
+	}
+	return nil
+}
+
+func checkMap(fp fieldPath) error {
+	leaf := fp.leaf()
+	if !leaf.IsMap() {
+		return fmt.Errorf("field %q is not a map", leaf.FullName())
+	}
+	if leaf.MapKey().Kind() != protoreflect.StringKind {
+		return fmt.Errorf("keys of map %q are not strings", leaf.FullName())
+	}
+	if leaf.MapValue().Message() != nil {
+		return fmt.Errorf("values of map %q are messages", leaf.FullName())
+	}
+	return nil
+}
+
+func (fp fieldPath) leaf() protoreflect.FieldDescriptor {
+	return fp[len(fp)-1]
+}
+
+// parent returns the message containing the leaf field, creating all
+// intermediate messages
+func (fp fieldPath) parent(msg protoreflect.Message) protoreflect.Message {
+	for _, fd := range fp[:len(fp)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+	return msg
+}
+
+func (fp fieldPath) set(msg protoreflect.Message, value interface{}) error {
+	leaf := fp.leaf()
+	v, err := convert(leaf, value)
+	if err != nil {
+		return err
+	}
+	fp.parent(msg).Set(leaf, v)
+	return nil
+}
+
+func (fp fieldPath) setMapEntry(msg protoreflect.Message, key string, value interface{}) error {
+	leaf := fp.leaf()
+	v, err := convert(leaf.MapValue(), value)
+	if err != nil {
+		return err
+	}
+	entries := fp.parent(msg).Mutable(leaf).Map()
+	entries.Set(protoreflect.ValueOfString(key).MapKey(), v)
+	return nil
+}
+
+// convert converts the metric value to the kind of the given field
+func convert(fd protoreflect.FieldDescriptor, value interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := internal.ToBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := internal.ToInt64(value)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := internal.ToUint32(value)
+		return protoreflect.ValueOfUint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := internal.ToUint64(value)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := internal.ToFloat32(value)
+		return protoreflect.ValueOfFloat32(v), err
+	case protoreflect.DoubleKind:
+		v, err := internal.ToFloat64(value)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfBytes([]byte(v)), err
+	case protoreflect.EnumKind:
+		if name, ok := value.(string); ok {
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(name)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+			known := make([]string, 0, fd.Enum().Values().Len())
+			for i := 0; i < fd.Enum().Values().Len(); i++ {
+				known = append(known, string(fd.Enum().Values().Get(i).Name()))
+			}
+			sort.Strings(known)
+			return protoreflect.Value{}, fmt.Errorf("unknown value %q for enum %q, known values are %v", name, fd.Enum().FullName(), known)
+		}
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field kind %q", fd.Kind())
+}
+
+func init() {
+	serializers.Add("protobuf",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
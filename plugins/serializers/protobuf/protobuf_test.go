@@ -0,0 +1,280 @@
+package protobuf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerialize(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles:    []string{"testdata/metric.proto"},
+		MessageType:     "telegraf.test.Measurement",
+		MeasurementPath: "header.name",
+		TimestampPath:   "header.time",
+		TagPaths:        map[string]string{"host": "host"},
+		FieldPaths: map[string]string{
+			"usage_idle": "usage",
+			"count":      "count",
+			"status":     "status",
+		},
+		TagsMapPath:   "header.labels",
+		FieldsMapPath: "values",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "localhost", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 98.5,
+			"count":      int64(4),
+			"status":     "STATUS_OK",
+			"user":       1.25,
+			"system":     int64(2),
+		},
+		time.Unix(1700000000, 123456789),
+	)
+	buf, err := serializer.Serialize(m)
+	require.NoError(t, err)
+
+	msg := serializer.msgType.New().Interface()
+	require.NoError(t, proto.Unmarshal(buf, msg))
+	actual, err := protojson.Marshal(msg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"header": {
+			"name": "cpu",
+			"time": "2023-11-14T22:13:20.123456789Z",
+			"labels": {"cpu": "cpu0"}
+		},
+		"host": "localhost",
+		"usage": 98.5,
+		"count": 4,
+		"status": "STATUS_OK",
+		"values": {"user": 1.25, "system": 2}
+	}`, string(actual))
+}
+
+func TestSerializeTimestampFormat(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles:    []string{"testdata/metric.proto"},
+		MessageType:     "telegraf.test.Measurement",
+		TimestampPath:   "time_ns",
+		TimestampFormat: "unix_ms",
+		FieldPaths:      map[string]string{"value": "usage"},
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.Serialize(metric.New("cpu", nil, map[string]interface{}{"value": 42}, time.UnixMilli(1700000000123)))
+	require.NoError(t, err)
+
+	msg := serializer.msgType.New().Interface()
+	require.NoError(t, proto.Unmarshal(buf, msg))
+	actual, err := protojson.Marshal(msg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"timeNs": "1700000000123", "usage": 42}`, string(actual))
+}
+
+func TestSerializeBatchLengthDelimited(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles:    []string{"testdata/metric.proto"},
+		MessageType:     "telegraf.test.Measurement",
+		MeasurementPath: "header.name",
+		FieldPaths:      map[string]string{"value": "count"},
+		Framing:         "length-delimited",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("b", nil, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("c", nil, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	// Split the stream again using the standard length-delimited decoder
+	expected := []string{
+		`{"header": {"name": "a"}, "count": 1}`,
+		`{"header": {"name": "b"}, "count": 2}`,
+		`{"header": {"name": "c"}, "count": 3}`,
+	}
+	r := bytes.NewReader(buf)
+	for _, e := range expected {
+		msg := serializer.msgType.New().Interface()
+		require.NoError(t, protodelim.UnmarshalFrom(r, msg))
+
+		actual, err := protojson.Marshal(msg)
+		require.NoError(t, err)
+		require.JSONEq(t, e, string(actual))
+	}
+	require.Zero(t, r.Len())
+
+	// Batches are length-delimited independent of the framing as they could
+	// not be split otherwise
+	serializer.Framing = "none"
+	unframed, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, buf, unframed)
+}
+
+func TestSerializeBatchInvalidMetric(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles:    []string{"testdata/metric.proto"},
+		MessageType:     "telegraf.test.Measurement",
+		MeasurementPath: "header.name",
+		FieldPaths:      map[string]string{"value": "count"},
+		Framing:         "length-delimited",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	// Metrics that cannot be converted are skipped in batches
+	metrics := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("b", nil, map[string]interface{}{"value": int64(1) << 40}, time.Unix(0, 0)),
+		metric.New("c", nil, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := []string{
+		`{"header": {"name": "a"}, "count": 1}`,
+		`{"header": {"name": "c"}, "count": 3}`,
+	}
+	r := bytes.NewReader(buf)
+	for _, e := range expected {
+		msg := serializer.msgType.New().Interface()
+		require.NoError(t, protodelim.UnmarshalFrom(r, msg))
+
+		actual, err := protojson.Marshal(msg)
+		require.NoError(t, err)
+		require.JSONEq(t, e, string(actual))
+	}
+	require.Zero(t, r.Len())
+}
+
+func TestFramingDefault(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles: []string{"testdata/metric.proto"},
+		MessageType:  "telegraf.test.Measurement",
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+	require.Equal(t, "none", serializer.Framing)
+
+	serializer = &Serializer{
+		MessageFiles:   []string{"testdata/metric.proto"},
+		MessageType:    "telegraf.test.Measurement",
+		UseBatchFormat: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+	require.Equal(t, "length-delimited", serializer.Framing)
+}
+
+func TestSerializeErrors(t *testing.T) {
+	serializer := &Serializer{
+		MessageFiles: []string{"testdata/metric.proto"},
+		MessageType:  "telegraf.test.Measurement",
+		FieldPaths:   map[string]string{"status": "status", "count": "count"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	_, err := serializer.Serialize(metric.New("cpu", nil, map[string]interface{}{"status": "BROKEN"}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `unknown value "BROKEN" for enum "telegraf.test.Status"`)
+
+	_, err = serializer.Serialize(metric.New("cpu", nil, map[string]interface{}{"count": int64(1) << 40}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `setting field "count" failed`)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Serializer
+		expected string
+	}{
+		{
+			name:     "no files",
+			plugin:   &Serializer{MessageType: "telegraf.test.Measurement"},
+			expected: "protocol-buffer files not set",
+		},
+		{
+			name:     "unknown type",
+			plugin:   &Serializer{MessageFiles: []string{"testdata/metric.proto"}, MessageType: "telegraf.test.Unknown"},
+			expected: `finding message type "telegraf.test.Unknown" failed`,
+		},
+		{
+			name: "unknown field",
+			plugin: &Serializer{
+				MessageFiles: []string{"testdata/metric.proto"},
+				MessageType:  "telegraf.test.Measurement",
+				FieldPaths:   map[string]string{"value": "header.value"},
+			},
+			expected: `invalid path for field "value": message "telegraf.test.Header" has no field "value"`,
+		},
+		{
+			name: "message leaf",
+			plugin: &Serializer{
+				MessageFiles:    []string{"testdata/metric.proto"},
+				MessageType:     "telegraf.test.Measurement",
+				MeasurementPath: "header",
+			},
+			expected: `field "telegraf.test.Measurement.header" is a message`,
+		},
+		{
+			name: "repeated leaf",
+			plugin: &Serializer{
+				MessageFiles: []string{"testdata/metric.proto"},
+				MessageType:  "telegraf.test.Measurement",
+				TagPaths:     map[string]string{"comment": "comments"},
+			},
+			expected: `field "telegraf.test.Measurement.comments" is repeated`,
+		},
+		{
+			name: "tags map is no map",
+			plugin: &Serializer{
+				MessageFiles: []string{"testdata/metric.proto"},
+				MessageType:  "telegraf.test.Measurement",
+				TagsMapPath:  "host",
+			},
+			expected: `field "telegraf.test.Measurement.host" is not a map`,
+		},
+		{
+			name: "invalid framing",
+			plugin: &Serializer{
+				Framing: "netstring",
+			},
+			expected: `unknown 'protobuf_framing' "netstring"`,
+		},
+		{
+			name: "batch format without framing",
+			plugin: &Serializer{
+				Framing:        "none",
+				UseBatchFormat: true,
+			},
+			expected: `'use_batch_format' requires 'protobuf_framing' to be "length-delimited"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
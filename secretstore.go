@@ -11,6 +11,9 @@ type SecretStore interface {
 	// Set sets the given secret for the given key
 	Set(key, value string) error
 
+	// Delete removes the secret with the given key
+	Delete(key string) error
+
 	// List lists all known secret keys
 	List() ([]string, error)
 
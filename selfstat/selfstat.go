@@ -77,10 +77,28 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
-	registry.mu.Lock()
+	return registry.collect(Stat.Get)
+}
+
+// Snapshot returns all registered stats as telegraf metrics like Metrics() but
+// without clearing the averages of timing stats. Use this function for all
+// consumers other than the internal input to not interfere with its values.
+func Snapshot() []telegraf.Metric {
+	return registry.collect(func(s Stat) int64 {
+		if ts, ok := s.(*timingStat); ok {
+			return ts.peek()
+		}
+		return s.Get()
+	})
+}
+
+// collect returns all registered stats as telegraf metrics using the given
+// function to read the value of a stat.
+func (r *Registry) collect(get func(Stat) int64) []telegraf.Metric {
+	r.mu.Lock()
 	now := time.Now()
-	metrics := make([]telegraf.Metric, 0, len(registry.stats))
-	for _, stats := range registry.stats {
+	metrics := make([]telegraf.Metric, 0, len(r.stats))
+	for _, stats := range r.stats {
 		if len(stats) > 0 {
 			var tags map[string]string
 			var name string
@@ -91,14 +109,14 @@ func Metrics() []telegraf.Metric {
 					tags = stat.Tags()
 					name = stat.Name()
 				}
-				fields[fieldname] = stat.Get()
+				fields[fieldname] = get(stat)
 				j++
 			}
 			m := metric.New(name, tags, fields, now)
 			metrics = append(metrics, m)
 		}
 	}
-	registry.mu.Unlock()
+	r.mu.Unlock()
 	return metrics
 }
 
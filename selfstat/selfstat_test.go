@@ -211,3 +211,31 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestSnapshotKeepsTimings(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	s := RegisterTiming("test_snapshot", "test_field_ns", map[string]string{"test": "foo"})
+
+	value := func() interface{} {
+		for _, m := range Snapshot() {
+			if m.Name() == "internal_test_snapshot" {
+				return m.Fields()["test_field_ns"]
+			}
+		}
+		return nil
+	}
+
+	s.Incr(10)
+	s.Incr(20)
+
+	// Taking snapshots must not clear the average
+	require.Equal(t, int64(15), value())
+	require.Equal(t, int64(15), value())
+
+	// Adding timings after a snapshot must account for all timings since the
+	// last call to Get()
+	s.Incr(30)
+	require.Equal(t, int64(20), s.Get())
+	require.Equal(t, int64(20), value())
+}